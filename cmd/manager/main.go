@@ -31,6 +31,7 @@ import (
 	machineactuator "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
 	machinesetcontroller "github.com/openshift/machine-api-provider-aws/pkg/actuators/machineset"
 	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	"github.com/openshift/machine-api-provider-aws/pkg/client/replay"
 	"github.com/openshift/machine-api-provider-aws/pkg/version"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apiserver/pkg/util/feature"
@@ -110,6 +111,75 @@ func main() {
 		"The address for serving pprof profiling endpoints (requires --enable-pprof).",
 	)
 
+	instancesCacheTTL := flag.Duration(
+		"instances-cache-ttl",
+		machineactuator.DefaultInstancesCacheTTL,
+		"The duration for which a cluster-wide DescribeInstances result is reused across Machine reconciles. Set to 0 to disable the cache.",
+	)
+
+	spotInterruptionRateWindow := flag.Duration(
+		"spot-interruption-rate-window",
+		machineactuator.DefaultSpotInterruptionRateWindow,
+		"The rolling window over which the spot interruption rate of MachineSets is computed. Set to 0 to disable the spot interruption rate annotation.",
+	)
+
+	priceRefreshInterval := flag.Duration(
+		"price-refresh-interval",
+		machinesetcontroller.DefaultPriceRefreshInterval,
+		"The interval at which the hourly price annotations of MachineSets are refreshed. Set to 0 to disable the price annotations.",
+	)
+
+	serviceQuotaRefreshInterval := flag.Duration(
+		"service-quota-refresh-interval",
+		machinesetcontroller.DefaultServiceQuotaRefreshInterval,
+		"The interval at which the vCPU quotas checked against the replicas of MachineSets are refreshed. Set to 0 to disable the quota check.",
+	)
+
+	instanceTypesCacheWarmInterval := flag.Duration(
+		"instance-types-cache-warm-interval",
+		machinesetcontroller.DefaultInstanceTypesWarmInterval,
+		"The interval at which the instance types cache is warmed in the background and snapshotted to a ConfigMap. Set to 0 to disable warming.",
+	)
+
+	recordCassette := flag.String(
+		"record-aws-cassette",
+		"",
+		"Record the latest AWS requests and responses, with user data, credentials and account IDs redacted, to this JSON or YAML file. "+
+			"The file is written every minute and when the manager stops. Intended for debugging only.",
+	)
+
+	describeCacheTTLs := awsclient.DefaultDescribeCacheTTLs()
+	flag.DurationVar(
+		&describeCacheTTLs.Images,
+		"images-cache-ttl",
+		describeCacheTTLs.Images,
+		"The duration for which DescribeImages results are cached. Set to 0 to disable caching.",
+	)
+	flag.DurationVar(
+		&describeCacheTTLs.Subnets,
+		"subnets-cache-ttl",
+		describeCacheTTLs.Subnets,
+		"The duration for which DescribeSubnets results are cached. Set to 0 to disable caching.",
+	)
+	flag.DurationVar(
+		&describeCacheTTLs.AvailabilityZones,
+		"availability-zones-cache-ttl",
+		describeCacheTTLs.AvailabilityZones,
+		"The duration for which DescribeAvailabilityZones results are cached. Set to 0 to disable caching.",
+	)
+	flag.DurationVar(
+		&describeCacheTTLs.SecurityGroups,
+		"security-groups-cache-ttl",
+		describeCacheTTLs.SecurityGroups,
+		"The duration for which DescribeSecurityGroups results are cached. Set to 0 to disable caching.",
+	)
+	flag.DurationVar(
+		&describeCacheTTLs.DHCPOptions,
+		"dhcp-options-cache-ttl",
+		describeCacheTTLs.DHCPOptions,
+		"The duration for which DescribeVpcs and DescribeDhcpOptions results are cached. Set to 0 to disable caching.",
+	)
+
 	// Sets up feature gates (version from build time, default 4 for unknown)
 	// Default should be changed to 5 once we branch for 5
 	majorVersion := version.Version.Major
@@ -207,13 +277,52 @@ func main() {
 	mgr.Add(startCache)
 
 	describeRegionsCache := awsclient.NewRegionCache()
+
+	awsClientBuilder := awsclient.AwsClientBuilderFuncType(awsclient.NewValidatedClient)
+	var recorder *replay.Recorder
+	if *recordCassette != "" {
+		klog.Warningf("Recording AWS requests to %s", *recordCassette)
+		recorder = replay.NewRecorder(replay.DefaultRedactor(), replay.DefaultMaxInteractions)
+		awsClientBuilder = recorder.WrapClientBuilder(awsClientBuilder)
+		if err := mgr.Add(&replay.CassetteSaver{Recorder: recorder, Path: *recordCassette, Interval: time.Minute}); err != nil {
+			klog.Fatalf("Error adding AWS cassette saver: %v", err)
+		}
+	}
+
+	var instancesCache machineactuator.InstancesCache
+	if *instancesCacheTTL > 0 {
+		instancesCache = machineactuator.NewInstancesCache(*instancesCacheTTL)
+	}
+
+	var spotInterruptions machineactuator.SpotInterruptionHistory
+	if *spotInterruptionRateWindow > 0 {
+		spotInterruptions = machineactuator.NewSpotInterruptionHistory(*spotInterruptionRateWindow)
+	}
+
+	var prices machinesetcontroller.PriceCache
+	if *priceRefreshInterval > 0 {
+		prices = machinesetcontroller.NewPriceCache(*priceRefreshInterval, mgr.GetAPIReader())
+	}
+
+	var quotas machinesetcontroller.ServiceQuotaCache
+	if *serviceQuotaRefreshInterval > 0 {
+		quotas = machinesetcontroller.NewServiceQuotaCache(*serviceQuotaRefreshInterval)
+	}
+
+	// The instance types cache is shared by the MachineSet controller and the resolution of instance requirements of Machines.
+	instanceTypesCache := machinesetcontroller.NewInstanceTypesCache()
+
 	// Initialize machine actuator.
 	machineActuator := machineactuator.NewActuator(machineactuator.ActuatorParams{
-		Client:              mgr.GetClient(),
-		EventRecorder:       mgr.GetEventRecorderFor("awscontroller"),
-		AwsClientBuilder:    awsclient.NewValidatedClient,
-		ConfigManagedClient: configManagedClient,
-		RegionCache:         describeRegionsCache,
+		Client:               mgr.GetClient(),
+		EventRecorder:        mgr.GetEventRecorderFor("awscontroller"),
+		AwsClientBuilder:     awsClientBuilder,
+		ConfigManagedClient:  configManagedClient,
+		RegionCache:          describeRegionsCache,
+		InstancesCache:       instancesCache,
+		DescribeCache:        awsclient.NewDescribeCache(describeCacheTTLs),
+		SpotInterruptions:    spotInterruptions,
+		InstanceTypeResolver: machinesetcontroller.NewInstanceTypeResolver(instanceTypesCache, prices),
 	})
 
 	if err := machine.AddWithActuator(mgr, machineActuator, defaultMutableGate); err != nil {
@@ -223,19 +332,48 @@ func main() {
 	ctrl.SetLogger(klogr.New())
 	setupLog := ctrl.Log.WithName("setup")
 
+	if *instanceTypesCacheWarmInterval > 0 {
+		if err := mgr.Add(&machinesetcontroller.InstanceTypesCacheWarmer{
+			Client:              mgr.GetClient(),
+			APIReader:           mgr.GetAPIReader(),
+			AwsClientBuilder:    awsClientBuilder,
+			RegionCache:         describeRegionsCache,
+			ConfigManagedClient: configManagedClient,
+			InstanceTypesCache:  instanceTypesCache,
+			Interval:            *instanceTypesCacheWarmInterval,
+		}); err != nil {
+			klog.Fatalf("Error adding instance types cache warmer: %v", err)
+		}
+	}
+
 	if err := (&machinesetcontroller.Reconciler{
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("MachineSet"),
-		AwsClientBuilder:    awsclient.NewValidatedClient,
+		AwsClientBuilder:    awsClientBuilder,
 		RegionCache:         describeRegionsCache,
 		ConfigManagedClient: configManagedClient,
-		InstanceTypesCache:  machinesetcontroller.NewInstanceTypesCache(),
+		InstanceTypesCache:  instanceTypesCache,
+		SpotInterruptions:   spotInterruptions,
+		Prices:              prices,
+		Quotas:              quotas,
 		Gate:                defaultMutableGate,
+		APIReader:           mgr.GetAPIReader(),
 	}).SetupWithManager(mgr, controller.Options{}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
 		os.Exit(1)
 	}
 
+	if err := (&machinesetcontroller.ZoneSpreadReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("MachineSetZoneSpread"),
+		AwsClientBuilder:    awsClientBuilder,
+		RegionCache:         describeRegionsCache,
+		ConfigManagedClient: configManagedClient,
+	}).SetupWithManager(mgr, controller.Options{}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachineSetZoneSpread")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
 		klog.Fatal(err)
 	}
@@ -253,8 +391,7 @@ func main() {
 	}
 
 	// Start the Cmd
-	err = mgr.Start(ctrl.SetupSignalHandler())
-	if err != nil {
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		klog.Fatalf("Error starting manager: %v", err)
 	}
 }
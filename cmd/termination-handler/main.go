@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
@@ -40,6 +43,13 @@ func main() {
 	pollIntervalSeconds := flag.Int64("poll-interval-seconds", 5, "interval in seconds at which termination notice endpoint should be checked (Default: 5)")
 	nodeName := flag.String("node-name", "", "name of the node that the termination handler is running on")
 	namespace := flag.String("namespace", "", "namespace that the machine for the node should live in. If unspecified, the look for machines across all namespaces.")
+	watchRebalanceRecommendations := flag.Bool("watch-rebalance-recommendations", true, "mark the node with the RebalanceRecommended condition when EC2 issues a rebalance recommendation for the instance")
+	annotateMachineOnRebalanceRecommendation := flag.Bool("annotate-machine-on-rebalance-recommendation", false, "annotate the machine for the node when EC2 issues a rebalance recommendation for the instance")
+	watchScheduledMaintenance := flag.Bool("watch-scheduled-maintenance", true, "mark the node with the MaintenanceScheduled condition when EC2 schedules an event, such as a retirement or reboot, for the instance")
+	markMachineForDeletionBeforeMaintenance := flag.Duration("mark-machine-for-deletion-before-maintenance", 0, "annotate the machine for the node for deletion once a scheduled event is due to start within this duration. Disabled when zero.")
+	drainOnTermination := flag.Bool("drain-on-termination", false, "cordon, taint and drain the node when the instance is marked for interruption, evicting pods before the notice time")
+	simulatedNoticesFile := flag.String("simulated-notices-file", "", "file listing simulated notices, in YAML or JSON, handled in addition to the notices of the instance metadata service. The file is read on every poll, so that notices can be issued by editing it, e.g. during chaos testing.")
+	bindAddress := flag.String("bind-address", "", "address serving the health probes, on /healthz and /readyz, and the metrics, on /metrics, e.g. 127.0.0.1:9440. They are unauthenticated, and served on every interface of the host network when the address has no host. Disabled if empty.")
 	flag.Set("logtostderr", "true")
 	flag.Parse()
 
@@ -58,13 +68,35 @@ func main() {
 	// Get the poll interval as a duration from the `poll-interval-seconds` flag
 	pollInterval := time.Duration(*pollIntervalSeconds) * time.Second
 
+	options := termination.HandlerOptions{
+		WatchRebalanceRecommendations:            *watchRebalanceRecommendations,
+		AnnotateMachineOnRebalanceRecommendation: *annotateMachineOnRebalanceRecommendation,
+		WatchScheduledMaintenance:                *watchScheduledMaintenance,
+		MarkMachineForDeletionBeforeMaintenance:  *markMachineForDeletionBeforeMaintenance,
+		DrainOnTermination:                       *drainOnTermination,
+	}
+	if *simulatedNoticesFile != "" {
+		options.Sources = append(options.Sources, termination.NewFileNoticeSource(*simulatedNoticesFile))
+	}
+
 	// Construct a termination handler
-	handler, err := termination.NewHandler(logger, cfg, pollInterval, *namespace, *nodeName)
+	handler, err := termination.NewHandler(logger, cfg, pollInterval, *namespace, *nodeName, options)
 	if err != nil {
 		logger.Error(err, "Error constructing termination handler")
 		return
 	}
 
+	// Serve the health probes and metrics
+	if *bindAddress != "" {
+		server := termination.NewServer(*bindAddress, handler)
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error(err, "Error serving health probes and metrics")
+			}
+		}()
+		defer server.Shutdown(context.Background())
+	}
+
 	// Start the termination handler
 	if err := handler.Run(ctrl.SetupSignalHandler().Done()); err != nil {
 		logger.Error(err, "Error starting termination handler")
@@ -0,0 +1,46 @@
+#!/bin/bash
+
+# Generates the aws-on-demand-prices ConfigMap listing the on-demand Linux prices of the given regions, used for the
+# MachineSet price annotations and to rank instance types resolved from instance requirements.
+# Requires the aws CLI with credentials allowed to call the Price List API, and jq.
+#
+# Usage: hack/generate-on-demand-prices.sh region... | oc apply -f -
+
+set -euo pipefail
+
+NAMESPACE="${NAMESPACE:-openshift-machine-api}"
+REGIONS=("$@")
+if [ ${#REGIONS[@]} -eq 0 ]; then
+  echo "Usage: $0 region..." >&2
+  exit 1
+fi
+
+TMP=$(mktemp -d)
+trap 'rm -rf "${TMP}"' EXIT
+
+for REGION in "${REGIONS[@]}"; do
+  echo "Fetching on-demand prices for ${REGION}" >&2
+  # The Price List API is only served from a few regions, us-east-1 serves prices of all regions.
+  aws pricing get-products --region us-east-1 --service-code AmazonEC2 --output json \
+    --filters \
+      "Type=TERM_MATCH,Field=regionCode,Value=${REGION}" \
+      "Type=TERM_MATCH,Field=operatingSystem,Value=Linux" \
+      "Type=TERM_MATCH,Field=tenancy,Value=Shared" \
+      "Type=TERM_MATCH,Field=preInstalledSw,Value=NA" \
+      "Type=TERM_MATCH,Field=capacitystatus,Value=Used" \
+      "Type=TERM_MATCH,Field=licenseModel,Value=No License required" |
+    jq --arg region "${REGION}" '
+      [.PriceList[] | fromjson
+        | {key: "\($region).\(.product.attributes.instanceType)",
+           value: ([.terms.OnDemand[].priceDimensions[].pricePerUnit.USD | tonumber] | first)}
+        | select(.value != null and .value > 0)
+        | .value |= tostring]
+      | from_entries' > "${TMP}/${REGION}.json"
+done
+
+jq -S -s --arg namespace "${NAMESPACE}" '{
+  apiVersion: "v1",
+  kind: "ConfigMap",
+  metadata: {name: "aws-on-demand-prices", namespace: $namespace},
+  data: (reduce .[] as $prices ({}; . + $prices))
+}' "${TMP}"/*.json
@@ -39,11 +39,15 @@ const (
 
 // Actuator is responsible for performing machine reconciliation.
 type Actuator struct {
-	client              runtimeclient.Client
-	eventRecorder       record.EventRecorder
-	awsClientBuilder    awsclient.AwsClientBuilderFuncType
-	configManagedClient runtimeclient.Client
-	regionCache         awsclient.RegionCache
+	client               runtimeclient.Client
+	eventRecorder        record.EventRecorder
+	awsClientBuilder     awsclient.AwsClientBuilderFuncType
+	configManagedClient  runtimeclient.Client
+	regionCache          awsclient.RegionCache
+	instancesCache       InstancesCache
+	describeCache        awsclient.DescribeCache
+	spotInterruptions    SpotInterruptionHistory
+	instanceTypeResolver InstanceTypeResolver
 }
 
 // ActuatorParams holds parameter information for Actuator.
@@ -53,16 +57,25 @@ type ActuatorParams struct {
 	AwsClientBuilder    awsclient.AwsClientBuilderFuncType
 	ConfigManagedClient runtimeclient.Client
 	RegionCache         awsclient.RegionCache
+	InstancesCache      InstancesCache
+	DescribeCache       awsclient.DescribeCache
+	SpotInterruptions   SpotInterruptionHistory
+	// InstanceTypeResolver resolves the instance requirements of Machines, they are rejected if it is nil.
+	InstanceTypeResolver InstanceTypeResolver
 }
 
 // NewActuator returns an actuator.
 func NewActuator(params ActuatorParams) *Actuator {
 	return &Actuator{
-		client:              params.Client,
-		eventRecorder:       params.EventRecorder,
-		awsClientBuilder:    params.AwsClientBuilder,
-		configManagedClient: params.ConfigManagedClient,
-		regionCache:         params.RegionCache,
+		client:               params.Client,
+		eventRecorder:        params.EventRecorder,
+		awsClientBuilder:     params.AwsClientBuilder,
+		configManagedClient:  params.ConfigManagedClient,
+		regionCache:          params.RegionCache,
+		instancesCache:       params.InstancesCache,
+		describeCache:        params.DescribeCache,
+		spotInterruptions:    params.SpotInterruptions,
+		instanceTypeResolver: params.InstanceTypeResolver,
 	}
 }
 
@@ -80,12 +93,16 @@ func (a *Actuator) handleMachineError(machine *machinev1beta1.Machine, err error
 func (a *Actuator) Create(ctx context.Context, machine *machinev1beta1.Machine) error {
 	klog.Infof("%s: actuator creating machine", machine.GetName())
 	scope, err := newMachineScope(machineScopeParams{
-		Context:             ctx,
-		client:              a.client,
-		machine:             machine,
-		awsClientBuilder:    a.awsClientBuilder,
-		configManagedClient: a.configManagedClient,
-		regionCache:         a.regionCache,
+		Context:              ctx,
+		client:               a.client,
+		machine:              machine,
+		awsClientBuilder:     a.awsClientBuilder,
+		configManagedClient:  a.configManagedClient,
+		regionCache:          a.regionCache,
+		instancesCache:       a.instancesCache,
+		describeCache:        a.describeCache,
+		spotInterruptions:    a.spotInterruptions,
+		instanceTypeResolver: a.instanceTypeResolver,
 	})
 	if err != nil {
 		fmtErr := fmt.Errorf(scopeFailFmt, machine.GetName(), err)
@@ -108,12 +125,16 @@ func (a *Actuator) Create(ctx context.Context, machine *machinev1beta1.Machine)
 func (a *Actuator) Exists(ctx context.Context, machine *machinev1beta1.Machine) (bool, error) {
 	klog.Infof("%s: actuator checking if machine exists", machine.GetName())
 	scope, err := newMachineScope(machineScopeParams{
-		Context:             ctx,
-		client:              a.client,
-		machine:             machine,
-		awsClientBuilder:    a.awsClientBuilder,
-		configManagedClient: a.configManagedClient,
-		regionCache:         a.regionCache,
+		Context:              ctx,
+		client:               a.client,
+		machine:              machine,
+		awsClientBuilder:     a.awsClientBuilder,
+		configManagedClient:  a.configManagedClient,
+		regionCache:          a.regionCache,
+		instancesCache:       a.instancesCache,
+		describeCache:        a.describeCache,
+		spotInterruptions:    a.spotInterruptions,
+		instanceTypeResolver: a.instanceTypeResolver,
 	})
 	if err != nil {
 		return false, fmt.Errorf(scopeFailFmt, machine.GetName(), err)
@@ -126,12 +147,16 @@ func (a *Actuator) Exists(ctx context.Context, machine *machinev1beta1.Machine)
 func (a *Actuator) Update(ctx context.Context, machine *machinev1beta1.Machine) error {
 	klog.Infof("%s: actuator updating machine", machine.GetName())
 	scope, err := newMachineScope(machineScopeParams{
-		Context:             ctx,
-		client:              a.client,
-		machine:             machine,
-		awsClientBuilder:    a.awsClientBuilder,
-		configManagedClient: a.configManagedClient,
-		regionCache:         a.regionCache,
+		Context:              ctx,
+		client:               a.client,
+		machine:              machine,
+		awsClientBuilder:     a.awsClientBuilder,
+		configManagedClient:  a.configManagedClient,
+		regionCache:          a.regionCache,
+		instancesCache:       a.instancesCache,
+		describeCache:        a.describeCache,
+		spotInterruptions:    a.spotInterruptions,
+		instanceTypeResolver: a.instanceTypeResolver,
 	})
 	if err != nil {
 		fmtErr := fmt.Errorf(scopeFailFmt, machine.GetName(), err)
@@ -167,12 +192,16 @@ func (a *Actuator) Update(ctx context.Context, machine *machinev1beta1.Machine)
 func (a *Actuator) Delete(ctx context.Context, machine *machinev1beta1.Machine) error {
 	klog.Infof("%s: actuator deleting machine", machine.GetName())
 	scope, err := newMachineScope(machineScopeParams{
-		Context:             ctx,
-		client:              a.client,
-		machine:             machine,
-		awsClientBuilder:    a.awsClientBuilder,
-		configManagedClient: a.configManagedClient,
-		regionCache:         a.regionCache,
+		Context:              ctx,
+		client:               a.client,
+		machine:              machine,
+		awsClientBuilder:     a.awsClientBuilder,
+		configManagedClient:  a.configManagedClient,
+		regionCache:          a.regionCache,
+		instancesCache:       a.instancesCache,
+		describeCache:        a.describeCache,
+		spotInterruptions:    a.spotInterruptions,
+		instanceTypeResolver: a.instanceTypeResolver,
 	})
 	if err != nil {
 		fmtErr := fmt.Errorf(scopeFailFmt, machine.GetName(), err)
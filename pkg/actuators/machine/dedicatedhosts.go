@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,7 +20,7 @@ const (
 
 // allocateDedicatedHost allocates a new dedicated host for the given instance type in the specified availability zone.
 // It applies any tags specified in the DynamicHostAllocation configuration.
-func allocateDedicatedHost(client awsclient.Client, instanceType, availabilityZone string, tags []*ec2.Tag, machineName string) (string, error) {
+func allocateDedicatedHost(ctx context.Context, client awsclient.Client, instanceType, availabilityZone string, tags []*ec2.Tag, machineName string) (string, error) {
 	klog.Infof("Allocating dedicated host for instance type %s in availability zone %s for machine %s", instanceType, availabilityZone, machineName)
 
 	allocateInput := &ec2.AllocateHostsInput{
@@ -39,7 +40,7 @@ func allocateDedicatedHost(client awsclient.Client, instanceType, availabilityZo
 		allocateInput.TagSpecifications = tagSpecs
 	}
 
-	output, err := client.AllocateHosts(allocateInput)
+	output, err := client.AllocateHosts(ctx, allocateInput)
 	if err != nil {
 		klog.Errorf("Failed to allocate dedicated host: %v", err)
 		return "", fmt.Errorf("failed to allocate dedicated host: %w", err)
@@ -55,14 +56,14 @@ func allocateDedicatedHost(client awsclient.Client, instanceType, availabilityZo
 }
 
 // releaseDedicatedHost releases the dedicated host with the given ID.
-func releaseDedicatedHost(client awsclient.Client, hostID, machineName string) error {
+func releaseDedicatedHost(ctx context.Context, client awsclient.Client, hostID, machineName string) error {
 	klog.Infof("Releasing dedicated host %s for machine %s", hostID, machineName)
 
 	releaseInput := &ec2.ReleaseHostsInput{
 		HostIds: []*string{aws.String(hostID)},
 	}
 
-	output, err := client.ReleaseHosts(releaseInput)
+	output, err := client.ReleaseHosts(ctx, releaseInput)
 	if err != nil {
 		klog.Errorf("Failed to release dedicated host %s: %v", hostID, err)
 		return fmt.Errorf("failed to release dedicated host %s: %w", hostID, err)
@@ -142,7 +143,7 @@ func clearAllocatedHostIDInStatus(providerStatus *machinev1beta1.AWSMachineProvi
 }
 
 // tagBYODedicatedHost tags a BYO (Bring Your Own) dedicated host with kubernetes.io/cluster/<cluster-id>=shared.
-func tagBYODedicatedHost(client awsclient.Client, hostID, clusterID, machineName string) error {
+func tagBYODedicatedHost(ctx context.Context, client awsclient.Client, hostID, clusterID, machineName string) error {
 	klog.Infof("Tagging BYO dedicated host %s with kubernetes.io/cluster/%s=shared for machine %s", hostID, clusterID, machineName)
 
 	tags := []*ec2.Tag{
@@ -157,7 +158,7 @@ func tagBYODedicatedHost(client awsclient.Client, hostID, clusterID, machineName
 		Tags:      tags,
 	}
 
-	_, err := client.CreateTags(input)
+	_, err := client.CreateTags(ctx, input)
 	if err != nil {
 		klog.Errorf("Failed to tag BYO dedicated host %s: %v", hostID, err)
 		return fmt.Errorf("failed to tag BYO dedicated host %s: %w", hostID, err)
@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -208,7 +209,7 @@ func TestAllocateDedicatedHost(t *testing.T) {
 
 	expectedHostID := "h-1234567890abcdef0"
 
-	mockAWSClient.EXPECT().AllocateHosts(gomock.Any()).DoAndReturn(func(input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
+	mockAWSClient.EXPECT().AllocateHosts(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
 		if *input.InstanceType != instanceType {
 			t.Errorf("expected instance type %s, got %s", instanceType, *input.InstanceType)
 		}
@@ -234,7 +235,7 @@ func TestAllocateDedicatedHost(t *testing.T) {
 		}, nil
 	})
 
-	hostID, err := allocateDedicatedHost(mockAWSClient, instanceType, availabilityZone, tags, machineName)
+	hostID, err := allocateDedicatedHost(context.TODO(), mockAWSClient, instanceType, availabilityZone, tags, machineName)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -253,7 +254,7 @@ func TestReleaseDedicatedHost(t *testing.T) {
 	hostID := "h-1234567890abcdef0"
 	machineName := "test-machine"
 
-	mockAWSClient.EXPECT().ReleaseHosts(gomock.Any()).DoAndReturn(func(input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
+	mockAWSClient.EXPECT().ReleaseHosts(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
 		if len(input.HostIds) != 1 || *input.HostIds[0] != hostID {
 			t.Errorf("expected host ID %s, got %v", hostID, input.HostIds)
 		}
@@ -264,7 +265,7 @@ func TestReleaseDedicatedHost(t *testing.T) {
 		}, nil
 	})
 
-	err := releaseDedicatedHost(mockAWSClient, hostID, machineName)
+	err := releaseDedicatedHost(context.TODO(), mockAWSClient, hostID, machineName)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -439,7 +440,7 @@ func TestTagBYODedicatedHost(t *testing.T) {
 	clusterID := "test-cluster-id"
 	machineName := "test-machine"
 
-	mockAWSClient.EXPECT().CreateTags(gomock.Any()).DoAndReturn(func(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	mockAWSClient.EXPECT().CreateTags(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
 		// Verify the host ID
 		if len(input.Resources) != 1 || *input.Resources[0] != hostID {
 			t.Errorf("expected host ID %s, got %v", hostID, input.Resources)
@@ -462,7 +463,7 @@ func TestTagBYODedicatedHost(t *testing.T) {
 		return &ec2.CreateTagsOutput{}, nil
 	})
 
-	err := tagBYODedicatedHost(mockAWSClient, hostID, clusterID, machineName)
+	err := tagBYODedicatedHost(context.TODO(), mockAWSClient, hostID, clusterID, machineName)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
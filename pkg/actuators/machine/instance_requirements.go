@@ -0,0 +1,138 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// InstanceRequirementsAnnotation selects the instance type of a Machine by its attributes instead of the
+	// instanceType of the provider spec, which is ignored as the machine-api-operator webhook defaults it when it
+	// is empty. Its value is a JSON InstanceRequirements, e.g.
+	// {"vcpu":{"min":4,"max":8},"memoryMiB":{"min":16384},"architecture":"amd64","excludedFamilies":["t3"]}.
+	// It is usually set on the template of a MachineSet, so that all its Machines carry it.
+	InstanceRequirementsAnnotation = "machine.openshift.io/instance-requirements"
+
+	// ResolvedInstanceTypeAnnotation is set on Machines and MachineSets with instance requirements to the instance type
+	// they were resolved to. Machines keep the type they were resolved to at creation, MachineSets follow the type
+	// new Machines would resolve to so that it is used as the scale from zero shape.
+	// Matching types are ranked by on-demand price only if the aws-on-demand-prices ConfigMap lists all of them.
+	// Otherwise the type with the fewest vCPUs and then the least memory is used, with prices only ranking types of
+	// the same size, so the resolved type is the smallest rather than the cheapest.
+	ResolvedInstanceTypeAnnotation = "machine.openshift.io/resolved-instance-type"
+)
+
+// ErrNoMatchingInstanceType is returned by instance type resolvers when no instance type offered in the
+// availability zone matches the requirements.
+var ErrNoMatchingInstanceType = errors.New("no instance type matches the requirements")
+
+// InstanceRequirements are the attributes an instance type must have to be selected for a Machine.
+type InstanceRequirements struct {
+	// VCPU is the range of the number of vCPUs.
+	VCPU Range `json:"vcpu,omitempty"`
+	// MemoryMiB is the range of the memory in MiB.
+	MemoryMiB Range `json:"memoryMiB,omitempty"`
+	// Architecture is the CPU architecture, amd64 or arm64. Any architecture matches if it is empty.
+	Architecture string `json:"architecture,omitempty"`
+	// GPU is the range of the number of GPUs. Use a maximum of 0 to exclude GPU instance types.
+	GPU Range `json:"gpu,omitempty"`
+	// ExcludedFamilies are the instance type families never selected, e.g. t3 for all t3 instance types.
+	ExcludedFamilies []string `json:"excludedFamilies,omitempty"`
+	// Burstable selects only burstable performance instance types if true, and excludes them if false.
+	// Both match if it is unset.
+	Burstable *bool `json:"burstable,omitempty"`
+}
+
+// Range is an inclusive range of values, unbounded above if Max is unset.
+type Range struct {
+	Min int64  `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+}
+
+// Contains returns whether the value is within the range.
+func (r Range) Contains(value int64) bool {
+	return value >= r.Min && (r.Max == nil || value <= *r.Max)
+}
+
+// InstanceTypeResolver resolves instance requirements to a concrete instance type.
+type InstanceTypeResolver interface {
+	// ResolveInstanceType returns the instance type of the region matching the requirements, offered in the
+	// availability zone if it is set, ranked as described for ResolvedInstanceTypeAnnotation with the prices listed
+	// in the namespace. ErrNoMatchingInstanceType is returned if none matches.
+	ResolveInstanceType(ctx context.Context, awsClient awsclient.Client, namespace, region, zone string, requirements InstanceRequirements) (string, error)
+}
+
+// GetInstanceRequirements returns the instance requirements of the annotations, or nil if there are none.
+func GetInstanceRequirements(annotations map[string]string) (*InstanceRequirements, error) {
+	value, ok := annotations[InstanceRequirementsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	requirements := &InstanceRequirements{}
+	if err := json.Unmarshal([]byte(value), requirements); err != nil {
+		return nil, machinecontroller.InvalidMachineConfiguration("invalid %s annotation: %v", InstanceRequirementsAnnotation, err)
+	}
+	if err := validateInstanceRequirements(requirements); err != nil {
+		return nil, machinecontroller.InvalidMachineConfiguration("invalid %s annotation: %v", InstanceRequirementsAnnotation, err)
+	}
+	return requirements, nil
+}
+
+// validateInstanceRequirements checks that the ranges are not empty and the architecture is known.
+func validateInstanceRequirements(requirements *InstanceRequirements) error {
+	for name, r := range map[string]Range{"vcpu": requirements.VCPU, "memoryMiB": requirements.MemoryMiB, "gpu": requirements.GPU} {
+		if r.Min < 0 {
+			return fmt.Errorf("%s minimum must not be negative", name)
+		}
+		if r.Max != nil && *r.Max < r.Min {
+			return fmt.Errorf("%s maximum %d is lower than the minimum %d", name, *r.Max, r.Min)
+		}
+	}
+
+	switch requirements.Architecture {
+	case "", "amd64", "arm64":
+		return nil
+	default:
+		return fmt.Errorf("unknown architecture %q, must be amd64 or arm64", requirements.Architecture)
+	}
+}
+
+// resolveInstanceType sets the instance type of Machines with instance requirements to the type they resolve to,
+// overriding the instance type of the provider spec, and records it on the Machine so that it is kept if the instance
+// has to be launched again.
+func (r *Reconciler) resolveInstanceType() error {
+	requirements, err := GetInstanceRequirements(r.machine.Annotations)
+	if err != nil || requirements == nil {
+		return err
+	}
+	if r.providerSpec.InstanceType != "" {
+		klog.V(3).Infof("%s: instance type %s of the provider spec is overridden by the %s annotation", r.machine.Name, r.providerSpec.InstanceType, InstanceRequirementsAnnotation)
+	}
+
+	if instanceType := r.machine.Annotations[ResolvedInstanceTypeAnnotation]; instanceType != "" {
+		r.providerSpec.InstanceType = instanceType
+		return nil
+	}
+	if r.instanceTypeResolver == nil {
+		return machinecontroller.InvalidMachineConfiguration("the %s annotation is not supported, instance type resolution is disabled", InstanceRequirementsAnnotation)
+	}
+
+	instanceType, err := r.instanceTypeResolver.ResolveInstanceType(r.Context, r.awsClient, r.machine.Namespace, r.providerSpec.Placement.Region, r.providerSpec.Placement.AvailabilityZone, *requirements)
+	if errors.Is(err, ErrNoMatchingInstanceType) {
+		return machinecontroller.InvalidMachineConfiguration("error resolving instance type: %v", err)
+	} else if err != nil {
+		return fmt.Errorf("error resolving instance type: %w", err)
+	}
+
+	klog.Infof("%s: resolved instance requirements to instance type %s", r.machine.Name, instanceType)
+	r.machine.Annotations[ResolvedInstanceTypeAnnotation] = instanceType
+	r.providerSpec.InstanceType = instanceType
+	return nil
+}
@@ -0,0 +1,170 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetInstanceRequirements(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expected    *InstanceRequirements
+		expectErr   bool
+	}{
+		{
+			name: "without the annotation",
+		},
+		{
+			name: "with all the requirements",
+			annotations: map[string]string{InstanceRequirementsAnnotation: `{"vcpu":{"min":4,"max":8},"memoryMiB":{"min":16384},"architecture":"arm64",` +
+				`"gpu":{"max":0},"excludedFamilies":["t4g"],"burstable":false}`},
+			expected: &InstanceRequirements{
+				VCPU:             Range{Min: 4, Max: ptr.To[int64](8)},
+				MemoryMiB:        Range{Min: 16384},
+				Architecture:     "arm64",
+				GPU:              Range{Max: ptr.To[int64](0)},
+				ExcludedFamilies: []string{"t4g"},
+				Burstable:        ptr.To(false),
+			},
+		},
+		{
+			name:        "with invalid JSON",
+			annotations: map[string]string{InstanceRequirementsAnnotation: `{"vcpu":4}`},
+			expectErr:   true,
+		},
+		{
+			name:        "with an empty range",
+			annotations: map[string]string{InstanceRequirementsAnnotation: `{"vcpu":{"min":8,"max":4}}`},
+			expectErr:   true,
+		},
+		{
+			name:        "with an unknown architecture",
+			annotations: map[string]string{InstanceRequirementsAnnotation: `{"architecture":"x86_64"}`},
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			requirements, err := GetInstanceRequirements(tc.annotations)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(requirements).To(Equal(tc.expected))
+		})
+	}
+}
+
+// fakeInstanceTypeResolver resolves any requirements to its instance type, or returns its error.
+type fakeInstanceTypeResolver struct {
+	instanceType string
+	err          error
+	calls        int
+}
+
+func (f *fakeInstanceTypeResolver) ResolveInstanceType(_ context.Context, _ awsclient.Client, _, _, _ string, _ InstanceRequirements) (string, error) {
+	f.calls++
+	return f.instanceType, f.err
+}
+
+func TestResolveInstanceType(t *testing.T) {
+	requirements := `{"vcpu":{"min":2}}`
+
+	testCases := []struct {
+		name                 string
+		annotations          map[string]string
+		instanceType         string
+		resolver             *fakeInstanceTypeResolver
+		expectedInstanceType string
+		expectedResolved     string
+		expectedCalls        int
+		expectErr            bool
+		expectInvalidConfig  bool
+	}{
+		{
+			name:                 "without requirements",
+			instanceType:         "m5.large",
+			resolver:             &fakeInstanceTypeResolver{},
+			expectedInstanceType: "m5.large",
+		},
+		{
+			name:                 "resolves the requirements",
+			annotations:          map[string]string{InstanceRequirementsAnnotation: requirements},
+			resolver:             &fakeInstanceTypeResolver{instanceType: "m6a.large"},
+			expectedInstanceType: "m6a.large",
+			expectedResolved:     "m6a.large",
+			expectedCalls:        1,
+		},
+		{
+			name:                 "keeps the type resolved previously",
+			annotations:          map[string]string{InstanceRequirementsAnnotation: requirements, ResolvedInstanceTypeAnnotation: "m5.large"},
+			resolver:             &fakeInstanceTypeResolver{instanceType: "m6a.large"},
+			expectedInstanceType: "m5.large",
+			expectedResolved:     "m5.large",
+		},
+		{
+			name:                 "overrides a defaulted instance type in the provider spec",
+			annotations:          map[string]string{InstanceRequirementsAnnotation: requirements},
+			instanceType:         "m5.large",
+			resolver:             &fakeInstanceTypeResolver{instanceType: "m6a.large"},
+			expectedInstanceType: "m6a.large",
+			expectedResolved:     "m6a.large",
+			expectedCalls:        1,
+		},
+		{
+			name:                "when no instance type matches",
+			annotations:         map[string]string{InstanceRequirementsAnnotation: requirements},
+			resolver:            &fakeInstanceTypeResolver{err: fmt.Errorf("%w in availability zone us-east-1a", ErrNoMatchingInstanceType)},
+			expectedCalls:       1,
+			expectErr:           true,
+			expectInvalidConfig: true,
+		},
+		{
+			name:          "when the resolution fails",
+			annotations:   map[string]string{InstanceRequirementsAnnotation: requirements},
+			resolver:      &fakeInstanceTypeResolver{err: errors.New("throttled")},
+			expectedCalls: 1,
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", Annotations: tc.annotations}}
+			reconciler := newReconciler(&machineScope{
+				Context:              context.Background(),
+				machine:              machine,
+				providerSpec:         &machinev1beta1.AWSMachineProviderConfig{InstanceType: tc.instanceType},
+				instanceTypeResolver: tc.resolver,
+			})
+
+			err := reconciler.resolveInstanceType()
+			g.Expect(tc.resolver.calls).To(Equal(tc.expectedCalls))
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				var machineErr *machinecontroller.MachineError
+				g.Expect(errors.As(err, &machineErr)).To(Equal(tc.expectInvalidConfig))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(reconciler.providerSpec.InstanceType).To(Equal(tc.expectedInstanceType))
+			g.Expect(machine.Annotations[ResolvedInstanceTypeAnnotation]).To(Equal(tc.expectedResolved))
+		})
+	}
+}
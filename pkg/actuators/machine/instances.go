@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -44,8 +45,8 @@ func removeDuplicatedTags(tags []*ec2.Tag) []*ec2.Tag {
 }
 
 // removeStoppedMachine removes all instances of a specific machine that are in a stopped state.
-func removeStoppedMachine(machine *machinev1beta1.Machine, client awsclient.Client) error {
-	instances, err := getStoppedInstances(machine, client)
+func removeStoppedMachine(ctx context.Context, machine *machinev1beta1.Machine, client awsclient.Client) error {
+	instances, err := getStoppedInstances(ctx, machine, client)
 	if err != nil {
 		klog.Errorf("Error getting stopped instances: %v", err)
 		return fmt.Errorf("error getting stopped instances: %v", err)
@@ -56,11 +57,12 @@ func removeStoppedMachine(machine *machinev1beta1.Machine, client awsclient.Clie
 		return nil
 	}
 
-	_, err = terminateInstances(client, instances)
+	_, err = terminateInstances(ctx, client, machine, instances)
 	return err
 }
 
-func buildEC2Filters(inputFilters []machinev1beta1.Filter) []*ec2.Filter {
+// BuildEC2Filters converts the filters of a provider spec resource reference to EC2 filters.
+func BuildEC2Filters(inputFilters []machinev1beta1.Filter) []*ec2.Filter {
 	filters := make([]*ec2.Filter, len(inputFilters))
 	for i, f := range inputFilters {
 		values := make([]*string, len(f.Values))
@@ -75,7 +77,7 @@ func buildEC2Filters(inputFilters []machinev1beta1.Filter) []*ec2.Filter {
 	return filters
 }
 
-func getSecurityGroupsIDs(securityGroups []machinev1beta1.AWSResourceReference, client awsclient.Client) ([]*string, error) {
+func getSecurityGroupsIDs(ctx context.Context, securityGroups []machinev1beta1.AWSResourceReference, client awsclient.Client) ([]*string, error) {
 	var securityGroupIDs []*string
 	for _, g := range securityGroups {
 		// ID has priority
@@ -85,9 +87,9 @@ func getSecurityGroupsIDs(securityGroups []machinev1beta1.AWSResourceReference,
 			klog.Info("Describing security groups based on filters")
 			// Get groups based on filters
 			describeSecurityGroupsRequest := ec2.DescribeSecurityGroupsInput{
-				Filters: buildEC2Filters(g.Filters),
+				Filters: BuildEC2Filters(g.Filters),
 			}
-			describeSecurityGroupsResult, err := client.DescribeSecurityGroups(&describeSecurityGroupsRequest)
+			describeSecurityGroupsResult, err := client.DescribeSecurityGroups(ctx, &describeSecurityGroupsRequest)
 			if err != nil {
 				klog.Errorf("error describing security groups: %v", err)
 				return nil, fmt.Errorf("error describing security groups: %v", err)
@@ -107,13 +109,13 @@ func getSecurityGroupsIDs(securityGroups []machinev1beta1.AWSResourceReference,
 	return securityGroupIDs, nil
 }
 
-func getSubnetIDs(machine runtimeclient.ObjectKey, subnet machinev1beta1.AWSResourceReference, availabilityZone string, client awsclient.Client) ([]*string, error) {
+func getSubnetIDs(ctx context.Context, machine runtimeclient.ObjectKey, subnet machinev1beta1.AWSResourceReference, availabilityZone string, client awsclient.Client) ([]*string, error) {
 	var subnetIDs []*string
 	// ID has priority
 	if subnet.ID != nil {
 		subnetIDs = append(subnetIDs, subnet.ID)
 
-		availabilityZoneFromSubnetID, err := getAvalabilityZoneFromSubnetID(*subnet.ID, client)
+		availabilityZoneFromSubnetID, err := getAvalabilityZoneFromSubnetID(ctx, *subnet.ID, client)
 		if err != nil {
 			klog.Errorf("could not check if the subnet id and availability zone fields are mismatched: %v", err)
 			return subnetIDs, nil
@@ -128,7 +130,7 @@ func getSubnetIDs(machine runtimeclient.ObjectKey, subnet machinev1beta1.AWSReso
 			// Improve error logging for better user experience.
 			// Otherwise, during the process of minimizing API calls, this is a good
 			// candidate for removal.
-			_, err := client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+			_, err := client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
 				ZoneNames: []*string{aws.String(availabilityZone)},
 			})
 			if err != nil {
@@ -145,9 +147,9 @@ func getSubnetIDs(machine runtimeclient.ObjectKey, subnet machinev1beta1.AWSReso
 		filters = append(filters, subnet.Filters...)
 		klog.Info("Describing subnets based on filters")
 		describeSubnetRequest := ec2.DescribeSubnetsInput{
-			Filters: buildEC2Filters(filters),
+			Filters: BuildEC2Filters(filters),
 		}
-		describeSubnetResult, err := client.DescribeSubnets(&describeSubnetRequest)
+		describeSubnetResult, err := client.DescribeSubnets(ctx, &describeSubnetRequest)
 		if err != nil {
 			metrics.RegisterFailedInstanceCreate(&metrics.MachineLabels{
 				Name:      machine.Name,
@@ -170,8 +172,8 @@ func getSubnetIDs(machine runtimeclient.ObjectKey, subnet machinev1beta1.AWSReso
 }
 
 // getAvalabilityZoneFromSubnetID gets an availability zone from specified subnet id.
-func getAvalabilityZoneFromSubnetID(subnetID string, client awsclient.Client) (string, error) {
-	result, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+func getAvalabilityZoneFromSubnetID(ctx context.Context, subnetID string, client awsclient.Client) (string, error) {
+	result, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
 		DryRun: aws.Bool(false),
 		SubnetIds: []*string{
 			aws.String(subnetID),
@@ -194,9 +196,9 @@ func getAvalabilityZoneFromSubnetID(subnetID string, client awsclient.Client) (s
 }
 
 // getAvalabilityZoneTypeFromZoneName gets an availability zone type from specified zone name.
-func getAvalabilityZoneTypeFromZoneName(zoneName string, client awsclient.Client) (string, error) {
+func getAvalabilityZoneTypeFromZoneName(ctx context.Context, zoneName string, client awsclient.Client) (string, error) {
 
-	result, err := client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+	result, err := client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
 		DryRun:    aws.Bool(false),
 		ZoneNames: []*string{aws.String(zoneName)},
 	})
@@ -215,7 +217,7 @@ func getAvalabilityZoneTypeFromZoneName(zoneName string, client awsclient.Client
 	return "", fmt.Errorf("could not get an availability zone type from a zone name")
 }
 
-func getAMI(machine runtimeclient.ObjectKey, AMI machinev1beta1.AWSResourceReference, client awsclient.Client) (*string, error) {
+func getAMI(ctx context.Context, machine runtimeclient.ObjectKey, AMI machinev1beta1.AWSResourceReference, client awsclient.Client) (*string, error) {
 	if AMI.ID != nil {
 		amiID := AMI.ID
 		klog.Infof("Using AMI %s", *amiID)
@@ -224,9 +226,9 @@ func getAMI(machine runtimeclient.ObjectKey, AMI machinev1beta1.AWSResourceRefer
 	if len(AMI.Filters) > 0 {
 		klog.Info("Describing AMI based on filters")
 		describeImagesRequest := ec2.DescribeImagesInput{
-			Filters: buildEC2Filters(AMI.Filters),
+			Filters: BuildEC2Filters(AMI.Filters),
 		}
-		describeAMIResult, err := client.DescribeImages(&describeImagesRequest)
+		describeAMIResult, err := client.DescribeImages(ctx, &describeImagesRequest)
 		if err != nil {
 			metrics.RegisterFailedInstanceCreate(&metrics.MachineLabels{
 				Name:      machine.Name,
@@ -262,7 +264,7 @@ func getAMI(machine runtimeclient.ObjectKey, AMI machinev1beta1.AWSResourceRefer
 	return nil, fmt.Errorf("AMI ID or AMI filters need to be specified")
 }
 
-func getBlockDeviceMappings(machine runtimeclient.ObjectKey, blockDeviceMappingSpecs []machinev1beta1.BlockDeviceMappingSpec, AMI string, client awsclient.Client) ([]*ec2.BlockDeviceMapping, error) {
+func getBlockDeviceMappings(ctx context.Context, machine runtimeclient.ObjectKey, blockDeviceMappingSpecs []machinev1beta1.BlockDeviceMappingSpec, AMI string, client awsclient.Client) ([]*ec2.BlockDeviceMapping, error) {
 	blockDeviceMappings := make([]*ec2.BlockDeviceMapping, 0)
 
 	if len(blockDeviceMappingSpecs) == 0 {
@@ -273,7 +275,7 @@ func getBlockDeviceMappings(machine runtimeclient.ObjectKey, blockDeviceMappingS
 	describeImagesRequest := ec2.DescribeImagesInput{
 		ImageIds: []*string{&AMI},
 	}
-	describeAMIResult, err := client.DescribeImages(&describeImagesRequest)
+	describeAMIResult, err := client.DescribeImages(ctx, &describeImagesRequest)
 	if err != nil {
 		metrics.RegisterFailedInstanceCreate(&metrics.MachineLabels{
 			Name:      machine.Name,
@@ -347,21 +349,21 @@ func getBlockDeviceMappings(machine runtimeclient.ObjectKey, blockDeviceMappingS
 	return blockDeviceMappings, nil
 }
 
-func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *machinev1beta1.AWSMachineProviderConfig, userData []byte, awsClient awsclient.Client, client runtimeclient.Client, infra *configv1.Infrastructure) (*ec2.Instance, string, error) {
+func launchInstance(ctx context.Context, machine *machinev1beta1.Machine, machineProviderConfig *machinev1beta1.AWSMachineProviderConfig, userData []byte, awsClient awsclient.Client, client runtimeclient.Client, infra *configv1.Infrastructure) (*ec2.Instance, string, error) {
 	machineKey := runtimeclient.ObjectKey{
 		Name:      machine.Name,
 		Namespace: machine.Namespace,
 	}
-	amiID, err := getAMI(machineKey, machineProviderConfig.AMI, awsClient)
+	amiID, err := getAMI(ctx, machineKey, machineProviderConfig.AMI, awsClient)
 	if err != nil {
 		return nil, "", mapierrors.InvalidMachineConfiguration("error getting AMI: %v", err)
 	}
 
-	securityGroupsIDs, err := getSecurityGroupsIDs(machineProviderConfig.SecurityGroups, awsClient)
+	securityGroupsIDs, err := getSecurityGroupsIDs(ctx, machineProviderConfig.SecurityGroups, awsClient)
 	if err != nil {
 		return nil, "", mapierrors.InvalidMachineConfiguration("error getting security groups IDs: %v", err)
 	}
-	subnetIDs, err := getSubnetIDs(machineKey, machineProviderConfig.Subnet, machineProviderConfig.Placement.AvailabilityZone, awsClient)
+	subnetIDs, err := getSubnetIDs(ctx, machineKey, machineProviderConfig.Subnet, machineProviderConfig.Placement.AvailabilityZone, awsClient)
 	if err != nil {
 		return nil, "", mapierrors.InvalidMachineConfiguration("error getting subnet IDs: %v", err)
 	}
@@ -394,11 +396,11 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 	// instead of AssociatePublicIpAddress.
 	// AssociatePublicIpAddress and AssociateCarrierIpAddress are mutually exclusive.
 	if machineProviderConfig.PublicIP != nil {
-		zoneName, err := getAvalabilityZoneFromSubnetID(*subnetID, awsClient)
+		zoneName, err := getAvalabilityZoneFromSubnetID(ctx, *subnetID, awsClient)
 		if err != nil {
 			return nil, "", mapierrors.InvalidMachineConfiguration("error discoverying zone type: %v", err)
 		}
-		zoneType, err := getAvalabilityZoneTypeFromZoneName(zoneName, awsClient)
+		zoneType, err := getAvalabilityZoneTypeFromZoneName(ctx, zoneName, awsClient)
 		if err != nil {
 			return nil, "", mapierrors.InvalidMachineConfiguration("error discoverying zone type: %v", err)
 		}
@@ -422,7 +424,18 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 		return nil, "", mapierrors.InvalidMachineConfiguration("invalid value for networkInterfaceType %q, valid values are \"\", \"ENA\" and \"EFA\"", machineProviderConfig.NetworkInterfaceType)
 	}
 
-	blockDeviceMappings, err := getBlockDeviceMappings(machineKey, machineProviderConfig.BlockDevices, *amiID, awsClient)
+	interruptionBehavior, err := getSpotInterruptionBehavior(machine)
+	if err != nil {
+		return nil, "", err
+	}
+	hibernation := interruptionBehavior == ec2.InstanceInterruptionBehaviorHibernate && isSpotMarket(machineProviderConfig)
+	if hibernation {
+		if err := ensureEncryptedRootDevice(machineProviderConfig); err != nil {
+			return nil, "", err
+		}
+	}
+
+	blockDeviceMappings, err := getBlockDeviceMappings(ctx, machineKey, machineProviderConfig.BlockDevices, *amiID, awsClient)
 	if err != nil {
 		return nil, "", mapierrors.InvalidMachineConfiguration("error getting blockDeviceMappings: %v", err)
 	}
@@ -460,7 +473,7 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 		availabilityZone := machineProviderConfig.Placement.AvailabilityZone
 		if availabilityZone == "" && len(subnetIDs) > 0 {
 			// Get availability zone from subnet
-			zoneName, err := getAvalabilityZoneFromSubnetID(*subnetIDs[0], awsClient)
+			zoneName, err := getAvalabilityZoneFromSubnetID(ctx, *subnetIDs[0], awsClient)
 			if err != nil {
 				return nil, "", mapierrors.InvalidMachineConfiguration("error getting availability zone for dedicated host allocation: %v", err)
 			}
@@ -474,7 +487,7 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 		userTags := getDynamicHostTags(&machineProviderConfig.Placement)
 		tags := buildTagList(machine.Name, clusterID, userTags, infra)
 
-		hostID, err := allocateDedicatedHost(awsClient, machineProviderConfig.InstanceType, availabilityZone, tags, machine.Name)
+		hostID, err := allocateDedicatedHost(ctx, awsClient, machineProviderConfig.InstanceType, availabilityZone, tags, machine.Name)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to allocate dedicated host: %w", err)
 		}
@@ -486,7 +499,7 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 	if err != nil {
 		// If we allocated a host and placement construction failed, we should release it
 		if allocatedHostID != "" {
-			if releaseErr := releaseDedicatedHost(awsClient, allocatedHostID, machine.Name); releaseErr != nil {
+			if releaseErr := releaseDedicatedHost(ctx, awsClient, allocatedHostID, machine.Name); releaseErr != nil {
 				klog.Errorf("Failed to release allocated dedicated host %s after placement construction error: %v", allocatedHostID, releaseErr)
 			}
 		}
@@ -496,7 +509,7 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 	// Tag BYO dedicated host with kubernetes.io/cluster/<cluster-id>=shared
 	if isBYODedicatedHost(&machineProviderConfig.Placement) {
 		byoHostID := getDedicatedHostID(&machineProviderConfig.Placement)
-		if err := tagBYODedicatedHost(awsClient, byoHostID, clusterID, machine.Name); err != nil {
+		if err := tagBYODedicatedHost(ctx, awsClient, byoHostID, clusterID, machine.Name); err != nil {
 			// Log the error but don't fail the instance creation since tagging is not critical
 			// and the user owns the host
 			klog.Warningf("Failed to tag BYO dedicated host %s: %v", byoHostID, err)
@@ -508,19 +521,19 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 	if err != nil {
 		// If we allocated a host and capacity reservation specification retrieval failed, release the host
 		if allocatedHostID != "" {
-			if releaseErr := releaseDedicatedHost(awsClient, allocatedHostID, machine.Name); releaseErr != nil {
+			if releaseErr := releaseDedicatedHost(ctx, awsClient, allocatedHostID, machine.Name); releaseErr != nil {
 				klog.Errorf("Failed to release allocated dedicated host %s after capacity reservation error: %v", allocatedHostID, releaseErr)
 			}
 		}
 		return nil, "", err
 	}
 
-	instanceMarketOptions, err := getInstanceMarketOptionsRequest(machineProviderConfig)
+	instanceMarketOptions, err := getInstanceMarketOptionsRequest(machineProviderConfig, interruptionBehavior)
 
 	if err != nil {
 		// If we allocated a host and market options retrieval failed, release the host
 		if allocatedHostID != "" {
-			if releaseErr := releaseDedicatedHost(awsClient, allocatedHostID, machine.Name); releaseErr != nil {
+			if releaseErr := releaseDedicatedHost(ctx, awsClient, allocatedHostID, machine.Name); releaseErr != nil {
 				klog.Errorf("Failed to release allocated dedicated host %s after market options error: %v", allocatedHostID, releaseErr)
 			}
 		}
@@ -549,11 +562,14 @@ func launchInstance(machine *machinev1beta1.Machine, machineProviderConfig *mach
 	if len(blockDeviceMappings) > 0 {
 		inputConfig.BlockDeviceMappings = blockDeviceMappings
 	}
-	runResult, err := awsClient.RunInstances(&inputConfig)
+	if hibernation {
+		inputConfig.HibernationOptions = &ec2.HibernationOptionsRequest{Configured: aws.Bool(true)}
+	}
+	runResult, err := awsClient.RunInstances(ctx, &inputConfig)
 	if err != nil {
 		// If we allocated a host and instance creation failed, release the host
 		if allocatedHostID != "" {
-			if releaseErr := releaseDedicatedHost(awsClient, allocatedHostID, machine.Name); releaseErr != nil {
+			if releaseErr := releaseDedicatedHost(ctx, awsClient, allocatedHostID, machine.Name); releaseErr != nil {
 				klog.Errorf("Failed to release allocated dedicated host %s after instance creation error: %v", allocatedHostID, releaseErr)
 			}
 		}
@@ -655,7 +671,14 @@ func sortInstances(instances []*ec2.Instance) {
 	sort.Sort(instanceList(instances))
 }
 
-func getInstanceMarketOptionsRequest(providerConfig *machinev1beta1.AWSMachineProviderConfig) (*ec2.InstanceMarketOptionsRequest, error) {
+// isSpotMarket returns true if the provider config requests a spot instance.
+func isSpotMarket(providerConfig *machinev1beta1.AWSMachineProviderConfig) bool {
+	return providerConfig.MarketType == machinev1beta1.MarketTypeSpot || providerConfig.SpotMarketOptions != nil
+}
+
+// getInstanceMarketOptionsRequest returns the market options of the instance. The interruption behavior,
+// one of terminate, stop or hibernate, only applies to spot instances.
+func getInstanceMarketOptionsRequest(providerConfig *machinev1beta1.AWSMachineProviderConfig, interruptionBehavior string) (*ec2.InstanceMarketOptionsRequest, error) {
 	if providerConfig.MarketType != "" && providerConfig.MarketType == machinev1beta1.MarketTypeCapacityBlock && providerConfig.SpotMarketOptions != nil {
 		return nil, errors.New("can't create spot capacity-blocks, remove spot market request")
 	}
@@ -688,7 +711,7 @@ func getInstanceMarketOptionsRequest(providerConfig *machinev1beta1.AWSMachinePr
 	case machinev1beta1.MarketTypeSpot:
 		// Set required values for Spot instances
 		spotOpts := &ec2.SpotMarketOptions{
-			// The following two options ensure that:
+			// By default, the following two options ensure that:
 			// - If an instance is interrupted, it is terminated rather than hibernating or stopping
 			// - No replacement instance will be created if the instance is interrupted
 			// - If the spot request cannot immediately be fulfilled, it will not be created
@@ -698,6 +721,13 @@ func getInstanceMarketOptionsRequest(providerConfig *machinev1beta1.AWSMachinePr
 			SpotInstanceType:             aws.String(ec2.SpotInstanceTypeOneTime),
 		}
 
+		// Stopping or hibernating requires a persistent request, so that EC2 starts the same instance again
+		// once capacity is available. The 1:1 mapping still holds as the request is cancelled with the Machine.
+		if interruptionBehavior == ec2.InstanceInterruptionBehaviorStop || interruptionBehavior == ec2.InstanceInterruptionBehaviorHibernate {
+			spotOpts.InstanceInterruptionBehavior = aws.String(interruptionBehavior)
+			spotOpts.SpotInstanceType = aws.String(ec2.SpotInstanceTypePersistent)
+		}
+
 		if maxPrice := aws.StringValue(providerConfig.SpotMarketOptions.MaxPrice); maxPrice != "" {
 			spotOpts.MaxPrice = aws.String(maxPrice)
 		}
@@ -0,0 +1,270 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultInstancesCacheTTL is the default duration for which a DescribeInstances snapshot is considered fresh.
+	// It is kept shorter than requeueAfterSeconds so that a pending instance requeue always observes new data.
+	DefaultInstancesCacheTTL = 15 * time.Second
+
+	// instancesCacheFailureBackoff is the duration for which refreshes are skipped after a failed one,
+	// lookups are answered by EC2 directly meanwhile. This avoids listing all instances of the cluster
+	// on every Machine reconcile while the API is throttling.
+	instancesCacheFailureBackoff = 30 * time.Second
+
+	// describeInstancesMaxResults is the page size used when filling the cache.
+	describeInstancesMaxResults = 1000
+)
+
+// InstancesCache answers per-Machine instance lookups from a shared snapshot of all instances of a cluster.
+// The snapshot is filled by a single paginated DescribeInstances call filtered by the cluster tag,
+// which avoids one DescribeInstances call per Machine per reconcile.
+// A lookup that can't be answered from the snapshot reports a miss, callers should then query EC2 directly.
+type InstancesCache interface {
+	// GetInstanceByID returns the instance with the given ID if it is present in the snapshot.
+	GetInstanceByID(ctx context.Context, awsClient awsclient.Client, cacheID, clusterID, instanceID string) (*ec2.Instance, bool)
+	// GetInstancesByName returns all instances whose Name tag matches machineName if any are present in the snapshot.
+	GetInstancesByName(ctx context.Context, awsClient awsclient.Client, cacheID, clusterID, machineName string) ([]*ec2.Instance, bool)
+	// Invalidate drops the entries for the given machine name and instance IDs, so that subsequent lookups
+	// for them are answered by EC2 until the next refresh.
+	Invalidate(cacheID, clusterID, machineName string, instanceIDs ...string)
+}
+
+// instancesSnapshot holds the instances of one cluster as seen by one set of credentials in one region.
+type instancesSnapshot struct {
+	byID       map[string]*ec2.Instance
+	byName     map[string][]*ec2.Instance
+	lastUpdate time.Time
+}
+
+// instancesCache holds cached instance snapshots per cache ID and cluster. Access is synchronized via rwmutex,
+// refreshes are deduplicated per key so that a slow refresh does not block the lookups of other keys.
+type instancesCache struct {
+	ttl            time.Duration
+	failureBackoff time.Duration
+	cache          map[string]instancesSnapshot
+	failures       map[string]time.Time
+	invalidations  map[string]uint64
+	rwmutex        sync.RWMutex
+	refreshes      singleflight.Group
+}
+
+// NewInstancesCache creates an empty instances cache whose snapshots expire after ttl.
+func NewInstancesCache(ttl time.Duration) InstancesCache {
+	return &instancesCache{
+		ttl:            ttl,
+		failureBackoff: instancesCacheFailureBackoff,
+		cache:          map[string]instancesSnapshot{},
+		failures:       map[string]time.Time{},
+		invalidations:  map[string]uint64{},
+	}
+}
+
+// instancesCacheKey builds the key of a snapshot. The cacheID identifies the region and credentials used,
+// the cluster ID the filter that was applied.
+func instancesCacheKey(cacheID, clusterID string) string {
+	return fmt.Sprintf("%s/%s", cacheID, clusterID)
+}
+
+// GetInstanceByID retrieves an instance from the snapshot by ID. If the snapshot is stale it is refreshed first.
+func (c *instancesCache) GetInstanceByID(ctx context.Context, awsClient awsclient.Client, cacheID, clusterID, instanceID string) (*ec2.Instance, bool) {
+	key := instancesCacheKey(cacheID, clusterID)
+	if !c.ensureFresh(ctx, awsClient, key, clusterID) {
+		return nil, false
+	}
+
+	c.rwmutex.RLock()
+	defer c.rwmutex.RUnlock()
+
+	instance, ok := c.cache[key].byID[instanceID]
+	return instance, ok
+}
+
+// GetInstancesByName retrieves instances from the snapshot by their Name tag. If the snapshot is stale it is refreshed first.
+// An empty result is reported as a miss, as the instance may have been created after the snapshot was taken.
+func (c *instancesCache) GetInstancesByName(ctx context.Context, awsClient awsclient.Client, cacheID, clusterID, machineName string) ([]*ec2.Instance, bool) {
+	key := instancesCacheKey(cacheID, clusterID)
+	if !c.ensureFresh(ctx, awsClient, key, clusterID) {
+		return nil, false
+	}
+
+	c.rwmutex.RLock()
+	defer c.rwmutex.RUnlock()
+
+	instances := c.cache[key].byName[machineName]
+	if len(instances) == 0 {
+		return nil, false
+	}
+
+	// Callers sort the returned list, so hand out a copy.
+	return append([]*ec2.Instance{}, instances...), true
+}
+
+// Invalidate removes the entries for the machine name and instance IDs from the snapshot.
+func (c *instancesCache) Invalidate(cacheID, clusterID, machineName string, instanceIDs ...string) {
+	key := instancesCacheKey(cacheID, clusterID)
+
+	c.rwmutex.Lock()
+	defer c.rwmutex.Unlock()
+
+	// A refresh in flight may have listed the instances before this change, it must not be stored.
+	c.invalidations[key]++
+
+	snapshot, ok := c.cache[key]
+	if !ok {
+		return
+	}
+
+	for _, instance := range snapshot.byName[machineName] {
+		delete(snapshot.byID, aws.StringValue(instance.InstanceId))
+	}
+	delete(snapshot.byName, machineName)
+
+	for _, instanceID := range instanceIDs {
+		if instance, ok := snapshot.byID[instanceID]; ok {
+			delete(snapshot.byName, instanceName(instance))
+		}
+		delete(snapshot.byID, instanceID)
+	}
+	klog.V(4).Infof("Invalidated cached instances for machine %q: %v", machineName, instanceIDs)
+}
+
+// isCacheFresh checks whether the snapshot for the given key is populated and younger than the TTL.
+func (c *instancesCache) isCacheFresh(key string) bool {
+	snapshot, ok := c.cache[key]
+	return ok && snapshot.byID != nil && time.Since(snapshot.lastUpdate) < c.ttl
+}
+
+// isBackingOff checks whether the last refresh for the given key failed less than the failure backoff ago.
+func (c *instancesCache) isBackingOff(key string) bool {
+	failedAt, ok := c.failures[key]
+	return ok && time.Since(failedAt) < c.failureBackoff
+}
+
+// ensureFresh refreshes the snapshot for the given key if needed. It returns false if no fresh snapshot is available.
+func (c *instancesCache) ensureFresh(ctx context.Context, awsClient awsclient.Client, key, clusterID string) bool {
+	c.rwmutex.RLock()
+	fresh := c.isCacheFresh(key)
+	backingOff := c.isBackingOff(key)
+	c.rwmutex.RUnlock()
+	if fresh {
+		return true
+	}
+	if backingOff {
+		return false
+	}
+
+	fresh, err := c.refresh(ctx, awsClient, key, clusterID)
+	if err != nil {
+		klog.Warningf("Failed to refresh instances cache for cluster %q, falling back to direct lookups for %v: %v", clusterID, c.failureBackoff, err)
+		return false
+	}
+	return fresh
+}
+
+// refresh ensures that the snapshot is updated in a thread safe way. It returns false if the refreshed snapshot
+// was discarded because entries were invalidated while it was fetched.
+func (c *instancesCache) refresh(ctx context.Context, awsClient awsclient.Client, key, clusterID string) (bool, error) {
+	// Only one thread should refresh a snapshot at a time.
+	// The whole point of the cache is to issue a single DescribeInstances for many Machines.
+	fresh, err, _ := c.refreshes.Do(key, func() (interface{}, error) {
+		c.rwmutex.RLock()
+		fresh := c.isCacheFresh(key)
+		invalidations := c.invalidations[key]
+		c.rwmutex.RUnlock()
+		if fresh {
+			// Another thread has already refreshed the cache.
+			return true, nil
+		}
+
+		snapshot, err := fetchClusterInstances(ctx, awsClient, clusterID)
+
+		c.rwmutex.Lock()
+		defer c.rwmutex.Unlock()
+
+		if err != nil {
+			c.failures[key] = time.Now()
+			return false, err
+		}
+		delete(c.failures, key)
+
+		if c.invalidations[key] != invalidations {
+			// The snapshot may contain entries invalidated meanwhile, the next lookup refreshes it again.
+			return false, nil
+		}
+		c.cache[key] = snapshot
+		return true, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return fresh.(bool), nil
+}
+
+// fetchClusterInstances lists all instances tagged as owned by the cluster, following pagination.
+func fetchClusterInstances(ctx context.Context, awsClient awsclient.Client, clusterID string) (instancesSnapshot, error) {
+	klog.V(3).Infof("Refreshing instances cache for cluster %q", clusterID)
+
+	snapshot := instancesSnapshot{
+		byID:   map[string]*ec2.Instance{},
+		byName: map[string][]*ec2.Instance{},
+	}
+
+	input := &ec2.DescribeInstancesInput{
+		Filters:    []*ec2.Filter{clusterFilter(clusterID)},
+		MaxResults: aws.Int64(describeInstancesMaxResults),
+	}
+
+	// AWS API paginates responses, so we need to loop until we get all the results
+	requestCounter := 0
+	for {
+		requestCounter++
+		output, err := awsClient.DescribeInstances(ctx, input)
+		if err != nil {
+			return instancesSnapshot{}, fmt.Errorf("describeInstances request failed: %w", err)
+		}
+
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance == nil || instance.InstanceId == nil {
+					continue
+				}
+				snapshot.byID[*instance.InstanceId] = instance
+				if name := instanceName(instance); name != "" {
+					snapshot.byName[name] = append(snapshot.byName[name], instance)
+				}
+			}
+		}
+
+		// If next token is empty, we have all the results
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	snapshot.lastUpdate = time.Now()
+	klog.V(4).Infof("Fetched %d instances for cluster %q in %d requests", len(snapshot.byID), clusterID, requestCounter)
+	return snapshot, nil
+}
+
+// instanceName returns the value of the Name tag of the instance.
+func instanceName(instance *ec2.Instance) string {
+	for _, tag := range instance.Tags {
+		if tag != nil && aws.StringValue(tag.Key) == "Name" {
+			return aws.StringValue(tag.Value)
+		}
+	}
+	return ""
+}
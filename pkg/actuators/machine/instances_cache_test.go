@@ -0,0 +1,227 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+)
+
+func stubNamedInstance(instanceID, name, state string) *ec2.Instance {
+	return &ec2.Instance{
+		InstanceId: aws.String(instanceID),
+		State:      &ec2.InstanceState{Name: aws.String(state)},
+		Tags: []*ec2.Tag{
+			{Key: aws.String("Name"), Value: aws.String(name)},
+		},
+	}
+}
+
+func TestInstancesCacheLookups(t *testing.T) {
+	clusterID := "aws-actuator-cluster"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(ctrl)
+
+	// The cache must be filled by a single paginated, cluster-filtered request.
+	mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), &ec2.DescribeInstancesInput{
+		Filters:    []*ec2.Filter{clusterFilter(clusterID)},
+		MaxResults: aws.Int64(describeInstancesMaxResults),
+	}).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{Instances: []*ec2.Instance{stubNamedInstance("i-1", "machine-1", ec2.InstanceStateNameRunning)}},
+		},
+		NextToken: aws.String("page-2"),
+	}, nil).Times(1)
+	mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), &ec2.DescribeInstancesInput{
+		Filters:    []*ec2.Filter{clusterFilter(clusterID)},
+		MaxResults: aws.Int64(describeInstancesMaxResults),
+		NextToken:  aws.String("page-2"),
+	}).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{Instances: []*ec2.Instance{
+				stubNamedInstance("i-2", "machine-2", ec2.InstanceStateNameRunning),
+				stubNamedInstance("i-3", "machine-2", ec2.InstanceStateNameTerminated),
+			}},
+		},
+	}, nil).Times(1)
+
+	cache := NewInstancesCache(time.Minute)
+
+	instance, ok := cache.GetInstanceByID(context.TODO(), mockAWSClient, "us-east-1", clusterID, "i-1")
+	if !ok || aws.StringValue(instance.InstanceId) != "i-1" {
+		t.Errorf("Expected to find instance i-1, got %v (found: %t)", instance, ok)
+	}
+
+	instances, ok := cache.GetInstancesByName(context.TODO(), mockAWSClient, "us-east-1", clusterID, "machine-2")
+	if !ok || len(instances) != 2 {
+		t.Errorf("Expected to find 2 instances for machine-2, got %v (found: %t)", instances, ok)
+	}
+
+	if _, ok := cache.GetInstanceByID(context.TODO(), mockAWSClient, "us-east-1", clusterID, "i-4"); ok {
+		t.Error("Expected a miss for unknown instance i-4")
+	}
+
+	if _, ok := cache.GetInstancesByName(context.TODO(), mockAWSClient, "us-east-1", clusterID, "machine-3"); ok {
+		t.Error("Expected a miss for machine-3 without instances")
+	}
+
+	cache.Invalidate("us-east-1", clusterID, "machine-2", "i-1")
+
+	if _, ok := cache.GetInstancesByName(context.TODO(), mockAWSClient, "us-east-1", clusterID, "machine-2"); ok {
+		t.Error("Expected a miss for machine-2 after invalidation")
+	}
+
+	if _, ok := cache.GetInstanceByID(context.TODO(), mockAWSClient, "us-east-1", clusterID, "i-3"); ok {
+		t.Error("Expected a miss for instance i-3 of machine-2 after invalidation")
+	}
+
+	if _, ok := cache.GetInstanceByID(context.TODO(), mockAWSClient, "us-east-1", clusterID, "i-1"); ok {
+		t.Error("Expected a miss for instance i-1 after invalidation")
+	}
+}
+
+func TestInstancesCacheRefresh(t *testing.T) {
+	clusterID := "aws-actuator-cluster"
+
+	testCases := []struct {
+		name           string
+		ttl            time.Duration
+		failureBackoff time.Duration
+		err            error
+		expectedCalls  int
+		expectFound    bool
+	}{
+		{
+			name:          "fresh cache is reused",
+			ttl:           time.Minute,
+			expectedCalls: 1,
+			expectFound:   true,
+		},
+		{
+			name:          "expired cache is refreshed",
+			ttl:           0,
+			expectedCalls: 2,
+			expectFound:   true,
+		},
+		{
+			name:           "refresh errors report a miss and back off",
+			ttl:            time.Minute,
+			failureBackoff: time.Minute,
+			err:            errors.New("RequestLimitExceeded"),
+			expectedCalls:  1,
+			expectFound:    false,
+		},
+		{
+			name:          "refresh errors are retried after the backoff",
+			ttl:           time.Minute,
+			err:           errors.New("RequestLimitExceeded"),
+			expectedCalls: 2,
+			expectFound:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockAWSClient := mockaws.NewMockClient(ctrl)
+
+			var output *ec2.DescribeInstancesOutput
+			if tc.err == nil {
+				output = &ec2.DescribeInstancesOutput{
+					Reservations: []*ec2.Reservation{
+						{Instances: []*ec2.Instance{stubNamedInstance("i-1", "machine-1", ec2.InstanceStateNameRunning)}},
+					},
+				}
+			}
+			mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).Return(output, tc.err).Times(tc.expectedCalls)
+
+			cache := NewInstancesCache(tc.ttl).(*instancesCache)
+			cache.failureBackoff = tc.failureBackoff
+			for i := 0; i < 2; i++ {
+				if _, ok := cache.GetInstanceByID(context.TODO(), mockAWSClient, "us-east-1", clusterID, "i-1"); ok != tc.expectFound {
+					t.Errorf("Expected found to be %t, got %t", tc.expectFound, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestInstancesCacheInvalidationDuringRefresh(t *testing.T) {
+	clusterID := "aws-actuator-cluster"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(ctrl)
+
+	cache := NewInstancesCache(time.Minute)
+
+	// The instance is invalidated, e.g. because it was terminated, after it was listed.
+	mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+		cache.Invalidate("us-east-1", clusterID, "machine-1", "i-1")
+		return &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{Instances: []*ec2.Instance{stubNamedInstance("i-1", "machine-1", ec2.InstanceStateNameRunning)}},
+			},
+		}, nil
+	}).Times(1)
+
+	if _, ok := cache.GetInstanceByID(context.TODO(), mockAWSClient, "us-east-1", clusterID, "i-1"); ok {
+		t.Error("Expected a miss for instance i-1 invalidated during the refresh")
+	}
+}
+
+func TestGetMachineInstancesFromCache(t *testing.T) {
+	machine, err := stubMachine()
+	if err != nil {
+		t.Fatalf("unable to build stub machine: %v", err)
+	}
+	clusterID, _ := getClusterID(machine)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(ctrl)
+
+	mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), &ec2.DescribeInstancesInput{
+		Filters:    []*ec2.Filter{clusterFilter(clusterID)},
+		MaxResults: aws.Int64(describeInstancesMaxResults),
+	}).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{Instances: []*ec2.Instance{stubNamedInstance("i-1", machine.Name, ec2.InstanceStateNameRunning)}},
+		},
+	}, nil).Times(1)
+
+	reconciler := newReconciler(&machineScope{
+		awsClient:      mockAWSClient,
+		machine:        machine,
+		providerStatus: &machinev1beta1.AWSMachineProviderStatus{},
+		instancesCache: NewInstancesCache(time.Minute),
+		cacheID:        "us-east-1",
+	})
+
+	// Both lookups, by name and then by ID, must be answered by the single cache fill.
+	instances, err := reconciler.getMachineInstances()
+	if err != nil {
+		t.Fatalf("Unexpected error from getMachineInstances: %v", err)
+	}
+	if len(instances) != 1 || aws.StringValue(instances[0].InstanceId) != "i-1" {
+		t.Fatalf("Expected instance i-1, got %v", instances)
+	}
+
+	reconciler.providerStatus.InstanceID = aws.String("i-1")
+	instances, err = reconciler.getMachineInstances()
+	if err != nil {
+		t.Fatalf("Unexpected error from getMachineInstances: %v", err)
+	}
+	if len(instances) != 1 || aws.StringValue(instances[0].InstanceId) != "i-1" {
+		t.Fatalf("Expected instance i-1, got %v", instances)
+	}
+}
@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -218,9 +219,9 @@ func TestBuildEC2Filters(t *testing.T) {
 		},
 	}
 
-	got := buildEC2Filters(inputFilters)
+	got := BuildEC2Filters(inputFilters)
 	if !reflect.DeepEqual(expected, got) {
-		t.Errorf("failed to buildEC2Filters. Expected: %+v, got: %+v", expected, got)
+		t.Errorf("failed to BuildEC2Filters. Expected: %+v, got: %+v", expected, got)
 	}
 }
 
@@ -234,7 +235,7 @@ func TestGetBlockDeviceMappings(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	mockAWSClient := mockaws.NewMockClient(mockCtrl)
-	mockAWSClient.EXPECT().DescribeImages(gomock.Any()).Return(&ec2.DescribeImagesOutput{
+	mockAWSClient.EXPECT().DescribeImages(gomock.Any(), gomock.Any()).Return(&ec2.DescribeImagesOutput{
 		Images: []*ec2.Image{
 			{
 				CreationDate:   aws.String(time.RFC3339),
@@ -359,7 +360,7 @@ func TestGetBlockDeviceMappings(t *testing.T) {
 		Namespace: "fake",
 	}
 	for _, tc := range testCases {
-		got, err := getBlockDeviceMappings(fakeMachineKey, tc.blockDevices, "existing-AMI", mockAWSClient)
+		got, err := getBlockDeviceMappings(context.TODO(), fakeMachineKey, tc.blockDevices, "existing-AMI", mockAWSClient)
 		if tc.expectedErr {
 			if err == nil {
 				t.Error("Expected error")
@@ -435,9 +436,9 @@ func TestRemoveStoppedMachine(t *testing.T) {
 			mockAWSClient := mockaws.NewMockClient(mockCtrl)
 			// Not here to check how many times all the mocked methods get called.
 			// Rather to provide fake outputs to get through all possible execution paths.
-			mockAWSClient.EXPECT().DescribeInstances(gomock.Any()).Return(tc.output, tc.err).AnyTimes()
-			mockAWSClient.EXPECT().TerminateInstances(gomock.Any()).AnyTimes()
-			removeStoppedMachine(machine, mockAWSClient)
+			mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).Return(tc.output, tc.err).AnyTimes()
+			mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), gomock.Any()).AnyTimes()
+			removeStoppedMachine(context.TODO(), machine, mockAWSClient)
 		})
 	}
 }
@@ -1290,15 +1291,15 @@ func TestLaunchInstance(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAWSClient := mockaws.NewMockClient(mockCtrl)
 
-			mockAWSClient.EXPECT().DescribeSecurityGroups(gomock.Any()).Return(tc.securityGroupOutput, tc.securityGroupErr).AnyTimes()
-			mockAWSClient.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(tc.zonesOutput, nil).AnyTimes()
-			mockAWSClient.EXPECT().DescribeSubnets(gomock.Any()).Return(tc.subnetOutput, tc.subnetErr).AnyTimes()
-			mockAWSClient.EXPECT().DescribeImages(gomock.Any()).Return(tc.imageOutput, tc.imageErr).AnyTimes()
-			mockAWSClient.EXPECT().RunInstances(tc.runInstancesInput).Return(tc.instancesOutput, tc.instancesErr).AnyTimes()
+			mockAWSClient.EXPECT().DescribeSecurityGroups(gomock.Any(), gomock.Any()).Return(tc.securityGroupOutput, tc.securityGroupErr).AnyTimes()
+			mockAWSClient.EXPECT().DescribeAvailabilityZones(gomock.Any(), gomock.Any()).Return(tc.zonesOutput, nil).AnyTimes()
+			mockAWSClient.EXPECT().DescribeSubnets(gomock.Any(), gomock.Any()).Return(tc.subnetOutput, tc.subnetErr).AnyTimes()
+			mockAWSClient.EXPECT().DescribeImages(gomock.Any(), gomock.Any()).Return(tc.imageOutput, tc.imageErr).AnyTimes()
+			mockAWSClient.EXPECT().RunInstances(gomock.Any(), tc.runInstancesInput).Return(tc.instancesOutput, tc.instancesErr).AnyTimes()
 
 			fakeClient := fake.NewFakeClient(tc.objects...)
 
-			_, _, launchErr := launchInstance(machine, tc.providerConfig, nil, mockAWSClient, fakeClient, tc.infra)
+			_, _, launchErr := launchInstance(context.TODO(), machine, tc.providerConfig, nil, mockAWSClient, fakeClient, tc.infra)
 			t.Log(launchErr)
 			if launchErr == nil {
 				if !tc.succeeds {
@@ -1334,10 +1335,11 @@ func TestSortInstances(t *testing.T) {
 func TestGetInstanceMarketOptionsRequest(t *testing.T) {
 	mockCapacityReservationID := "cr-123"
 	testCases := []struct {
-		name            string
-		providerConfig  *machinev1beta1.AWSMachineProviderConfig
-		expectedRequest *ec2.InstanceMarketOptionsRequest
-		wantErr         bool
+		name                 string
+		providerConfig       *machinev1beta1.AWSMachineProviderConfig
+		interruptionBehavior string
+		expectedRequest      *ec2.InstanceMarketOptionsRequest
+		wantErr              bool
 	}{
 		{
 			name:            "with no Spot options specified",
@@ -1424,6 +1426,43 @@ func TestGetInstanceMarketOptionsRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "with the stop interruption behavior",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				SpotMarketOptions: &machinev1beta1.SpotMarketOptions{},
+			},
+			interruptionBehavior: ec2.InstanceInterruptionBehaviorStop,
+			expectedRequest: &ec2.InstanceMarketOptionsRequest{
+				MarketType: aws.String(ec2.MarketTypeSpot),
+				SpotOptions: &ec2.SpotMarketOptions{
+					InstanceInterruptionBehavior: aws.String(ec2.InstanceInterruptionBehaviorStop),
+					SpotInstanceType:             aws.String(ec2.SpotInstanceTypePersistent),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "with the hibernate interruption behavior",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				MarketType: machinev1beta1.MarketTypeSpot,
+			},
+			interruptionBehavior: ec2.InstanceInterruptionBehaviorHibernate,
+			expectedRequest: &ec2.InstanceMarketOptionsRequest{
+				MarketType: aws.String(ec2.MarketTypeSpot),
+				SpotOptions: &ec2.SpotMarketOptions{
+					InstanceInterruptionBehavior: aws.String(ec2.InstanceInterruptionBehaviorHibernate),
+					SpotInstanceType:             aws.String(ec2.SpotInstanceTypePersistent),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:                 "with the stop interruption behavior on demand",
+			providerConfig:       &machinev1beta1.AWSMachineProviderConfig{},
+			interruptionBehavior: ec2.InstanceInterruptionBehaviorStop,
+			expectedRequest:      nil,
+			wantErr:              false,
+		},
 		{
 			name:            "invalid MarketType specified",
 			expectedRequest: nil,
@@ -1467,7 +1506,7 @@ func TestGetInstanceMarketOptionsRequest(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			g := gmg.NewWithT(t)
 
-			request, err := getInstanceMarketOptionsRequest(tc.providerConfig)
+			request, err := getInstanceMarketOptionsRequest(tc.providerConfig, tc.interruptionBehavior)
 			if err == nil {
 				g.Expect(request).To(gmg.BeEquivalentTo(tc.expectedRequest))
 			} else {
@@ -1793,10 +1832,10 @@ func TestCorrectExistingTags(t *testing.T) {
 			instance.Tags = tc.tags
 
 			if tc.expectedCreateTags {
-				mockAWSClient.EXPECT().CreateTags(gomock.Any()).Return(&ec2.CreateTagsOutput{}, nil).MinTimes(1)
+				mockAWSClient.EXPECT().CreateTags(gomock.Any(), gomock.Any()).Return(&ec2.CreateTagsOutput{}, nil).MinTimes(1)
 			}
 
-			err := correctExistingTags(machine, &instance, mockAWSClient, tc.userTags)
+			err := correctExistingTags(context.TODO(), machine, &instance, mockAWSClient, tc.userTags)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
@@ -1853,9 +1892,9 @@ func TestGetAvalabilityZoneTypeFromZoneName(t *testing.T) {
 
 			mockCtrl := gomock.NewController(t)
 			mockAWSClient := mockaws.NewMockClient(mockCtrl)
-			mockAWSClient.EXPECT().DescribeAvailabilityZones(gomock.Any()).Return(tc.args.zonesOutput, nil).AnyTimes()
+			mockAWSClient.EXPECT().DescribeAvailabilityZones(gomock.Any(), gomock.Any()).Return(tc.args.zonesOutput, nil).AnyTimes()
 
-			got, err := getAvalabilityZoneTypeFromZoneName(tc.args.zoneName, mockAWSClient)
+			got, err := getAvalabilityZoneTypeFromZoneName(context.TODO(), tc.args.zoneName, mockAWSClient)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("getAvalabilityZoneTypeFromZoneName() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"fmt"
 
 	errorutil "k8s.io/apimachinery/pkg/util/errors"
@@ -15,7 +16,7 @@ import (
 	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
 )
 
-func registerWithClassicLoadBalancers(client awsclient.Client, names []string, instance *ec2.Instance) error {
+func registerWithClassicLoadBalancers(ctx context.Context, client awsclient.Client, names []string, instance *ec2.Instance) error {
 	klog.V(4).Infof("Updating classic load balancer registration for %q", *instance.InstanceId)
 	elbInstance := &elb.Instance{InstanceId: instance.InstanceId}
 	var errs []error
@@ -24,7 +25,7 @@ func registerWithClassicLoadBalancers(client awsclient.Client, names []string, i
 			Instances:        []*elb.Instance{elbInstance},
 			LoadBalancerName: aws.String(elbName),
 		}
-		_, err := client.RegisterInstancesWithLoadBalancer(req)
+		_, err := client.RegisterInstancesWithLoadBalancer(ctx, req)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("%s: %v", elbName, err))
 		}
@@ -36,9 +37,9 @@ func registerWithClassicLoadBalancers(client awsclient.Client, names []string, i
 	return nil
 }
 
-func registerWithNetworkLoadBalancers(client awsclient.Client, names []string, instance *ec2.Instance) error {
+func registerWithNetworkLoadBalancers(ctx context.Context, client awsclient.Client, names []string, instance *ec2.Instance) error {
 	klog.V(4).Infof("Updating network load balancer registration for %q", *instance.InstanceId)
-	targetGroups, err := gatherLoadBalancerTargetGroups(client, names)
+	targetGroups, err := gatherLoadBalancerTargetGroups(ctx, client, names)
 	if err != nil {
 		return err
 	}
@@ -60,7 +61,7 @@ func registerWithNetworkLoadBalancers(client awsclient.Client, names []string, i
 			klog.V(4).Infof("Registering instance %q by IP to target group: %v", *instance.InstanceId, *targetGroup.TargetGroupArn)
 		}
 
-		registeredTargets, err := gatherLoadBalancerTargetGroupRegisteredTargets(client, targetGroup.TargetGroupArn)
+		registeredTargets, err := gatherLoadBalancerTargetGroupRegisteredTargets(ctx, client, targetGroup.TargetGroupArn)
 		if err != nil {
 			klog.Errorf("Failed to gather registered targets for target group %q: %v", *targetGroup.TargetGroupArn, err)
 			errs = append(errs, fmt.Errorf("%s: %v", *targetGroup.TargetGroupArn, err))
@@ -76,7 +77,7 @@ func registerWithNetworkLoadBalancers(client awsclient.Client, names []string, i
 			TargetGroupArn: targetGroup.TargetGroupArn,
 			Targets:        []*elbv2.TargetDescription{target},
 		}
-		if _, err := client.ELBv2RegisterTargets(registerTargetsInput); err != nil {
+		if _, err := client.ELBv2RegisterTargets(ctx, registerTargetsInput); err != nil {
 			klog.Errorf("Failed to register instance %q with target group %q: %v", *instance.InstanceId, *targetGroup.TargetGroupArn, err)
 			errs = append(errs, fmt.Errorf("%s: %v", *targetGroup.TargetGroupArn, err))
 		}
@@ -89,14 +90,14 @@ func registerWithNetworkLoadBalancers(client awsclient.Client, names []string, i
 
 // deregisterNetworkLoadBalancers serves manual instance removal from Network LoadBalancer TargetGroup list
 // for the instances attached by IP. Unlike instance reference, IP attachment should be cleaned manually.
-func deregisterNetworkLoadBalancers(client awsclient.Client, names []string, instance *ec2.Instance) error {
+func deregisterNetworkLoadBalancers(ctx context.Context, client awsclient.Client, names []string, instance *ec2.Instance) error {
 	if instance.PrivateIpAddress == nil {
 		klog.V(4).Infof("Instance %q does not have private ip, skipping...", *instance.InstanceId)
 		return nil
 	}
 
 	klog.V(4).Infof("Removing network load balancer registration for %q", *instance.InstanceId)
-	targetGroupsOutput, err := gatherLoadBalancerTargetGroups(client, names)
+	targetGroupsOutput, err := gatherLoadBalancerTargetGroups(ctx, client, names)
 	if err != nil {
 		return err
 	}
@@ -118,7 +119,7 @@ func deregisterNetworkLoadBalancers(client awsclient.Client, names []string, ins
 				Id: instance.PrivateIpAddress,
 			}},
 		}
-		_, err := client.ELBv2DeregisterTargets(deregisterTargetsInput)
+		_, err := client.ELBv2DeregisterTargets(ctx, deregisterTargetsInput)
 		if err != nil {
 			if aerr, ok := err.(awserr.Error); ok {
 				switch aerr.Code() {
@@ -137,7 +138,7 @@ func deregisterNetworkLoadBalancers(client awsclient.Client, names []string, ins
 	return nil
 }
 
-func gatherLoadBalancerTargetGroups(client awsclient.Client, names []string) ([]*elbv2.TargetGroup, error) {
+func gatherLoadBalancerTargetGroups(ctx context.Context, client awsclient.Client, names []string) ([]*elbv2.TargetGroup, error) {
 	lbNames := make([]*string, len(names))
 	for i, name := range names {
 		lbNames[i] = aws.String(name)
@@ -145,7 +146,7 @@ func gatherLoadBalancerTargetGroups(client awsclient.Client, names []string) ([]
 	lbsRequest := &elbv2.DescribeLoadBalancersInput{
 		Names: lbNames,
 	}
-	lbsResponse, err := client.ELBv2DescribeLoadBalancers(lbsRequest)
+	lbsResponse, err := client.ELBv2DescribeLoadBalancers(ctx, lbsRequest)
 	if err != nil {
 		klog.Errorf("Failed to describe load balancers %v: %v", names, err)
 		return nil, err
@@ -157,7 +158,7 @@ func gatherLoadBalancerTargetGroups(client awsclient.Client, names []string) ([]
 		targetGroupsInput := &elbv2.DescribeTargetGroupsInput{
 			LoadBalancerArn: loadBalancer.LoadBalancerArn,
 		}
-		targetGroupsOutput, err := client.ELBv2DescribeTargetGroups(targetGroupsInput)
+		targetGroupsOutput, err := client.ELBv2DescribeTargetGroups(ctx, targetGroupsInput)
 		if err != nil {
 			klog.Errorf("Failed to retrieve load balancer target groups for %q: %v", *loadBalancer.LoadBalancerName, err)
 			return nil, err
@@ -172,11 +173,11 @@ func gatherLoadBalancerTargetGroups(client awsclient.Client, names []string) ([]
 // Within the AWS API, the only way to find the targets that are registered is to look at the target health for the group.
 // The target health response contains all of the targets and importantly, their IDs which we need later to compare with
 // the target ID we are wanting to register.
-func gatherLoadBalancerTargetGroupRegisteredTargets(client awsclient.Client, targetGroupArn *string) (map[string]struct{}, error) {
+func gatherLoadBalancerTargetGroupRegisteredTargets(ctx context.Context, client awsclient.Client, targetGroupArn *string) (map[string]struct{}, error) {
 	targetHealthRequest := &elbv2.DescribeTargetHealthInput{
 		TargetGroupArn: targetGroupArn,
 	}
-	targetHealthResponse, err := client.ELBv2DescribeTargetHealth(targetHealthRequest)
+	targetHealthResponse, err := client.ELBv2DescribeTargetHealth(ctx, targetHealthRequest)
 	if err != nil {
 		klog.Errorf("Failed to describe target health: %v", err)
 		return nil, err
@@ -35,6 +35,14 @@ type machineScopeParams struct {
 	configManagedClient runtimeclient.Client
 	// cache for DescribeRegions API call results
 	regionCache awsclient.RegionCache
+	// cache for DescribeInstances API call results, shared between machines
+	instancesCache InstancesCache
+	// cache for describe API calls of rarely changing resources, shared between machines
+	describeCache awsclient.DescribeCache
+	// history of spot interruptions, shared with the MachineSet controller
+	spotInterruptions SpotInterruptionHistory
+	// resolver of the instance requirements of machines, nil if disabled
+	instanceTypeResolver InstanceTypeResolver
 }
 
 type idleCloser interface {
@@ -54,6 +62,14 @@ type machineScope struct {
 	originalStatus     machinev1beta1.MachineStatus
 	providerSpec       *machinev1beta1.AWSMachineProviderConfig
 	providerStatus     *machinev1beta1.AWSMachineProviderStatus
+	// shared instances cache, nil if disabled
+	instancesCache InstancesCache
+	// identifies the region and credentials of awsClient within the shared caches
+	cacheID string
+	// shared history of spot interruptions, nil if disabled
+	spotInterruptions SpotInterruptionHistory
+	// resolver of the instance requirements of the machine, nil if disabled
+	instanceTypeResolver InstanceTypeResolver
 }
 
 func newMachineScope(params machineScopeParams) (*machineScope, error) {
@@ -72,20 +88,29 @@ func newMachineScope(params machineScopeParams) (*machineScope, error) {
 		credentialsSecretName = providerSpec.CredentialsSecret.Name
 	}
 
-	awsClient, err := params.awsClientBuilder(params.client, credentialsSecretName, params.machine.Namespace, providerSpec.Placement.Region, params.configManagedClient, params.regionCache)
+	awsClient, err := params.awsClientBuilder(params.Context, params.client, credentialsSecretName, params.machine.Namespace, providerSpec.Placement.Region, params.configManagedClient, params.regionCache)
 	if err != nil {
 		return nil, machineapierros.InvalidMachineConfiguration("failed to create aws client: %v", err.Error())
 	}
 
+	cacheID := fmt.Sprintf("%s/%s/%s", providerSpec.Placement.Region, params.machine.Namespace, credentialsSecretName)
+	if params.describeCache != nil {
+		awsClient = params.describeCache.WrapClient(awsClient, cacheID)
+	}
+
 	return &machineScope{
-		Context:            params.Context,
-		awsClient:          awsClient,
-		client:             params.client,
-		machine:            params.machine,
-		machineToBePatched: runtimeclient.MergeFrom(params.machine.DeepCopy()),
-		originalStatus:     params.machine.DeepCopy().Status,
-		providerSpec:       providerSpec,
-		providerStatus:     providerStatus,
+		Context:              params.Context,
+		awsClient:            awsClient,
+		client:               params.client,
+		machine:              params.machine,
+		machineToBePatched:   runtimeclient.MergeFrom(params.machine.DeepCopy()),
+		originalStatus:       params.machine.DeepCopy().Status,
+		providerSpec:         providerSpec,
+		providerStatus:       providerStatus,
+		instancesCache:       params.instancesCache,
+		cacheID:              cacheID,
+		spotInterruptions:    params.spotInterruptions,
+		instanceTypeResolver: params.instanceTypeResolver,
 	}, nil
 }
 
@@ -209,7 +234,7 @@ func (s *machineScope) setProviderStatus(instance *ec2.Instance, condition metav
 }
 
 func (s *machineScope) getCustomDomainFromDHCP(vpcID *string) ([]string, error) {
-	vpc, err := s.awsClient.DescribeVpcs(&ec2.DescribeVpcsInput{
+	vpc, err := s.awsClient.DescribeVpcs(s.Context, &ec2.DescribeVpcsInput{
 		VpcIds: []*string{vpcID},
 	})
 	if err != nil {
@@ -221,7 +246,7 @@ func (s *machineScope) getCustomDomainFromDHCP(vpcID *string) ([]string, error)
 		return nil, nil
 	}
 
-	dhcp, err := s.awsClient.DescribeDHCPOptions(&ec2.DescribeDhcpOptionsInput{
+	dhcp, err := s.awsClient.DescribeDHCPOptions(s.Context, &ec2.DescribeDhcpOptionsInput{
 		DhcpOptionsIds: []*string{vpc.Vpcs[0].DhcpOptionsId},
 	})
 	if err != nil {
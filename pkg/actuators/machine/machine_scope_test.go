@@ -261,7 +261,7 @@ func TestPatchMachine(t *testing.T) {
 			machineScope, err := newMachineScope(machineScopeParams{
 				client:  k8sClient,
 				machine: machine,
-				awsClientBuilder: func(client runtimeclient.Client, secretName, namespace, region string, configManagedClient runtimeclient.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
+				awsClientBuilder: func(ctx context.Context, client runtimeclient.Client, secretName, namespace, region string, configManagedClient runtimeclient.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
 					return nil, nil
 				},
 			})
@@ -367,7 +367,7 @@ func TestGetCustomDomainFromDHCP(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
 		mockAWSClient := mockaws.NewMockClient(mockCtrl)
 		dhcpID := "someID"
-		mockAWSClient.EXPECT().DescribeVpcs(gomock.Any()).Return(&ec2.DescribeVpcsOutput{
+		mockAWSClient.EXPECT().DescribeVpcs(gomock.Any(), gomock.Any()).Return(&ec2.DescribeVpcsOutput{
 			Vpcs: []*ec2.Vpc{
 				{DhcpOptionsId: &dhcpID},
 			},
@@ -377,7 +377,7 @@ func TestGetCustomDomainFromDHCP(t *testing.T) {
 			awsClient: mockAWSClient,
 		}
 
-		mockAWSClient.EXPECT().DescribeDHCPOptions(gomock.Any()).Return(tc.describeDhcpOptionsOutput, nil).AnyTimes()
+		mockAWSClient.EXPECT().DescribeDHCPOptions(gomock.Any(), gomock.Any()).Return(tc.describeDhcpOptionsOutput, nil).AnyTimes()
 
 		got, err := mS.getCustomDomainFromDHCP(nil)
 		if err != nil {
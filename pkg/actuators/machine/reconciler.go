@@ -79,12 +79,16 @@ func (r *Reconciler) create() error {
 	} else {
 		if !isMaster {
 			// Prevent having a lot of stopped nodes sitting around.
-			if err = removeStoppedMachine(r.machine, r.awsClient); err != nil {
+			if err = removeStoppedMachine(r.Context, r.machine, r.awsClient); err != nil {
 				return fmt.Errorf("unable to remove stopped machines: %w", err)
 			}
 		}
 	}
 
+	if err := r.resolveInstanceType(); err != nil {
+		return fmt.Errorf("%v: failed resolving instance type: %w", r.machine.GetName(), err)
+	}
+
 	userData, err := r.machineScope.getUserData()
 	if err != nil {
 		return fmt.Errorf("failed to get user data: %w", err)
@@ -97,7 +101,9 @@ func (r *Reconciler) create() error {
 		return err
 	}
 
-	instance, allocatedHostID, err := launchInstance(r.machine, r.providerSpec, userData, r.awsClient, r.client, infra)
+	instance, allocatedHostID, err := launchInstance(r.Context, r.machine, r.providerSpec, userData, r.awsClient, r.client, infra)
+	// Stopped instances may have been terminated above, make sure the next lookup doesn't use stale data.
+	r.invalidateCachedMachineInstances()
 	if err != nil {
 		klog.Errorf("%s: error creating machine: %v", r.machine.Name, err)
 		conditionFailed := conditionFailed()
@@ -158,6 +164,9 @@ func (r *Reconciler) delete() error {
 	}
 
 	isTerminated := r.checkIfInstanceTerminated(existingInstances[0])
+	if err := r.checkSpotInterruption(existingInstances[0]); err != nil {
+		klog.Errorf("%s: failed to check for spot interruption: %v", r.machine.Name, err)
+	}
 	var terminatingInstances []*ec2.InstanceStateChange
 
 	if !isTerminated {
@@ -170,7 +179,8 @@ func (r *Reconciler) delete() error {
 			return fmt.Errorf("failed to remove instance from load balancers: %w", err)
 		}
 
-		terminatingInstances, err = terminateInstances(r.awsClient, existingInstances)
+		terminatingInstances, err = terminateInstances(r.Context, r.awsClient, r.machine, existingInstances)
+		r.invalidateCachedMachineInstances(existingInstances...)
 		if err != nil {
 			metrics.RegisterFailedInstanceDelete(&metrics.MachineLabels{
 				Name:      r.machine.Name,
@@ -197,7 +207,7 @@ func (r *Reconciler) delete() error {
 		// Release dynamically allocated dedicated host if present
 		if allocatedHostID != "" {
 			klog.Infof("%s: releasing dynamically allocated dedicated host %s", r.machine.Name, allocatedHostID)
-			if err := releaseDedicatedHost(r.awsClient, allocatedHostID, r.machine.Name); err != nil {
+			if err := releaseDedicatedHost(r.Context, r.awsClient, allocatedHostID, r.machine.Name); err != nil {
 				klog.Errorf("%s: failed to release dedicated host %s: %v", r.machine.Name, allocatedHostID, err)
 				// Don't return error here - we still want to mark the machine as deleted
 				// The dedicated host will need to be cleaned up manually
@@ -309,6 +319,15 @@ func (r *Reconciler) update() error {
 		newestInstance = existingInstances[0]
 	}
 
+	if err := r.checkSpotInterruption(newestInstance); err != nil {
+		klog.Errorf("%s: failed to check for spot interruption: %v", r.machine.Name, err)
+	}
+
+	spotRequest, err := r.updateSpotRequestCondition(newestInstance)
+	if err != nil {
+		klog.Errorf("%s: failed to update spot request condition: %v", r.machine.Name, err)
+	}
+
 	if err = r.setProviderID(newestInstance); err != nil {
 		return fmt.Errorf("failed to update machine object with providerID: %w", err)
 	}
@@ -317,7 +336,7 @@ func (r *Reconciler) update() error {
 		return fmt.Errorf("failed to set machine cloud provider specifics: %w", err)
 	}
 
-	if err = correctExistingTags(r.machine, newestInstance, r.awsClient, tagList); err != nil {
+	if err = correctExistingTags(r.Context, r.machine, newestInstance, r.awsClient, tagList); err != nil {
 		return fmt.Errorf("failed to correct existing instance tags: %w", err)
 	}
 
@@ -325,6 +344,22 @@ func (r *Reconciler) update() error {
 
 	r.machineScope.setProviderStatus(newestInstance, conditionSuccess())
 
+	if isSpotInstanceStopped(newestInstance) {
+		if spotRequest != nil && isSpotRequestClosed(spotRequest) {
+			// The request expired or was cancelled, the instance stays stopped. This is reflected in the
+			// SpotRequest condition, waiting for the instance is pointless.
+			klog.Warningf("%s: spot instance %s is %s and its spot request %s is %s, it will not be started again", r.machine.Name,
+				aws.StringValue(newestInstance.InstanceId), aws.StringValue(newestInstance.State.Name),
+				aws.StringValue(spotRequest.SpotInstanceRequestId), aws.StringValue(spotRequest.State))
+			return nil
+		}
+		// The instance is started again by EC2 once spot capacity is available,
+		// keep polling it rather than treating the Machine as failed.
+		klog.Infof("%s: spot instance %s is %s following an interruption, waiting for it to be started again", r.machine.Name,
+			aws.StringValue(newestInstance.InstanceId), aws.StringValue(newestInstance.State.Name))
+		return &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterSeconds * time.Second}
+	}
+
 	return r.requeueIfInstancePending(newestInstance)
 }
 
@@ -410,14 +445,14 @@ func (r *Reconciler) updateLoadBalancers(instance *ec2.Instance) error {
 
 	var err error
 	if len(classicLoadBalancerNames) > 0 {
-		err := registerWithClassicLoadBalancers(r.awsClient, classicLoadBalancerNames, instance)
+		err := registerWithClassicLoadBalancers(r.Context, r.awsClient, classicLoadBalancerNames, instance)
 		if err != nil {
 			klog.Errorf("%s: Failed to register classic load balancers: %v", r.machine.Name, err)
 			errs = append(errs, err)
 		}
 	}
 	if len(networkLoadBalancerNames) > 0 {
-		err = registerWithNetworkLoadBalancers(r.awsClient, networkLoadBalancerNames, instance)
+		err = registerWithNetworkLoadBalancers(r.Context, r.awsClient, networkLoadBalancerNames, instance)
 		if err != nil {
 			klog.Errorf("%s: Failed to register network load balancers: %v", r.machine.Name, err)
 			errs = append(errs, err)
@@ -445,7 +480,7 @@ func (r *Reconciler) removeFromLoadBalancers(instances []*ec2.Instance) error {
 	errs := []error{}
 	if len(networkLoadBalancerNames) > 0 {
 		for _, instance := range instances {
-			err := deregisterNetworkLoadBalancers(r.awsClient, networkLoadBalancerNames, instance)
+			err := deregisterNetworkLoadBalancers(r.Context, r.awsClient, networkLoadBalancerNames, instance)
 			if err != nil {
 				klog.Errorf("%s: Failed to register network load balancers: %v", r.machine.Name, err)
 				errs = append(errs, err)
@@ -552,10 +587,14 @@ func (r *Reconciler) checkIfInstanceTerminated(instance *ec2.Instance) bool {
 }
 
 func (r *Reconciler) getMachineInstances() ([]*ec2.Instance, error) {
+	if instances, ok := r.getCachedMachineInstances(); ok {
+		return instances, nil
+	}
+
 	// If there is a non-empty instance ID, search using that, otherwise
 	// fallback to filtering based on tags.
 	if r.providerStatus.InstanceID != nil && *r.providerStatus.InstanceID != "" {
-		i, err := getExistingInstanceByID(*r.providerStatus.InstanceID, r.awsClient)
+		i, err := getExistingInstanceByID(r.Context, *r.providerStatus.InstanceID, r.awsClient)
 		if err != nil {
 			klog.Warningf("%s: Failed to find existing instance by id %s: %v", r.machine.Name, *r.providerStatus.InstanceID, err)
 		} else {
@@ -564,5 +603,67 @@ func (r *Reconciler) getMachineInstances() ([]*ec2.Instance, error) {
 		}
 	}
 
-	return getExistingInstances(r.machine, r.awsClient)
+	return getExistingInstances(r.Context, r.machine, r.awsClient)
+}
+
+// getCachedMachineInstances looks up the machine instances in the shared instances cache.
+// It returns false if the cache is disabled or can't answer the lookup, in which case EC2 should be queried directly.
+func (r *Reconciler) getCachedMachineInstances() ([]*ec2.Instance, bool) {
+	if r.instancesCache == nil {
+		return nil, false
+	}
+
+	clusterID, ok := getClusterID(r.machine)
+	if !ok {
+		return nil, false
+	}
+
+	if r.providerStatus.InstanceID != nil && *r.providerStatus.InstanceID != "" {
+		instance, ok := r.instancesCache.GetInstanceByID(r.Context, r.awsClient, r.cacheID, clusterID, *r.providerStatus.InstanceID)
+		if !ok || instanceHasAllowedState(instance, existingInstanceStates()) != nil {
+			return nil, false
+		}
+		klog.V(3).Infof("%s: Found cached instance by id: %s", r.machine.Name, *r.providerStatus.InstanceID)
+		return []*ec2.Instance{instance}, true
+	}
+
+	cached, ok := r.instancesCache.GetInstancesByName(r.Context, r.awsClient, r.cacheID, clusterID, r.machine.Name)
+	if !ok {
+		return nil, false
+	}
+
+	instances := make([]*ec2.Instance, 0, len(cached))
+	for _, instance := range cached {
+		if err := instanceHasAllowedState(instance, existingInstanceStates()); err != nil {
+			klog.Errorf("Excluding instance matching %s: %v", r.machine.Name, err)
+		} else {
+			instances = append(instances, instance)
+		}
+	}
+	if len(instances) == 0 {
+		return nil, false
+	}
+
+	klog.V(3).Infof("%s: Found %d cached instances by tags", r.machine.Name, len(instances))
+	return instances, true
+}
+
+// invalidateCachedMachineInstances drops the machine and the given instances from the shared instances cache
+// so that the next lookups observe the result of a create or delete.
+func (r *Reconciler) invalidateCachedMachineInstances(instances ...*ec2.Instance) {
+	if r.instancesCache == nil {
+		return
+	}
+
+	clusterID, ok := getClusterID(r.machine)
+	if !ok {
+		return
+	}
+
+	instanceIDs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		instanceIDs = append(instanceIDs, aws.StringValue(instance.InstanceId))
+	}
+
+	r.instancesCache.Invalidate(r.cacheID, clusterID, r.machine.Name, instanceIDs...)
 }
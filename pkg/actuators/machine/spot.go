@@ -0,0 +1,192 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SpotInterruptionBehaviorAnnotation selects what happens to the spot instance of a Machine when EC2 interrupts it:
+	// terminate, the default, stop or hibernate. Stopped and hibernated instances are started again by EC2 once capacity
+	// is available, which requires a persistent spot request. Once the request expires, 7 days after its creation by
+	// default, stopped instances are no longer started. Hibernation additionally requires an encrypted root volume.
+	SpotInterruptionBehaviorAnnotation = "machine.openshift.io/spot-interruption-behavior"
+
+	// spotRequestConditionType is the provider status condition reflecting the state of the spot request of the instance.
+	spotRequestConditionType = "SpotRequest"
+)
+
+// getSpotInterruptionBehavior returns the interruption behavior requested for the spot instance of the machine.
+func getSpotInterruptionBehavior(machine *machinev1beta1.Machine) (string, error) {
+	behavior, ok := machine.Annotations[SpotInterruptionBehaviorAnnotation]
+	if !ok {
+		return ec2.InstanceInterruptionBehaviorTerminate, nil
+	}
+
+	switch behavior {
+	case ec2.InstanceInterruptionBehaviorTerminate, ec2.InstanceInterruptionBehaviorStop, ec2.InstanceInterruptionBehaviorHibernate:
+		return behavior, nil
+	default:
+		return "", machinecontroller.InvalidMachineConfiguration("invalid %s annotation %q, must be one of %s, %s or %s", SpotInterruptionBehaviorAnnotation, behavior,
+			ec2.InstanceInterruptionBehaviorTerminate, ec2.InstanceInterruptionBehaviorStop, ec2.InstanceInterruptionBehaviorHibernate)
+	}
+}
+
+// hasPersistentSpotRequest returns true if the spot instance of the machine is requested with a persistent spot
+// request, i.e. with the stop or hibernate interruption behavior. One-time requests close once their instance is
+// launched and need no further handling.
+func hasPersistentSpotRequest(machine *machinev1beta1.Machine) bool {
+	behavior, err := getSpotInterruptionBehavior(machine)
+	return err == nil && behavior != ec2.InstanceInterruptionBehaviorTerminate
+}
+
+// ensureEncryptedRootDevice encrypts the root volume of the instance, as required for hibernation.
+// The root volume is added to the block devices if it is not configured.
+func ensureEncryptedRootDevice(providerConfig *machinev1beta1.AWSMachineProviderConfig) error {
+	for i, blockDevice := range providerConfig.BlockDevices {
+		if blockDevice.DeviceName != nil || blockDevice.EBS == nil {
+			continue
+		}
+		if blockDevice.EBS.Encrypted != nil && !*blockDevice.EBS.Encrypted {
+			return machinecontroller.InvalidMachineConfiguration("hibernation requires an encrypted root volume, but the root volume is configured as unencrypted")
+		}
+		providerConfig.BlockDevices[i].EBS.Encrypted = aws.Bool(true)
+		return nil
+	}
+
+	providerConfig.BlockDevices = append(providerConfig.BlockDevices, machinev1beta1.BlockDeviceMappingSpec{
+		EBS: &machinev1beta1.EBSBlockDeviceSpec{Encrypted: aws.Bool(true)},
+	})
+	return nil
+}
+
+// isSpotInstanceStopped returns true if the instance was stopped or hibernated following a spot interruption.
+// Such instances are started again by EC2 once capacity is available, as long as their spot request is open.
+func isSpotInstanceStopped(instance *ec2.Instance) bool {
+	if !isSpotInstance(instance) || aws.StringValue(instance.SpotInstanceRequestId) == "" || instance.State == nil {
+		return false
+	}
+	state := aws.StringValue(instance.State.Name)
+	return state == ec2.InstanceStateNameStopped || state == ec2.InstanceStateNameStopping
+}
+
+// isSpotRequestClosed returns true if the spot request was closed, cancelled, e.g. once it expired, or failed.
+// A stopped instance of such a request is never started again by EC2.
+func isSpotRequestClosed(request *ec2.SpotInstanceRequest) bool {
+	switch aws.StringValue(request.State) {
+	case ec2.SpotInstanceStateClosed, ec2.SpotInstanceStateCancelled, ec2.SpotInstanceStateFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// updateSpotRequestCondition reflects the state of the persistent spot request of the instance in the provider status,
+// and returns the request. One-time requests are not described, they are closed as soon as they are fulfilled.
+func (r *Reconciler) updateSpotRequestCondition(instance *ec2.Instance) (*ec2.SpotInstanceRequest, error) {
+	requestID := aws.StringValue(instance.SpotInstanceRequestId)
+	if requestID == "" || !hasPersistentSpotRequest(r.machine) {
+		return nil, nil
+	}
+
+	output, err := r.awsClient.DescribeSpotInstanceRequests(r.Context, &ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []*string{aws.String(requestID)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing spot instance request %s: %w", requestID, err)
+	}
+	if len(output.SpotInstanceRequests) == 0 {
+		return nil, nil
+	}
+
+	request := output.SpotInstanceRequests[0]
+	r.providerStatus.Conditions = setCondition(spotRequestCondition(request), r.providerStatus.Conditions)
+	return request, nil
+}
+
+// spotRequestCondition returns the condition reflecting the state of the spot request.
+// The condition is true while the request is active, i.e. fulfilled by a running instance.
+func spotRequestCondition(request *ec2.SpotInstanceRequest) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   spotRequestConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: "Unknown",
+	}
+	if aws.StringValue(request.State) == ec2.SpotInstanceStateActive {
+		condition.Status = metav1.ConditionTrue
+	}
+	if request.Status != nil {
+		if code := aws.StringValue(request.Status.Code); code != "" {
+			condition.Reason = conditionReasonFromCode(code)
+		}
+		condition.Message = fmt.Sprintf("Spot request %s is %s: %s", aws.StringValue(request.SpotInstanceRequestId), aws.StringValue(request.State), aws.StringValue(request.Status.Message))
+	}
+	return condition
+}
+
+// conditionReasonFromCode converts a status code such as instance-stopped-by-price to a condition reason
+// such as InstanceStoppedByPrice.
+func conditionReasonFromCode(code string) string {
+	words := strings.FieldsFunc(code, func(r rune) bool {
+		return r == '-' || r == '_' || r == ' '
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, "")
+}
+
+// cancelSpotInstanceRequests cancels the persistent spot requests of the instances. They must be cancelled
+// before their instances are terminated, otherwise EC2 launches replacement instances. The request type is read from
+// EC2 rather than the Machine, whose annotations may have changed since the launch. If it cannot be read, all the
+// requests are cancelled, which is harmless for the one-time requests already fulfilled.
+// An error is returned if requests which are, or may be, persistent cannot be cancelled: mayBePersistent is set
+// when the Machine requests a persistent spot request, and stopped spot instances always have one. Failing to
+// cancel one-time requests, e.g. without the ec2:CancelSpotInstanceRequests permission, is only logged.
+func cancelSpotInstanceRequests(ctx context.Context, client awsclient.Client, instances []*ec2.Instance, mayBePersistent bool) error {
+	requestIDs := []*string{}
+	for _, instance := range instances {
+		if requestID := aws.StringValue(instance.SpotInstanceRequestId); requestID != "" {
+			requestIDs = append(requestIDs, aws.String(requestID))
+			mayBePersistent = mayBePersistent || isSpotInstanceStopped(instance)
+		}
+	}
+	if len(requestIDs) == 0 {
+		return nil
+	}
+
+	output, err := client.DescribeSpotInstanceRequests(ctx, &ec2.DescribeSpotInstanceRequestsInput{SpotInstanceRequestIds: requestIDs})
+	if err != nil {
+		klog.Errorf("Error describing spot instance requests %v, cancelling all of them: %v", aws.StringValueSlice(requestIDs), err)
+	} else {
+		mayBePersistent = true
+		requestIDs = []*string{}
+		for _, request := range output.SpotInstanceRequests {
+			if aws.StringValue(request.Type) == ec2.SpotInstanceTypePersistent && !isSpotRequestClosed(request) {
+				requestIDs = append(requestIDs, request.SpotInstanceRequestId)
+			}
+		}
+		if len(requestIDs) == 0 {
+			return nil
+		}
+	}
+
+	klog.Infof("Cancelling spot instance requests %v", aws.StringValueSlice(requestIDs))
+	if _, err := client.CancelSpotInstanceRequests(ctx, &ec2.CancelSpotInstanceRequestsInput{SpotInstanceRequestIds: requestIDs}); err != nil {
+		if !mayBePersistent {
+			klog.Errorf("Error cancelling spot instance requests %v, assuming they are one-time requests: %v", aws.StringValueSlice(requestIDs), err)
+			return nil
+		}
+		return fmt.Errorf("error cancelling spot instance requests %v: %w", aws.StringValueSlice(requestIDs), err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package machine
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultSpotInterruptionRateWindow is the default rolling window over which spot interruptions are kept.
+	DefaultSpotInterruptionRateWindow = 24 * time.Hour
+
+	// SpotInterruptionRecordedAnnotation is set on a Machine once the interruption of its spot instance has been
+	// recorded, so that it is only recorded once. Its value is the ID of the interrupted instance.
+	SpotInterruptionRecordedAnnotation = "machine.openshift.io/spot-interruption-recorded"
+
+	// nodeTerminatingConditionType is set on nodes by the termination handler when their instance
+	// receives an interruption notice.
+	nodeTerminatingConditionType corev1.NodeConditionType = "Terminating"
+
+	// spotInstanceTerminationStateReason is the state reason of spot instances terminated by EC2.
+	spotInstanceTerminationStateReason = "Server.SpotInstanceTermination"
+
+	spotInterruptionSourceNodeCondition      = "node_condition"
+	spotInterruptionSourceInstanceTerminated = "instance_terminated"
+)
+
+var (
+	spotInterruptions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_aws_spot_interruptions_total",
+			Help: "Number of spot instance interruptions observed by the Machine controller.",
+		}, []string{"instance_type", "availability_zone", "source"},
+	)
+
+	spotInterruptionUptime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "mapi_aws_spot_interruption_uptime_seconds",
+			Help: "Time spot instances were running for before being interrupted.",
+			Buckets: []float64{
+				(5 * time.Minute).Seconds(), (15 * time.Minute).Seconds(), (30 * time.Minute).Seconds(),
+				time.Hour.Seconds(), (3 * time.Hour).Seconds(), (6 * time.Hour).Seconds(), (12 * time.Hour).Seconds(),
+				(24 * time.Hour).Seconds(), (3 * 24 * time.Hour).Seconds(), (7 * 24 * time.Hour).Seconds(),
+			},
+		}, []string{"instance_type", "availability_zone"},
+	)
+
+	// stateTransitionTimeRegexp extracts the time from state transition reasons such as
+	// "Service initiated (2017-09-18 08:22:00 GMT)".
+	stateTransitionTimeRegexp = regexp.MustCompile(`\((\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) GMT\)`)
+)
+
+func init() {
+	metrics.Registry.MustRegister(spotInterruptions, spotInterruptionUptime)
+}
+
+// SpotInterruption is a spot instance interruption observed by the Machine controller.
+type SpotInterruption struct {
+	InstanceID       string
+	InstanceType     string
+	AvailabilityZone string
+	// Namespace and MachineSet identify the MachineSet owning the interrupted Machine, if any.
+	Namespace  string
+	MachineSet string
+	// Time is when the instance was interrupted, Uptime how long it had been running for.
+	Time   time.Time
+	Uptime time.Duration
+}
+
+// SpotInterruptionHistory keeps the spot interruptions observed over a rolling window,
+// so that the MachineSet controller can report interruption rates.
+// The history is kept in memory, it is shared by the controllers of the manager and lost when it restarts.
+type SpotInterruptionHistory interface {
+	// Record adds an interruption to the history.
+	Record(interruption SpotInterruption)
+	// Count returns the number of interruptions of Machines owned by the MachineSet within the window.
+	Count(namespace, machineSet string, now time.Time) int
+	// Window returns the duration for which interruptions are kept.
+	Window() time.Duration
+}
+
+// spotInterruptionHistory holds the interruptions of the window in the order they were recorded.
+// Access is synchronized via mutex.
+type spotInterruptionHistory struct {
+	window        time.Duration
+	interruptions []SpotInterruption
+	mutex         sync.Mutex
+}
+
+// NewSpotInterruptionHistory creates an empty history keeping interruptions for the window.
+func NewSpotInterruptionHistory(window time.Duration) SpotInterruptionHistory {
+	return &spotInterruptionHistory{window: window}
+}
+
+// Record implements SpotInterruptionHistory
+func (h *spotInterruptionHistory) Record(interruption SpotInterruption) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.prune(time.Now())
+	h.interruptions = append(h.interruptions, interruption)
+}
+
+// Count implements SpotInterruptionHistory
+func (h *spotInterruptionHistory) Count(namespace, machineSet string, now time.Time) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.prune(now)
+	count := 0
+	for _, interruption := range h.interruptions {
+		if interruption.Namespace == namespace && interruption.MachineSet == machineSet {
+			count++
+		}
+	}
+	return count
+}
+
+// Window implements SpotInterruptionHistory
+func (h *spotInterruptionHistory) Window() time.Duration {
+	return h.window
+}
+
+// prune drops the interruptions older than the window.
+func (h *spotInterruptionHistory) prune(now time.Time) {
+	kept := h.interruptions[:0]
+	for _, interruption := range h.interruptions {
+		if now.Sub(interruption.Time) < h.window {
+			kept = append(kept, interruption)
+		}
+	}
+	h.interruptions = kept
+}
+
+// isSpotInstance returns true if the instance was launched as a spot instance.
+func isSpotInstance(instance *ec2.Instance) bool {
+	return instance != nil && aws.StringValue(instance.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot
+}
+
+// spotInstanceInterruptedByEC2 returns true if the spot instance was terminated by EC2, as opposed to by a user.
+func spotInstanceInterruptedByEC2(instance *ec2.Instance) bool {
+	return isSpotInstance(instance) && instance.StateReason != nil &&
+		aws.StringValue(instance.StateReason.Code) == spotInstanceTerminationStateReason
+}
+
+// instanceTerminationTime returns when the instance was terminated according to its state transition reason,
+// or now if the reason does not include a time.
+func instanceTerminationTime(instance *ec2.Instance, now time.Time) time.Time {
+	match := stateTransitionTimeRegexp.FindStringSubmatch(aws.StringValue(instance.StateTransitionReason))
+	if match == nil {
+		return now
+	}
+	t, err := time.Parse("2006-01-02 15:04:05", match[1])
+	if err != nil {
+		return now
+	}
+	return t
+}
+
+// nodeTerminatingSince returns the transition time of the terminating condition of the node,
+// or false if the node is not terminating.
+func nodeTerminatingSince(node *corev1.Node) (time.Time, bool) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == nodeTerminatingConditionType && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// owningMachineSet returns the name of the MachineSet controlling the machine, or an empty string.
+func owningMachineSet(machine *machinev1beta1.Machine) string {
+	for _, ref := range machine.OwnerReferences {
+		if ref.Kind == "MachineSet" && ref.Controller != nil && *ref.Controller {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// recordSpotInterruption records the interruption of the spot instance of the machine in the metrics and the
+// history, unless it was already recorded. The machine is annotated so that it is recorded only once.
+func (r *Reconciler) recordSpotInterruption(instance *ec2.Instance, interruptedAt time.Time, source string) {
+	instanceID := aws.StringValue(instance.InstanceId)
+	if r.machine.Annotations[SpotInterruptionRecordedAnnotation] == instanceID {
+		return
+	}
+
+	interruption := SpotInterruption{
+		InstanceID:   instanceID,
+		InstanceType: aws.StringValue(instance.InstanceType),
+		Namespace:    r.machine.Namespace,
+		MachineSet:   owningMachineSet(r.machine),
+		Time:         interruptedAt.UTC(),
+	}
+	if instance.Placement != nil {
+		interruption.AvailabilityZone = aws.StringValue(instance.Placement.AvailabilityZone)
+	}
+	if instance.LaunchTime != nil && interruptedAt.After(*instance.LaunchTime) {
+		interruption.Uptime = interruptedAt.Sub(*instance.LaunchTime)
+	}
+
+	klog.Infof("%s: spot instance %s interrupted after %s (source: %s)", r.machine.Name, instanceID, interruption.Uptime.Round(time.Second), source)
+	spotInterruptions.WithLabelValues(interruption.InstanceType, interruption.AvailabilityZone, source).Inc()
+	spotInterruptionUptime.WithLabelValues(interruption.InstanceType, interruption.AvailabilityZone).Observe(interruption.Uptime.Seconds())
+	if r.spotInterruptions != nil {
+		r.spotInterruptions.Record(interruption)
+	}
+
+	if r.machine.Annotations == nil {
+		r.machine.Annotations = make(map[string]string)
+	}
+	r.machine.Annotations[SpotInterruptionRecordedAnnotation] = instanceID
+}
+
+// checkSpotInterruption records the interruption of the spot instance of the machine, if it was terminated
+// by EC2 or its node received an interruption notice.
+func (r *Reconciler) checkSpotInterruption(instance *ec2.Instance) error {
+	if !isSpotInstance(instance) {
+		return nil
+	}
+
+	if spotInstanceInterruptedByEC2(instance) {
+		r.recordSpotInterruption(instance, instanceTerminationTime(instance, time.Now()), spotInterruptionSourceInstanceTerminated)
+		return nil
+	}
+
+	if r.machine.Status.NodeRef == nil {
+		return nil
+	}
+	node := &corev1.Node{}
+	if err := r.client.Get(r.Context, client.ObjectKey{Name: r.machine.Status.NodeRef.Name}, node); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get node %s: %w", r.machine.Status.NodeRef.Name, err)
+	}
+	if since, ok := nodeTerminatingSince(node); ok {
+		r.recordSpotInterruption(instance, since, spotInterruptionSourceNodeCondition)
+	}
+	return nil
+}
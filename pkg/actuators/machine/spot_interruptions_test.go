@@ -0,0 +1,163 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordingSpotInterruptionHistory keeps all the recorded interruptions
+type recordingSpotInterruptionHistory struct {
+	interruptions []SpotInterruption
+}
+
+func (h *recordingSpotInterruptionHistory) Record(interruption SpotInterruption) {
+	h.interruptions = append(h.interruptions, interruption)
+}
+
+func (h *recordingSpotInterruptionHistory) Count(_, _ string, _ time.Time) int {
+	return len(h.interruptions)
+}
+
+func (h *recordingSpotInterruptionHistory) Window() time.Duration {
+	return DefaultSpotInterruptionRateWindow
+}
+
+func TestCheckSpotInterruption(t *testing.T) {
+	launchTime := time.Date(2017, 9, 18, 6, 22, 0, 0, time.UTC)
+	terminatingSince := metav1.NewTime(time.Date(2017, 9, 18, 8, 20, 0, 0, time.UTC))
+
+	stubSpotInstance := func(state string, stateReason string) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceId:            aws.String("i-1"),
+			InstanceType:          aws.String("m5.large"),
+			InstanceLifecycle:     aws.String(ec2.InstanceLifecycleTypeSpot),
+			LaunchTime:            aws.Time(launchTime),
+			Placement:             &ec2.Placement{AvailabilityZone: aws.String("us-east-1a")},
+			State:                 &ec2.InstanceState{Name: aws.String(state)},
+			StateReason:           &ec2.StateReason{Code: aws.String(stateReason)},
+			StateTransitionReason: aws.String("Service initiated (2017-09-18 08:22:00 GMT)"),
+		}
+	}
+
+	testCases := []struct {
+		name                  string
+		instance              *ec2.Instance
+		nodeConditions        []corev1.NodeCondition
+		existingAnnotations   map[string]string
+		expectedInterruptions []SpotInterruption
+	}{
+		{
+			name: "with an on-demand instance",
+			instance: &ec2.Instance{
+				InstanceId: aws.String("i-1"),
+				State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameTerminated)},
+			},
+			nodeConditions: []corev1.NodeCondition{{Type: nodeTerminatingConditionType, Status: corev1.ConditionTrue}},
+		},
+		{
+			name:     "with a spot instance terminated by EC2",
+			instance: stubSpotInstance(ec2.InstanceStateNameTerminated, spotInstanceTerminationStateReason),
+			expectedInterruptions: []SpotInterruption{{
+				InstanceID:       "i-1",
+				InstanceType:     "m5.large",
+				AvailabilityZone: "us-east-1a",
+				Namespace:        "default",
+				MachineSet:       "spot-machineset",
+				Time:             time.Date(2017, 9, 18, 8, 22, 0, 0, time.UTC),
+				Uptime:           2 * time.Hour,
+			}},
+		},
+		{
+			name:     "with a spot instance terminated by a user",
+			instance: stubSpotInstance(ec2.InstanceStateNameTerminated, "Client.UserInitiatedShutdown"),
+		},
+		{
+			name:           "with a running spot instance whose node is terminating",
+			instance:       stubSpotInstance(ec2.InstanceStateNameRunning, ""),
+			nodeConditions: []corev1.NodeCondition{{Type: nodeTerminatingConditionType, Status: corev1.ConditionTrue, LastTransitionTime: terminatingSince}},
+			expectedInterruptions: []SpotInterruption{{
+				InstanceID:       "i-1",
+				InstanceType:     "m5.large",
+				AvailabilityZone: "us-east-1a",
+				Namespace:        "default",
+				MachineSet:       "spot-machineset",
+				Time:             terminatingSince.Time,
+				Uptime:           118 * time.Minute,
+			}},
+		},
+		{
+			name:     "with a running spot instance whose node is not terminating",
+			instance: stubSpotInstance(ec2.InstanceStateNameRunning, ""),
+		},
+		{
+			name:                "with an interruption already recorded",
+			instance:            stubSpotInstance(ec2.InstanceStateNameTerminated, spotInstanceTerminationStateReason),
+			existingAnnotations: map[string]string{SpotInterruptionRecordedAnnotation: "i-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			g := NewWithT(tt)
+
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "spot-node"},
+				Status:     corev1.NodeStatus{Conditions: tc.nodeConditions},
+			}
+			machine := &machinev1beta1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "spot-machine",
+					Namespace:   "default",
+					Annotations: tc.existingAnnotations,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: machinev1beta1.GroupVersion.String(),
+						Kind:       "MachineSet",
+						Name:       "spot-machineset",
+						Controller: ptr.To(true),
+					}},
+				},
+				Status: machinev1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: node.Name}},
+			}
+			history := &recordingSpotInterruptionHistory{}
+
+			r := newReconciler(&machineScope{
+				Context:           context.TODO(),
+				client:            fake.NewClientBuilder().WithObjects(node).Build(),
+				machine:           machine,
+				spotInterruptions: history,
+			})
+
+			g.Expect(r.checkSpotInterruption(tc.instance)).To(Succeed())
+			g.Expect(history.interruptions).To(Equal(tc.expectedInterruptions))
+			if len(tc.expectedInterruptions) > 0 {
+				g.Expect(machine.Annotations).To(HaveKeyWithValue(SpotInterruptionRecordedAnnotation, "i-1"))
+			}
+		})
+	}
+}
+
+func TestSpotInterruptionHistory(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+
+	history := NewSpotInterruptionHistory(time.Hour)
+	history.Record(SpotInterruption{InstanceID: "i-1", Namespace: "default", MachineSet: "a", Time: now.Add(-30 * time.Minute)})
+	history.Record(SpotInterruption{InstanceID: "i-2", Namespace: "default", MachineSet: "a", Time: now.Add(-50 * time.Minute)})
+	history.Record(SpotInterruption{InstanceID: "i-3", Namespace: "default", MachineSet: "b", Time: now})
+
+	g.Expect(history.Count("default", "a", now)).To(Equal(2))
+	g.Expect(history.Count("other", "a", now)).To(Equal(0))
+	// Interruptions leave the window as time passes
+	g.Expect(history.Count("default", "a", now.Add(20*time.Minute))).To(Equal(1))
+	g.Expect(history.Count("default", "b", now.Add(20*time.Minute))).To(Equal(1))
+}
@@ -0,0 +1,378 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetSpotInterruptionBehavior(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expected    string
+		expectErr   bool
+	}{
+		{
+			name:     "defaults to terminate",
+			expected: ec2.InstanceInterruptionBehaviorTerminate,
+		},
+		{
+			name:        "with stop",
+			annotations: map[string]string{SpotInterruptionBehaviorAnnotation: "stop"},
+			expected:    ec2.InstanceInterruptionBehaviorStop,
+		},
+		{
+			name:        "with hibernate",
+			annotations: map[string]string{SpotInterruptionBehaviorAnnotation: "hibernate"},
+			expected:    ec2.InstanceInterruptionBehaviorHibernate,
+		},
+		{
+			name:        "with an invalid value",
+			annotations: map[string]string{SpotInterruptionBehaviorAnnotation: "pause"},
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			behavior, err := getSpotInterruptionBehavior(machine)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(behavior).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestEnsureEncryptedRootDevice(t *testing.T) {
+	testCases := []struct {
+		name         string
+		blockDevices []machinev1beta1.BlockDeviceMappingSpec
+		expected     []machinev1beta1.BlockDeviceMappingSpec
+		expectErr    bool
+	}{
+		{
+			name: "adds an encrypted root device",
+			expected: []machinev1beta1.BlockDeviceMappingSpec{
+				{EBS: &machinev1beta1.EBSBlockDeviceSpec{Encrypted: aws.Bool(true)}},
+			},
+		},
+		{
+			name: "encrypts the configured root device",
+			blockDevices: []machinev1beta1.BlockDeviceMappingSpec{
+				{DeviceName: aws.String("/dev/sdb"), EBS: &machinev1beta1.EBSBlockDeviceSpec{VolumeSize: aws.Int64(100)}},
+				{EBS: &machinev1beta1.EBSBlockDeviceSpec{VolumeSize: aws.Int64(120)}},
+			},
+			expected: []machinev1beta1.BlockDeviceMappingSpec{
+				{DeviceName: aws.String("/dev/sdb"), EBS: &machinev1beta1.EBSBlockDeviceSpec{VolumeSize: aws.Int64(100)}},
+				{EBS: &machinev1beta1.EBSBlockDeviceSpec{VolumeSize: aws.Int64(120), Encrypted: aws.Bool(true)}},
+			},
+		},
+		{
+			name: "rejects an unencrypted root device",
+			blockDevices: []machinev1beta1.BlockDeviceMappingSpec{
+				{EBS: &machinev1beta1.EBSBlockDeviceSpec{Encrypted: aws.Bool(false)}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := &machinev1beta1.AWSMachineProviderConfig{BlockDevices: tc.blockDevices}
+			err := ensureEncryptedRootDevice(providerConfig)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(providerConfig.BlockDevices).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSpotRequestCondition(t *testing.T) {
+	testCases := []struct {
+		name     string
+		request  *ec2.SpotInstanceRequest
+		expected metav1.Condition
+	}{
+		{
+			name: "active request",
+			request: &ec2.SpotInstanceRequest{
+				SpotInstanceRequestId: aws.String("sir-1"),
+				State:                 aws.String(ec2.SpotInstanceStateActive),
+				Status:                &ec2.SpotInstanceStatus{Code: aws.String("fulfilled"), Message: aws.String("Your spot request is fulfilled.")},
+			},
+			expected: metav1.Condition{
+				Type:    spotRequestConditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Fulfilled",
+				Message: "Spot request sir-1 is active: Your spot request is fulfilled.",
+			},
+		},
+		{
+			name: "disabled request of a stopped instance",
+			request: &ec2.SpotInstanceRequest{
+				SpotInstanceRequestId: aws.String("sir-1"),
+				State:                 aws.String(ec2.SpotInstanceStateDisabled),
+				Status:                &ec2.SpotInstanceStatus{Code: aws.String("instance-stopped-by-price"), Message: aws.String("Your instance was stopped because the price exceeded your maximum price.")},
+			},
+			expected: metav1.Condition{
+				Type:    spotRequestConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  "InstanceStoppedByPrice",
+				Message: "Spot request sir-1 is disabled: Your instance was stopped because the price exceeded your maximum price.",
+			},
+		},
+		{
+			name:    "request without status",
+			request: &ec2.SpotInstanceRequest{State: aws.String(ec2.SpotInstanceStateOpen)},
+			expected: metav1.Condition{
+				Type:   spotRequestConditionType,
+				Status: metav1.ConditionFalse,
+				Reason: "Unknown",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(spotRequestCondition(tc.request)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestIsSpotRequestClosed(t *testing.T) {
+	g := NewWithT(t)
+	for state, closed := range map[string]bool{
+		ec2.SpotInstanceStateOpen:      false,
+		ec2.SpotInstanceStateActive:    false,
+		ec2.SpotInstanceStateDisabled:  false,
+		ec2.SpotInstanceStateClosed:    true,
+		ec2.SpotInstanceStateCancelled: true,
+		ec2.SpotInstanceStateFailed:    true,
+	} {
+		g.Expect(isSpotRequestClosed(&ec2.SpotInstanceRequest{State: aws.String(state)})).To(Equal(closed), state)
+	}
+}
+
+func TestIsSpotInstanceStopped(t *testing.T) {
+	stubInstance := func(lifecycle, requestID, state string) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceLifecycle:     aws.String(lifecycle),
+			SpotInstanceRequestId: aws.String(requestID),
+			State:                 &ec2.InstanceState{Name: aws.String(state)},
+		}
+	}
+
+	g := NewWithT(t)
+	g.Expect(isSpotInstanceStopped(stubInstance(ec2.InstanceLifecycleTypeSpot, "sir-1", ec2.InstanceStateNameStopped))).To(BeTrue())
+	g.Expect(isSpotInstanceStopped(stubInstance(ec2.InstanceLifecycleTypeSpot, "sir-1", ec2.InstanceStateNameStopping))).To(BeTrue())
+	g.Expect(isSpotInstanceStopped(stubInstance(ec2.InstanceLifecycleTypeSpot, "sir-1", ec2.InstanceStateNameRunning))).To(BeFalse())
+	g.Expect(isSpotInstanceStopped(stubInstance(ec2.InstanceLifecycleTypeSpot, "", ec2.InstanceStateNameStopped))).To(BeFalse())
+	g.Expect(isSpotInstanceStopped(stubInstance(ec2.InstanceLifecycleTypeScheduled, "sir-1", ec2.InstanceStateNameStopped))).To(BeFalse())
+}
+
+func TestTerminateInstancesCancelsSpotRequests(t *testing.T) {
+	launchTime := time.Now()
+	instances := []*ec2.Instance{
+		{
+			InstanceId:            aws.String("i-1"),
+			InstanceLifecycle:     aws.String(ec2.InstanceLifecycleTypeSpot),
+			SpotInstanceRequestId: aws.String("sir-1"),
+			State:                 &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameStopped)},
+			LaunchTime:            &launchTime,
+		},
+		{
+			InstanceId: aws.String("i-2"),
+			State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+			LaunchTime: &launchTime,
+		},
+	}
+
+	machine := &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "spot"}}
+
+	describeRequest := func(mockAWSClient *mockaws.MockClient, requestType string) *gomock.Call {
+		return mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), &ec2.DescribeSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []*string{aws.String("sir-1")},
+		}).Return(&ec2.DescribeSpotInstanceRequestsOutput{SpotInstanceRequests: []*ec2.SpotInstanceRequest{{
+			SpotInstanceRequestId: aws.String("sir-1"),
+			Type:                  aws.String(requestType),
+			State:                 aws.String(ec2.SpotInstanceStateDisabled),
+		}}}, nil)
+	}
+
+	t.Run("cancels persistent spot requests before terminating", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		gomock.InOrder(
+			describeRequest(mockAWSClient, ec2.SpotInstanceTypePersistent),
+			mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), &ec2.CancelSpotInstanceRequestsInput{
+				SpotInstanceRequestIds: []*string{aws.String("sir-1")},
+			}).Return(&ec2.CancelSpotInstanceRequestsOutput{}, nil),
+			mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), &ec2.TerminateInstancesInput{
+				InstanceIds: []*string{aws.String("i-1"), aws.String("i-2")},
+			}).Return(&ec2.TerminateInstancesOutput{}, nil),
+		)
+
+		_, err := terminateInstances(context.Background(), mockAWSClient, machine, instances)
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("does not cancel one-time spot requests", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		describeRequest(mockAWSClient, ec2.SpotInstanceTypeOneTime)
+		mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), gomock.Any()).Times(0)
+		mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), gomock.Any()).Return(&ec2.TerminateInstancesOutput{}, nil)
+
+		_, err := terminateInstances(context.Background(), mockAWSClient, machine, instances)
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("cancels the spot requests if their type cannot be described", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, errors.New("throttled"))
+		mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), &ec2.CancelSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []*string{aws.String("sir-1")},
+		}).Return(&ec2.CancelSpotInstanceRequestsOutput{}, nil)
+		mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), gomock.Any()).Return(&ec2.TerminateInstancesOutput{}, nil)
+
+		_, err := terminateInstances(context.Background(), mockAWSClient, machine, instances)
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("does not terminate if persistent spot requests cannot be cancelled", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		describeRequest(mockAWSClient, ec2.SpotInstanceTypePersistent)
+		mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, errors.New("throttled"))
+		mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := terminateInstances(context.Background(), mockAWSClient, machine, instances)
+		g.Expect(err).To(MatchError(ContainSubstring("throttled")))
+	})
+
+	t.Run("does not terminate if spot requests which may be persistent cannot be described nor cancelled", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		unauthorized := errors.New("UnauthorizedOperation")
+		mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, unauthorized)
+		mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, unauthorized)
+		mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), gomock.Any()).Times(0)
+
+		// The stopped instance can only have a persistent spot request.
+		_, err := terminateInstances(context.Background(), mockAWSClient, machine, instances)
+		g.Expect(err).To(MatchError(unauthorized))
+	})
+
+	t.Run("terminates if one-time spot requests cannot be described nor cancelled", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		running := []*ec2.Instance{{
+			InstanceId:            aws.String("i-1"),
+			InstanceLifecycle:     aws.String(ec2.InstanceLifecycleTypeSpot),
+			SpotInstanceRequestId: aws.String("sir-1"),
+			State:                 &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+			LaunchTime:            &launchTime,
+		}}
+		unauthorized := errors.New("UnauthorizedOperation")
+		mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, unauthorized)
+		mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, unauthorized)
+		mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), gomock.Any()).Return(&ec2.TerminateInstancesOutput{}, nil)
+
+		_, err := terminateInstances(context.Background(), mockAWSClient, machine, running)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		// Machines requesting a persistent spot request are not terminated.
+		persistent := machine.DeepCopy()
+		persistent.Annotations = map[string]string{SpotInterruptionBehaviorAnnotation: ec2.InstanceInterruptionBehaviorStop}
+		mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, unauthorized)
+		mockAWSClient.EXPECT().CancelSpotInstanceRequests(gomock.Any(), gomock.Any()).Return(nil, unauthorized)
+		_, err = terminateInstances(context.Background(), mockAWSClient, persistent, running)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestUpdateSpotRequestCondition(t *testing.T) {
+	instance := &ec2.Instance{
+		InstanceId:            aws.String("i-1"),
+		InstanceLifecycle:     aws.String(ec2.InstanceLifecycleTypeSpot),
+		SpotInstanceRequestId: aws.String("sir-1"),
+	}
+
+	t.Run("describes persistent spot requests", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), &ec2.DescribeSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []*string{aws.String("sir-1")},
+		}).Return(&ec2.DescribeSpotInstanceRequestsOutput{SpotInstanceRequests: []*ec2.SpotInstanceRequest{{
+			SpotInstanceRequestId: aws.String("sir-1"),
+			State:                 aws.String(ec2.SpotInstanceStateActive),
+		}}}, nil)
+
+		r := newReconciler(&machineScope{
+			Context:        context.Background(),
+			awsClient:      mockAWSClient,
+			machine:        &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{SpotInterruptionBehaviorAnnotation: "stop"}}},
+			providerStatus: &machinev1beta1.AWSMachineProviderStatus{},
+		})
+		request, err := r.updateSpotRequestCondition(instance)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(request).To(HaveField("SpotInstanceRequestId", HaveValue(Equal("sir-1"))))
+		g.Expect(r.providerStatus.Conditions).To(ContainElement(HaveField("Type", spotRequestConditionType)))
+	})
+
+	t.Run("does not describe one-time spot requests", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+		mockAWSClient.EXPECT().DescribeSpotInstanceRequests(gomock.Any(), gomock.Any()).Times(0)
+
+		r := newReconciler(&machineScope{
+			Context:        context.Background(),
+			awsClient:      mockAWSClient,
+			machine:        &machinev1beta1.Machine{},
+			providerStatus: &machinev1beta1.AWSMachineProviderStatus{},
+		})
+		request, err := r.updateSpotRequestCondition(instance)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(request).To(BeNil())
+		g.Expect(r.providerStatus.Conditions).To(BeEmpty())
+	})
+}
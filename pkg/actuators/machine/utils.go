@@ -17,6 +17,7 @@ limitations under the License.
 package machine
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -65,18 +66,18 @@ func getRunningFromInstances(instances []*ec2.Instance) []*ec2.Instance {
 
 // getStoppedInstances returns all stopped instances that have a tag matching our machine name,
 // and cluster ID.
-func getStoppedInstances(machine *machinev1beta1.Machine, client awsclient.Client) ([]*ec2.Instance, error) {
+func getStoppedInstances(ctx context.Context, machine *machinev1beta1.Machine, client awsclient.Client) ([]*ec2.Instance, error) {
 	stoppedInstanceStateFilter := []*string{aws.String(ec2.InstanceStateNameStopped), aws.String(ec2.InstanceStateNameStopping)}
-	return getInstances(machine, client, stoppedInstanceStateFilter)
+	return getInstances(ctx, machine, client, stoppedInstanceStateFilter)
 }
 
 // getExistingInstances returns all instances
-func getExistingInstances(machine *machinev1beta1.Machine, client awsclient.Client) ([]*ec2.Instance, error) {
-	return getInstances(machine, client, existingInstanceStates())
+func getExistingInstances(ctx context.Context, machine *machinev1beta1.Machine, client awsclient.Client) ([]*ec2.Instance, error) {
+	return getInstances(ctx, machine, client, existingInstanceStates())
 }
 
-func getExistingInstanceByID(id string, client awsclient.Client) (*ec2.Instance, error) {
-	return getInstanceByID(id, client, existingInstanceStates())
+func getExistingInstanceByID(ctx context.Context, id string, client awsclient.Client) (*ec2.Instance, error) {
+	return getInstanceByID(ctx, id, client, existingInstanceStates())
 }
 
 func instanceHasAllowedState(instance *ec2.Instance, instanceStateFilter []*string) error {
@@ -107,7 +108,7 @@ func instanceHasAllowedState(instance *ec2.Instance, instanceStateFilter []*stri
 }
 
 // getInstanceByID returns the instance with the given ID if it exists.
-func getInstanceByID(id string, client awsclient.Client, instanceStateFilter []*string) (*ec2.Instance, error) {
+func getInstanceByID(ctx context.Context, id string, client awsclient.Client, instanceStateFilter []*string) (*ec2.Instance, error) {
 	if id == "" {
 		return nil, fmt.Errorf("instance-id not specified")
 	}
@@ -116,7 +117,7 @@ func getInstanceByID(id string, client awsclient.Client, instanceStateFilter []*
 		InstanceIds: aws.StringSlice([]string{id}),
 	}
 
-	result, err := client.DescribeInstances(request)
+	result, err := client.DescribeInstances(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +139,7 @@ func getInstanceByID(id string, client awsclient.Client, instanceStateFilter []*
 
 // correctExistingTags validates Name and clusterID tags are correct on the instance
 // and sets them if they are not.
-func correctExistingTags(machine *machinev1beta1.Machine, instance *ec2.Instance, client awsclient.Client, rawTags []*ec2.Tag) error {
+func correctExistingTags(ctx context.Context, machine *machinev1beta1.Machine, instance *ec2.Instance, client awsclient.Client, rawTags []*ec2.Tag) error {
 	tags := make(map[string]string)
 	for _, tag := range rawTags {
 		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
@@ -197,7 +198,7 @@ func correctExistingTags(machine *machinev1beta1.Machine, instance *ec2.Instance
 		}
 		klog.Infof("updating Tags for machine: %v; instanceID: %v, tags: %+v",
 			machine.Name, *instance.InstanceId, tagsToAdd)
-		_, err := client.CreateTags(input)
+		_, err := client.CreateTags(ctx, input)
 		return err
 	}
 
@@ -206,7 +207,7 @@ func correctExistingTags(machine *machinev1beta1.Machine, instance *ec2.Instance
 
 // getInstances returns all instances that have a tag matching our machine name,
 // and cluster ID.
-func getInstances(machine *machinev1beta1.Machine, client awsclient.Client, instanceStateFilter []*string) ([]*ec2.Instance, error) {
+func getInstances(ctx context.Context, machine *machinev1beta1.Machine, client awsclient.Client, instanceStateFilter []*string) ([]*ec2.Instance, error) {
 	clusterID, ok := getClusterID(machine)
 	if !ok {
 		return []*ec2.Instance{}, fmt.Errorf("unable to get cluster ID for machine: %q", machine.Name)
@@ -224,7 +225,7 @@ func getInstances(machine *machinev1beta1.Machine, client awsclient.Client, inst
 		Filters: requestFilters,
 	}
 
-	result, err := client.DescribeInstances(request)
+	result, err := client.DescribeInstances(ctx, request)
 	if err != nil {
 		return []*ec2.Instance{}, err
 	}
@@ -245,8 +246,16 @@ func getInstances(machine *machinev1beta1.Machine, client awsclient.Client, inst
 	return instances, nil
 }
 
-// terminateInstances terminates all provided instances with a single EC2 request.
-func terminateInstances(client awsclient.Client, instances []*ec2.Instance) ([]*ec2.InstanceStateChange, error) {
+// terminateInstances terminates all provided instances of the machine with a single EC2 request.
+// If they were launched with persistent spot requests, these are cancelled first so that they do not launch
+// replacements. The instances are not terminated while such requests cannot be cancelled, since the replacements
+// would not be owned by any Machine.
+func terminateInstances(ctx context.Context, client awsclient.Client, machine *machinev1beta1.Machine, instances []*ec2.Instance) ([]*ec2.InstanceStateChange, error) {
+	if err := cancelSpotInstanceRequests(ctx, client, instances, hasPersistentSpotRequest(machine)); err != nil {
+		klog.Errorf("Error cancelling spot instance requests, not terminating the instances: %v", err)
+		return nil, err
+	}
+
 	instanceIDs := []*string{}
 	// Cleanup all older instances:
 	for _, instance := range instances {
@@ -260,7 +269,7 @@ func terminateInstances(client awsclient.Client, instances []*ec2.Instance) ([]*
 	terminateInstancesRequest := &ec2.TerminateInstancesInput{
 		InstanceIds: instanceIDs,
 	}
-	output, err := client.TerminateInstances(terminateInstancesRequest)
+	output, err := client.TerminateInstances(ctx, terminateInstancesRequest)
 	if err != nil {
 		klog.Errorf("Error terminating instances: %v", err)
 		return nil, fmt.Errorf("error terminating instances: %v", err)
@@ -0,0 +1,160 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// CapacityReservationAvailableAnnotation is set on MachineSets targeting a capacity reservation
+	// to the number of instances that can still be launched into it.
+	CapacityReservationAvailableAnnotation = "machine.openshift.io/capacity-reservation-available-instances"
+
+	// CapacityReservationTotalAnnotation is set on MachineSets targeting a capacity reservation
+	// to the number of instances it reserves.
+	CapacityReservationTotalAnnotation = "machine.openshift.io/capacity-reservation-total-instances"
+
+	// CapacityReservationEndDateAnnotation is set on MachineSets targeting a capacity reservation
+	// to the RFC 3339 date it ends at, if it ends.
+	CapacityReservationEndDateAnnotation = "machine.openshift.io/capacity-reservation-end-date"
+
+	// CapacityReservationAvailableCondition reports whether Machines of the MachineSet can be launched
+	// into the capacity reservation they target.
+	CapacityReservationAvailableCondition machinev1beta1.ConditionType = "CapacityReservationAvailable"
+
+	capacityReservationNotFoundReason             = "CapacityReservationNotFound"
+	capacityReservationNotActiveReason            = "CapacityReservationNotActive"
+	capacityReservationInstanceTypeMismatchReason = "CapacityReservationInstanceTypeMismatch"
+	capacityReservationExhaustedReason            = "CapacityReservationExhausted"
+	capacityReservationExpiringReason             = "CapacityReservationExpiring"
+
+	// capacityReservationRefreshInterval is the interval at which the capacity of reservations is refreshed,
+	// as it changes when instances are launched into them or terminated.
+	capacityReservationRefreshInterval = 5 * time.Minute
+
+	// capacityReservationExpiryWarningPeriod is how long before the end of a reservation a warning is emitted.
+	capacityReservationExpiryWarningPeriod = 24 * time.Hour
+
+	// capacityReservationNotFoundErrorCode is returned by DescribeCapacityReservations for unknown reservations.
+	capacityReservationNotFoundErrorCode = "InvalidCapacityReservationId.NotFound"
+)
+
+// setCapacityReservationStatus annotates the MachineSet with the capacity of the reservation its Machines target,
+// and sets the CapacityReservationAvailable condition. MachineSets not targeting a reservation have the annotations
+// and the condition removed. The MachineSet is requeued so that the capacity follows the Machines launched.
+func (r *Reconciler) setCapacityReservationStatus(ctx context.Context, awsClient awsclient.Client, machineSet *machinev1beta1.MachineSet, providerConfig *machinev1beta1.AWSMachineProviderConfig, now time.Time) (ctrl.Result, error) {
+	if machineSet.Annotations == nil {
+		machineSet.Annotations = make(map[string]string)
+	}
+
+	reservationID := providerConfig.CapacityReservationID
+	if reservationID == "" {
+		delete(machineSet.Annotations, CapacityReservationAvailableAnnotation)
+		delete(machineSet.Annotations, CapacityReservationTotalAnnotation)
+		delete(machineSet.Annotations, CapacityReservationEndDateAnnotation)
+		removeCondition(machineSet, CapacityReservationAvailableCondition)
+		return ctrl.Result{}, nil
+	}
+
+	reservation, err := getCapacityReservation(ctx, awsClient, reservationID)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if reservation == nil {
+		delete(machineSet.Annotations, CapacityReservationAvailableAnnotation)
+		delete(machineSet.Annotations, CapacityReservationTotalAnnotation)
+		delete(machineSet.Annotations, CapacityReservationEndDateAnnotation)
+		r.setCapacityReservationCondition(machineSet, conditions.FalseCondition(CapacityReservationAvailableCondition, capacityReservationNotFoundReason,
+			machinev1beta1.ConditionSeverityError, "capacity reservation %s not found", reservationID))
+		return ctrl.Result{RequeueAfter: capacityReservationRefreshInterval}, nil
+	}
+
+	machineSet.Annotations[CapacityReservationAvailableAnnotation] = strconv.FormatInt(aws.Int64Value(reservation.AvailableInstanceCount), 10)
+	machineSet.Annotations[CapacityReservationTotalAnnotation] = strconv.FormatInt(aws.Int64Value(reservation.TotalInstanceCount), 10)
+	if reservation.EndDate != nil {
+		machineSet.Annotations[CapacityReservationEndDateAnnotation] = reservation.EndDate.UTC().Format(time.RFC3339)
+	} else {
+		delete(machineSet.Annotations, CapacityReservationEndDateAnnotation)
+	}
+
+	r.setCapacityReservationCondition(machineSet, capacityReservationCondition(reservation, providerConfig.InstanceType, now))
+	return ctrl.Result{RequeueAfter: capacityReservationRefreshInterval}, nil
+}
+
+// capacityReservationCondition returns the CapacityReservationAvailable condition for the reservation.
+// The condition is true while instances can be launched into it, with the expiring reason when it ends soon.
+func capacityReservationCondition(reservation *ec2.CapacityReservation, instanceType string, now time.Time) *machinev1beta1.Condition {
+	reservationID := aws.StringValue(reservation.CapacityReservationId)
+
+	if state := aws.StringValue(reservation.State); state != ec2.CapacityReservationStateActive {
+		return conditions.FalseCondition(CapacityReservationAvailableCondition, capacityReservationNotActiveReason, machinev1beta1.ConditionSeverityError,
+			"capacity reservation %s is %s", reservationID, state)
+	}
+	if reservedType := aws.StringValue(reservation.InstanceType); reservedType != "" && reservedType != instanceType {
+		return conditions.FalseCondition(CapacityReservationAvailableCondition, capacityReservationInstanceTypeMismatchReason, machinev1beta1.ConditionSeverityError,
+			"capacity reservation %s reserves instance type %s, not %s", reservationID, reservedType, instanceType)
+	}
+	if aws.Int64Value(reservation.AvailableInstanceCount) <= 0 {
+		return conditions.FalseCondition(CapacityReservationAvailableCondition, capacityReservationExhaustedReason, machinev1beta1.ConditionSeverityWarning,
+			"all %d instances of capacity reservation %s are in use", aws.Int64Value(reservation.TotalInstanceCount), reservationID)
+	}
+
+	if reservation.EndDate != nil && reservation.EndDate.Sub(now) < capacityReservationExpiryWarningPeriod {
+		return conditions.TrueConditionWithReason(CapacityReservationAvailableCondition, capacityReservationExpiringReason,
+			"capacity reservation %s ends at %s, its instances will be terminated", reservationID, reservation.EndDate.UTC().Format(time.RFC3339))
+	}
+	return conditions.TrueCondition(CapacityReservationAvailableCondition)
+}
+
+// setCapacityReservationCondition sets the condition on the MachineSet, and emits an event when its reason changes
+// to one needing attention: the reservation cannot be launched into, or it is about to end.
+func (r *Reconciler) setCapacityReservationCondition(machineSet *machinev1beta1.MachineSet, condition *machinev1beta1.Condition) {
+	previous := conditions.Get(machineSet, CapacityReservationAvailableCondition)
+	if condition.Reason != "" && (previous == nil || previous.Status != condition.Status || previous.Reason != condition.Reason) {
+		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, condition.Reason, "%s", condition.Message)
+	}
+	conditions.Set(machineSet, condition)
+}
+
+// getCapacityReservation returns the capacity reservation, or nil if it does not exist.
+func getCapacityReservation(ctx context.Context, awsClient awsclient.Client, reservationID string) (*ec2.CapacityReservation, error) {
+	klog.V(3).Infof("Describing capacity reservation %s", reservationID)
+
+	output, err := awsClient.DescribeCapacityReservations(ctx, &ec2.DescribeCapacityReservationsInput{
+		CapacityReservationIds: []*string{aws.String(reservationID)},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == capacityReservationNotFoundErrorCode {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error describing capacity reservation %s: %w", reservationID, err)
+	}
+	if len(output.CapacityReservations) == 0 {
+		return nil, nil
+	}
+	return output.CapacityReservations[0], nil
+}
+
+// removeCondition removes the condition of the given type from the MachineSet, if it is set.
+func removeCondition(machineSet *machinev1beta1.MachineSet, conditionType machinev1beta1.ConditionType) {
+	remaining := []machinev1beta1.Condition{}
+	for _, condition := range machineSet.Status.Conditions {
+		if condition.Type != conditionType {
+			remaining = append(remaining, condition)
+		}
+	}
+	if len(remaining) != len(machineSet.Status.Conditions) {
+		machineSet.Status.Conditions = remaining
+	}
+}
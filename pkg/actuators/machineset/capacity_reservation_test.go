@@ -0,0 +1,146 @@
+package machineset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCapacityReservationCondition(t *testing.T) {
+	now := time.Now()
+	reservation := func(modify func(*ec2.CapacityReservation)) *ec2.CapacityReservation {
+		r := &ec2.CapacityReservation{
+			CapacityReservationId:  aws.String("cr-0123456789abcdef0"),
+			InstanceType:           aws.String("p5.48xlarge"),
+			State:                  aws.String(ec2.CapacityReservationStateActive),
+			AvailableInstanceCount: aws.Int64(2),
+			TotalInstanceCount:     aws.Int64(4),
+		}
+		if modify != nil {
+			modify(r)
+		}
+		return r
+	}
+
+	testCases := []struct {
+		name           string
+		reservation    *ec2.CapacityReservation
+		expectedStatus corev1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "with available instances",
+			reservation:    reservation(nil),
+			expectedStatus: corev1.ConditionTrue,
+		},
+		{
+			name:           "ending after the warning period",
+			reservation:    reservation(func(r *ec2.CapacityReservation) { r.EndDate = aws.Time(now.Add(72 * time.Hour)) }),
+			expectedStatus: corev1.ConditionTrue,
+		},
+		{
+			name:           "ending within the warning period",
+			reservation:    reservation(func(r *ec2.CapacityReservation) { r.EndDate = aws.Time(now.Add(time.Hour)) }),
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: capacityReservationExpiringReason,
+		},
+		{
+			name:           "exhausted",
+			reservation:    reservation(func(r *ec2.CapacityReservation) { r.AvailableInstanceCount = aws.Int64(0) }),
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: capacityReservationExhaustedReason,
+		},
+		{
+			name:           "scheduled capacity block",
+			reservation:    reservation(func(r *ec2.CapacityReservation) { r.State = aws.String(ec2.CapacityReservationStateScheduled) }),
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: capacityReservationNotActiveReason,
+		},
+		{
+			name:           "for another instance type",
+			reservation:    reservation(func(r *ec2.CapacityReservation) { r.InstanceType = aws.String("p4d.24xlarge") }),
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: capacityReservationInstanceTypeMismatchReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			condition := capacityReservationCondition(tc.reservation, "p5.48xlarge", now)
+			g.Expect(condition.Status).To(Equal(tc.expectedStatus))
+			g.Expect(condition.Reason).To(Equal(tc.expectedReason))
+		})
+	}
+}
+
+func TestSetCapacityReservationStatus(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	recorder := record.NewFakeRecorder(10)
+	r := Reconciler{recorder: recorder}
+	now := time.Now()
+	endDate := now.Add(2 * time.Hour).Truncate(time.Second)
+
+	providerConfig := &machinev1beta1.AWSMachineProviderConfig{
+		InstanceType:          "p5.48xlarge",
+		CapacityReservationID: "cr-0123456789abcdef0",
+	}
+	machineSet := &machinev1beta1.MachineSet{}
+
+	mockAWSClient.EXPECT().DescribeCapacityReservations(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeCapacityReservationsInput) (*ec2.DescribeCapacityReservationsOutput, error) {
+			g.Expect(aws.StringValueSlice(input.CapacityReservationIds)).To(ConsistOf("cr-0123456789abcdef0"))
+			return &ec2.DescribeCapacityReservationsOutput{
+				CapacityReservations: []*ec2.CapacityReservation{{
+					CapacityReservationId:  aws.String("cr-0123456789abcdef0"),
+					InstanceType:           aws.String("p5.48xlarge"),
+					ReservationType:        aws.String(ec2.CapacityReservationTypeCapacityBlock),
+					State:                  aws.String(ec2.CapacityReservationStateActive),
+					AvailableInstanceCount: aws.Int64(1),
+					TotalInstanceCount:     aws.Int64(2),
+					EndDate:                aws.Time(endDate),
+				}},
+			}, nil
+		}).Times(2)
+
+	for i := 0; i < 2; i++ {
+		result, err := r.setCapacityReservationStatus(context.TODO(), mockAWSClient, machineSet, providerConfig, now)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(capacityReservationRefreshInterval))
+	}
+	g.Expect(machineSet.Annotations).To(Equal(map[string]string{
+		CapacityReservationAvailableAnnotation: "1",
+		CapacityReservationTotalAnnotation:     "2",
+		CapacityReservationEndDateAnnotation:   endDate.UTC().Format(time.RFC3339),
+	}))
+	g.Expect(conditions.IsTrue(machineSet, CapacityReservationAvailableCondition)).To(BeTrue())
+	g.Expect(recorder.Events).To(HaveLen(1), "the expiry warning must only be emitted once")
+	g.Expect(<-recorder.Events).To(ContainSubstring(capacityReservationExpiringReason))
+
+	mockAWSClient.EXPECT().DescribeCapacityReservations(gomock.Any(), gomock.Any()).Return(nil,
+		awserr.New(capacityReservationNotFoundErrorCode, "The capacity reservation does not exist", nil))
+	_, err := r.setCapacityReservationStatus(context.TODO(), mockAWSClient, machineSet, providerConfig, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(machineSet.Annotations).To(BeEmpty())
+	g.Expect(conditions.Get(machineSet, CapacityReservationAvailableCondition).Reason).To(Equal(capacityReservationNotFoundReason))
+
+	providerConfig.CapacityReservationID = ""
+	result, err := r.setCapacityReservationStatus(context.TODO(), mockAWSClient, machineSet, providerConfig, now)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(conditions.Get(machineSet, CapacityReservationAvailableCondition)).To(BeNil())
+}
@@ -2,19 +2,22 @@ package machineset
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	openshiftfeatures "github.com/openshift/api/features"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/machine-api-operator/pkg/controller/machine"
 	mapierrors "github.com/openshift/machine-api-operator/pkg/controller/machine"
-	"github.com/openshift/machine-api-operator/pkg/util"
 	"github.com/openshift/machine-api-operator/pkg/util/conditions"
 	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
 	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -33,6 +36,23 @@ const (
 	memoryKey = "machine.openshift.io/memoryMb"
 	gpuKey    = "machine.openshift.io/GPU"
 	labelsKey = "capacity.cluster-autoscaler.kubernetes.io/labels"
+
+	// These give the autoscaler a more accurate picture of the nodes when scaling from zero.
+	// https://github.com/kubernetes/autoscaler/tree/master/cluster-autoscaler/cloudprovider/clusterapi#scale-from-zero-support
+	ephemeralDiskKey   = "capacity.cluster-autoscaler.kubernetes.io/ephemeral-disk"
+	maxPodsKey         = "capacity.cluster-autoscaler.kubernetes.io/maxPods"
+	gpuTypeKey         = "capacity.cluster-autoscaler.kubernetes.io/gpu-type"
+	taintsKey          = "capacity.cluster-autoscaler.kubernetes.io/taints"
+	gpuManufacturerKey = "machine.openshift.io/gpuManufacturer"
+	gpuModelKey        = "machine.openshift.io/gpuModel"
+
+	// SpotInterruptionRateAnnotation is set on spot MachineSets to the number of spot interruptions of their
+	// Machines over the rolling window of the interruption history, divided by their replicas.
+	SpotInterruptionRateAnnotation = "machine.openshift.io/spot-interruption-rate"
+
+	// spotInterruptionRateRefreshes is the number of times per window the rate is refreshed
+	// while interruptions are within the window, so that it decays as they leave it.
+	spotInterruptionRateRefreshes = 24
 )
 
 // Reconciler reconciles machineSets.
@@ -43,8 +63,15 @@ type Reconciler struct {
 	RegionCache         awsclient.RegionCache
 	ConfigManagedClient client.Client
 	InstanceTypesCache  InstanceTypesCache
+	SpotInterruptions   utils.SpotInterruptionHistory
+	Prices              PriceCache
+	Quotas              ServiceQuotaCache
 	Gate                featuregate.MutableFeatureGate
 
+	// APIReader reads the ConfigMap overriding quotas, uncached so that the manager does not watch
+	// ConfigMaps cluster-wide. It only needs to get ConfigMaps in the namespace of the MachineSets.
+	APIReader client.Reader
+
 	recorder record.EventRecorder
 	scheme   *runtime.Scheme
 }
@@ -108,19 +135,33 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
-	originalMachineSetToPatch := client.MergeFrom(machineSet.DeepCopy())
+	originalMachineSet := machineSet.DeepCopy()
+	originalMachineSetToPatch := client.MergeFrom(originalMachineSet)
 
-	result, err := r.reconcile(machineSet)
+	result, err := r.reconcile(ctx, machineSet)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile MachineSet")
 		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "ReconcileError", "%v", err)
 		// we don't return here so we want to attempt to patch the machine regardless of an error.
 	}
 
+	// Patching the MachineSet overwrites it with the response, which does not include status changes.
+	status := machineSet.Status.DeepCopy()
 	if err := r.Client.Patch(ctx, machineSet, originalMachineSetToPatch); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to patch machineSet: %v", err)
 	}
 
+	if !equality.Semantic.DeepEqual(originalMachineSet.Status.Conditions, status.Conditions) {
+		// The patched MachineSet has the latest status. Only the conditions owned by this controller are applied
+		// onto it, so that conditions set concurrently by other controllers, e.g. Paused, are not reverted.
+		// The merge patch replaces all conditions, the optimistic lock makes it fail if they changed meanwhile.
+		latestMachineSetToPatch := client.MergeFromWithOptions(machineSet.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		applyOwnedConditions(machineSet, status)
+		if err := r.Client.Status().Patch(ctx, machineSet, latestMachineSetToPatch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to patch machineSet status: %v", err)
+		}
+	}
+
 	if isInvalidConfigurationError(err) {
 		// For situations where requeuing won't help we don't return error.
 		// https://github.com/kubernetes-sigs/controller-runtime/issues/617
@@ -129,6 +170,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return result, err
 }
 
+// ownedConditions are the conditions of MachineSets set by this controller.
+var ownedConditions = []machinev1beta1.ConditionType{InstanceTypeValidCondition, QuotaExceededCondition, CapacityReservationAvailableCondition}
+
+// applyOwnedConditions sets the conditions owned by this controller from status onto the MachineSet, and removes
+// those which are not in status. The conditions of other controllers are left untouched.
+func applyOwnedConditions(machineSet *machinev1beta1.MachineSet, status *machinev1beta1.MachineSetStatus) {
+	for _, conditionType := range ownedConditions {
+		if i := slices.IndexFunc(status.Conditions, func(c machinev1beta1.Condition) bool { return c.Type == conditionType }); i >= 0 {
+			conditions.Set(machineSet, status.Conditions[i].DeepCopy())
+			continue
+		}
+		removeCondition(machineSet, conditionType)
+	}
+}
+
 func isInvalidConfigurationError(err error) bool {
 	switch t := err.(type) {
 	case *mapierrors.MachineError:
@@ -139,7 +195,7 @@ func isInvalidConfigurationError(err error) bool {
 	return false
 }
 
-func (r *Reconciler) reconcile(machineSet *machinev1beta1.MachineSet) (ctrl.Result, error) {
+func (r *Reconciler) reconcile(ctx context.Context, machineSet *machinev1beta1.MachineSet) (ctrl.Result, error) {
 	klog.V(3).Infof("%v: Reconciling MachineSet", machineSet.Name)
 	providerConfig, err := utils.ProviderSpecFromRawExtension(machineSet.Spec.Template.Spec.ProviderSpec.Value)
 	if err != nil {
@@ -150,33 +206,99 @@ func (r *Reconciler) reconcile(machineSet *machinev1beta1.MachineSet) (ctrl.Resu
 		return ctrl.Result{}, mapierrors.InvalidMachineConfiguration("nil credentialsSecret for machineSet %s", machineSet.Name)
 	}
 
-	awsClient, err := r.AwsClientBuilder(r.Client, providerConfig.CredentialsSecret.Name, machineSet.Namespace, providerConfig.Placement.Region, r.ConfigManagedClient, r.RegionCache)
+	result := ctrl.Result{}
+	if r.SpotInterruptions != nil && isSpot(providerConfig) {
+		result = r.setSpotInterruptionRate(machineSet, time.Now())
+	}
+
+	awsClient, err := r.AwsClientBuilder(ctx, r.Client, providerConfig.CredentialsSecret.Name, machineSet.Namespace, providerConfig.Placement.Region, r.ConfigManagedClient, r.RegionCache)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error creating aws client: %w", err)
 	}
 
-	instanceType, err := r.InstanceTypesCache.GetInstanceType(awsClient, providerConfig.Placement.Region, providerConfig.InstanceType)
-	if err != nil {
+	resolved, err := r.resolveInstanceType(ctx, awsClient, machineSet, providerConfig)
+	if err != nil || !resolved {
+		return result, err
+	}
+
+	instanceType, err := r.InstanceTypesCache.GetInstanceType(ctx, awsClient, providerConfig.Placement.Region, providerConfig.InstanceType)
+	if errors.Is(err, errInstanceTypeNotFound) {
 		klog.Errorf("Unable to set scale from zero annotations: unknown instance type %s: %v", providerConfig.InstanceType, err)
 		klog.Errorf("Autoscaling from zero will not work. To fix this, manually populate machine annotations for your instance type: %v", []string{cpuKey, memoryKey, gpuKey})
 
+		// The annotations of a previous instance type would make the autoscaler simulate nodes of the wrong shape.
+		removeStaleScaleFromZeroAnnotations(machineSet, providerConfig.InstanceType)
+
 		// Returning no error to prevent further reconciliation, as user intervention is now required but emit an informational event
 		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "FailedUpdate", "Failed to set autoscaling from zero annotations, instance type unknown")
-		return ctrl.Result{}, nil
+		conditions.Set(machineSet, conditions.FalseCondition(InstanceTypeValidCondition, instanceTypeUnknownReason, machinev1beta1.ConditionSeverityError,
+			"instance type %q is unknown", providerConfig.InstanceType))
+		return result, nil
+	} else if err != nil {
+		// The instance types of the region could not be fetched, e.g. because of throttling or an EC2 API outage.
+		// Returning the error requeues the MachineSet with backoff, the annotations of the instance type are kept meanwhile.
+		// The error is only reported in the event, so that the condition does not change on every attempt.
+		conditions.Set(machineSet, conditions.UnknownCondition(InstanceTypeValidCondition, instanceTypesUnavailableReason,
+			"instance types of region %s could not be fetched", providerConfig.Placement.Region))
+		return result, fmt.Errorf("error getting instance type %q: %w", providerConfig.InstanceType, err)
+	}
+
+	setScaleFromZeroAnnotations(machineSet, providerConfig, instanceType)
+
+	condition, zone, err := r.validateInstanceType(ctx, awsClient, providerConfig, instanceType)
+	if err != nil {
+		return result, fmt.Errorf("error validating instance type: %w", err)
+	}
+	r.setInstanceTypeValidCondition(machineSet, condition)
+
+	if r.Prices != nil {
+		priceResult, err := r.setPriceAnnotations(ctx, awsClient, machineSet, providerConfig, zone)
+		if err != nil {
+			return result, fmt.Errorf("error setting price annotations: %w", err)
+		}
+		result = earliestRequeue(result, priceResult)
+	}
+
+	if r.Quotas != nil {
+		quotaResult, err := r.setQuotaExceededCondition(ctx, awsClient, machineSet, providerConfig, instanceType)
+		if err != nil {
+			return result, fmt.Errorf("error checking vCPU quota: %w", err)
+		}
+		result = earliestRequeue(result, quotaResult)
+	}
+
+	reservationResult, err := r.setCapacityReservationStatus(ctx, awsClient, machineSet, providerConfig, time.Now())
+	if err != nil {
+		return result, fmt.Errorf("error setting capacity reservation status: %w", err)
+	}
+	return earliestRequeue(result, reservationResult), nil
+}
+
+// setSpotInterruptionRate annotates the MachineSet with the rate of spot interruptions of its Machines.
+// While interruptions are within the window, the MachineSet is requeued so that the rate decays.
+func (r *Reconciler) setSpotInterruptionRate(machineSet *machinev1beta1.MachineSet, now time.Time) ctrl.Result {
+	interruptions := r.SpotInterruptions.Count(machineSet.Namespace, machineSet.Name, now)
+
+	replicas := int32(1)
+	if machineSet.Spec.Replicas != nil && *machineSet.Spec.Replicas > 1 {
+		replicas = *machineSet.Spec.Replicas
 	}
 
 	if machineSet.Annotations == nil {
 		machineSet.Annotations = make(map[string]string)
 	}
+	machineSet.Annotations[SpotInterruptionRateAnnotation] = strconv.FormatFloat(float64(interruptions)/float64(replicas), 'f', 2, 64)
 
-	// TODO: get annotations keys from machine API
-	machineSet.Annotations[cpuKey] = strconv.FormatInt(instanceType.VCPU, 10)
-	machineSet.Annotations[memoryKey] = strconv.FormatInt(instanceType.MemoryMb, 10)
-	machineSet.Annotations[gpuKey] = strconv.FormatInt(instanceType.GPU, 10)
-	// We guarantee that any existing labels provided via the capacity annotations are preserved.
-	// See https://github.com/kubernetes/autoscaler/pull/5382 and https://github.com/kubernetes/autoscaler/pull/5697
-	machineSet.Annotations[labelsKey] = util.MergeCommaSeparatedKeyValuePairs(
-		fmt.Sprintf("kubernetes.io/arch=%s", instanceType.CPUArchitecture),
-		machineSet.Annotations[labelsKey])
-	return ctrl.Result{}, nil
+	if interruptions == 0 {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: r.SpotInterruptions.Window() / spotInterruptionRateRefreshes}
+}
+
+// earliestRequeue returns the result requeuing the earliest, results without RequeueAfter never requeue.
+func earliestRequeue(a, b ctrl.Result) ctrl.Result {
+	if a.RequeueAfter == 0 || (b.RequeueAfter > 0 && b.RequeueAfter < a.RequeueAfter) {
+		return b
+	}
+	return a
 }
@@ -17,9 +17,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -27,8 +32,12 @@ import (
 	openshiftfeatures "github.com/openshift/api/features"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/library-go/pkg/features"
+	mapimachine "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
 	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
 	fakeawsclient "github.com/openshift/machine-api-provider-aws/pkg/client/fake"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
 	"github.com/openshift/machine-api-provider-aws/pkg/version"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,7 +46,9 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/component-base/featuregate"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -51,7 +62,7 @@ var _ = Describe("MachineSetReconciler", func() {
 	var namespace *corev1.Namespace
 	fakeClient, err := fakeawsclient.NewClient(nil, "", "", "")
 	Expect(err).ToNot(HaveOccurred())
-	awsClientBuilder := func(client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
+	awsClientBuilder := func(ctx context.Context, client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
 		return fakeClient, nil
 	}
 
@@ -71,6 +82,7 @@ var _ = Describe("MachineSetReconciler", func() {
 			AwsClientBuilder:   awsClientBuilder,
 			InstanceTypesCache: NewInstanceTypesCache(),
 			Gate:               gate,
+			APIReader:          mgr.GetAPIReader(),
 		}
 		Expect(r.SetupWithManager(mgr, controller.Options{
 			SkipNameValidation: ptr.To(true),
@@ -148,10 +160,11 @@ var _ = Describe("MachineSetReconciler", func() {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "8",
-				memoryKey: "16384",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:                  "8",
+				memoryKey:               "16384",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge"),
 			},
 			expectedEvents: []string{},
 		}),
@@ -160,10 +173,18 @@ var _ = Describe("MachineSetReconciler", func() {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "64",
-				memoryKey: "749568",
-				gpuKey:    "16",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:             "64",
+				memoryKey:          "749568",
+				gpuKey:             "16",
+				gpuTypeKey:         "nvidia.com/gpu",
+				gpuManufacturerKey: "NVIDIA",
+				gpuModelKey:        "K80",
+				labelsKey:          "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=p2.16xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{
+					gpuTypeKey:         "nvidia.com/gpu",
+					gpuManufacturerKey: "NVIDIA",
+					gpuModelKey:        "K80",
+				}, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=p2.16xlarge"),
 			},
 			expectedEvents: []string{},
 		}),
@@ -175,12 +196,13 @@ var _ = Describe("MachineSetReconciler", func() {
 				"annother": "existingAnnotation",
 			},
 			expectedAnnotations: map[string]string{
-				"existing": "annotation",
-				"annother": "existingAnnotation",
-				cpuKey:     "8",
-				memoryKey:  "16384",
-				gpuKey:     "0",
-				labelsKey:  "kubernetes.io/arch=amd64",
+				"existing":              "annotation",
+				"annother":              "existingAnnotation",
+				cpuKey:                  "8",
+				memoryKey:               "16384",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge"),
 			},
 			expectedEvents: []string{},
 		}),
@@ -189,10 +211,11 @@ var _ = Describe("MachineSetReconciler", func() {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "16",
-				memoryKey: "65536",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=arm64",
+				cpuKey:                  "16",
+				memoryKey:               "65536",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=arm64,node.kubernetes.io/instance-type=m6g.4xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=arm64,node.kubernetes.io/instance-type=m6g.4xlarge"),
 			},
 			expectedEvents: []string{},
 		}),
@@ -201,10 +224,11 @@ var _ = Describe("MachineSetReconciler", func() {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "32",
-				memoryKey: "131072",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:                  "32",
+				memoryKey:               "131072",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6i.8xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6i.8xlarge"),
 			},
 			expectedEvents: []string{},
 		}),
@@ -213,10 +237,11 @@ var _ = Describe("MachineSetReconciler", func() {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "32",
-				memoryKey: "131072",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:                  "32",
+				memoryKey:               "131072",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6h.8xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6h.8xlarge"),
 			},
 			expectedEvents: []string{},
 		}),
@@ -289,10 +314,11 @@ func TestReconcile(t *testing.T) {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "8",
-				memoryKey: "16384",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:                  "8",
+				memoryKey:               "16384",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge"),
 			},
 			expectErr: false,
 		},
@@ -302,10 +328,18 @@ func TestReconcile(t *testing.T) {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "64",
-				memoryKey: "749568",
-				gpuKey:    "16",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:             "64",
+				memoryKey:          "749568",
+				gpuKey:             "16",
+				gpuTypeKey:         "nvidia.com/gpu",
+				gpuManufacturerKey: "NVIDIA",
+				gpuModelKey:        "K80",
+				labelsKey:          "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=p2.16xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{
+					gpuTypeKey:         "nvidia.com/gpu",
+					gpuManufacturerKey: "NVIDIA",
+					gpuModelKey:        "K80",
+				}, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=p2.16xlarge"),
 			},
 			expectErr: false,
 		},
@@ -318,12 +352,13 @@ func TestReconcile(t *testing.T) {
 			},
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			expectedAnnotations: map[string]string{
-				"existing": "annotation",
-				"annother": "existingAnnotation",
-				cpuKey:     "8",
-				memoryKey:  "16384",
-				gpuKey:     "0",
-				labelsKey:  "kubernetes.io/arch=amd64",
+				"existing":              "annotation",
+				"annother":              "existingAnnotation",
+				cpuKey:                  "8",
+				memoryKey:               "16384",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge"),
 			},
 			expectErr: false,
 		},
@@ -342,16 +377,58 @@ func TestReconcile(t *testing.T) {
 			// Expect no error and only log entry in such case as we don't update instance types dynamically
 			expectErr: false,
 		},
+		{
+			name:         "with an invalid instanceType replacing a known one",
+			instanceType: "invalid",
+			existingAnnotations: map[string]string{
+				"existing": "annotation",
+				cpuKey:     "8",
+				memoryKey:  "16384",
+				gpuKey:     "0",
+				maxPodsKey: "58",
+				taintsKey:  "dedicated=infra:NoSchedule",
+				labelsKey:  "custom=label,kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge,topology.kubernetes.io/region=us-east-1",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{maxPodsKey: "58"},
+					"kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge,topology.kubernetes.io/region=us-east-1"),
+			},
+			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
+			expectedAnnotations: map[string]string{
+				"existing":              "annotation",
+				taintsKey:               "dedicated=infra:NoSchedule",
+				labelsKey:               "custom=label,topology.kubernetes.io/region=us-east-1",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "topology.kubernetes.io/region=us-east-1"),
+			},
+			expectErr: false,
+		},
+		{
+			name:         "with an invalid instanceType and manually populated annotations",
+			instanceType: "invalid",
+			existingAnnotations: map[string]string{
+				cpuKey:    "4",
+				memoryKey: "8192",
+				gpuKey:    "0",
+				labelsKey: "node.kubernetes.io/instance-type=invalid",
+			},
+			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
+			expectedAnnotations: map[string]string{
+				cpuKey:    "4",
+				memoryKey: "8192",
+				gpuKey:    "0",
+				labelsKey: "node.kubernetes.io/instance-type=invalid",
+			},
+			expectErr: false,
+		},
 		{
 			name:                   "with a m6g.4xlarge (aarch64)",
 			instanceType:           "m6g.4xlarge",
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "16",
-				memoryKey: "65536",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=arm64",
+				cpuKey:                  "16",
+				memoryKey:               "65536",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=arm64,node.kubernetes.io/instance-type=m6g.4xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=arm64,node.kubernetes.io/instance-type=m6g.4xlarge"),
 			},
 			expectErr: false,
 		},
@@ -361,10 +438,11 @@ func TestReconcile(t *testing.T) {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "32",
-				memoryKey: "131072",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:                  "32",
+				memoryKey:               "131072",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6i.8xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6i.8xlarge"),
 			},
 			expectErr: false,
 		},
@@ -374,10 +452,11 @@ func TestReconcile(t *testing.T) {
 			statusAuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI,
 			existingAnnotations:    make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "32",
-				memoryKey: "131072",
-				gpuKey:    "0",
-				labelsKey: "kubernetes.io/arch=amd64",
+				cpuKey:                  "32",
+				memoryKey:               "131072",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6h.8xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m6h.8xlarge"),
 			},
 			expectErr: false,
 		},
@@ -392,7 +471,7 @@ func TestReconcile(t *testing.T) {
 
 			fakeClient, err := fakeawsclient.NewClient(nil, "", "", "")
 			Expect(err).ToNot(HaveOccurred())
-			awsClientBuilder := func(client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
+			awsClientBuilder := func(ctx context.Context, client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
 				return fakeClient, nil
 			}
 
@@ -402,13 +481,51 @@ func TestReconcile(t *testing.T) {
 				InstanceTypesCache: NewInstanceTypesCache(),
 			}
 
-			_, err = r.reconcile(machineSet)
+			_, err = r.reconcile(context.TODO(), machineSet)
 			g.Expect(err != nil).To(Equal(tc.expectErr))
 			g.Expect(machineSet.Annotations).To(Equal(tc.expectedAnnotations))
 		})
 	}
 }
 
+func TestReconcileInstanceTypesUnavailable(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().DescribeInstanceTypes(gomock.Any(), gomock.Any()).Return(nil, awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil))
+
+	existingAnnotations := map[string]string{
+		cpuKey:    "8",
+		memoryKey: "16384",
+		gpuKey:    "0",
+		labelsKey: "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=a1.2xlarge",
+	}
+	machineSet, err := newTestMachineSet("default", "m5.large", existingAnnotations, machinev1beta1.MachineAuthorityMachineAPI)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	r := Reconciler{
+		recorder: record.NewFakeRecorder(1),
+		AwsClientBuilder: func(ctx context.Context, client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
+			return mockAWSClient, nil
+		},
+		InstanceTypesCache: NewInstanceTypesCache(),
+	}
+
+	_, err = r.reconcile(context.TODO(), machineSet)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(isInvalidConfigurationError(err)).To(BeFalse())
+
+	// The annotations are kept until the instance type is known to be unknown.
+	g.Expect(machineSet.Annotations).To(Equal(existingAnnotations))
+
+	condition := conditions.Get(machineSet, InstanceTypeValidCondition)
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionUnknown))
+	g.Expect(condition.Reason).To(Equal(instanceTypesUnavailableReason))
+	// The message does not contain the error, which changes on every attempt.
+	g.Expect(condition.Message).ToNot(ContainSubstring("Request limit exceeded"))
+}
+
 func TestNormalizeArchitecture(t *testing.T) {
 	testCases := []struct {
 		architecture string
@@ -506,3 +623,968 @@ func newDefaultMutableFeatureGate() (featuregate.MutableFeatureGate, error) {
 
 	return defaultMutableGate, nil
 }
+
+func TestSetSpotInterruptionRate(t *testing.T) {
+	now := time.Now()
+	history := utils.NewSpotInterruptionHistory(24 * time.Hour)
+	history.Record(utils.SpotInterruption{InstanceID: "i-1", Namespace: "default", MachineSet: "spot", Time: now.Add(-time.Hour)})
+	history.Record(utils.SpotInterruption{InstanceID: "i-2", Namespace: "default", MachineSet: "spot", Time: now.Add(-2 * time.Hour)})
+	history.Record(utils.SpotInterruption{InstanceID: "i-3", Namespace: "default", MachineSet: "spot", Time: now.Add(-25 * time.Hour)})
+	history.Record(utils.SpotInterruption{InstanceID: "i-4", Namespace: "default", MachineSet: "other", Time: now.Add(-time.Hour)})
+
+	testCases := []struct {
+		name           string
+		machineSet     string
+		replicas       *int32
+		expectedRate   string
+		expectedResult ctrl.Result
+	}{
+		{
+			name:           "with interruptions within the window",
+			machineSet:     "spot",
+			replicas:       ptr.To[int32](4),
+			expectedRate:   "0.50",
+			expectedResult: ctrl.Result{RequeueAfter: time.Hour},
+		},
+		{
+			name:           "with no replicas",
+			machineSet:     "spot",
+			replicas:       ptr.To[int32](0),
+			expectedRate:   "2.00",
+			expectedResult: ctrl.Result{RequeueAfter: time.Hour},
+		},
+		{
+			name:           "with no interruptions",
+			machineSet:     "stable",
+			replicas:       ptr.To[int32](3),
+			expectedRate:   "0.00",
+			expectedResult: ctrl.Result{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			g := NewWithT(tt)
+
+			machineSet := &machinev1beta1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.machineSet, Namespace: "default"},
+				Spec:       machinev1beta1.MachineSetSpec{Replicas: tc.replicas},
+			}
+			r := Reconciler{SpotInterruptions: history}
+
+			g.Expect(r.setSpotInterruptionRate(machineSet, now)).To(Equal(tc.expectedResult))
+			g.Expect(machineSet.Annotations).To(HaveKeyWithValue(SpotInterruptionRateAnnotation, tc.expectedRate))
+		})
+	}
+}
+
+func TestSetScaleFromZeroAnnotations(t *testing.T) {
+	testCases := []struct {
+		name                string
+		providerConfig      *machinev1beta1.AWSMachineProviderConfig
+		taints              []corev1.Taint
+		instanceType        InstanceType
+		existingAnnotations map[string]string
+		existingNodeLabels  map[string]string
+		expectedAnnotations map[string]string
+		expectedNodeLabels  map[string]string
+	}{
+		{
+			name:           "with a GPU instance type with instance storage",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			instanceType: InstanceType{
+				InstanceType:      "g4ad.xlarge",
+				VCPU:              4,
+				MemoryMb:          16384,
+				GPU:               1,
+				GPUManufacturer:   "AMD",
+				GPUModel:          "Radeon Pro V520",
+				InstanceStorageGB: 150,
+				CPUArchitecture:   ArchitectureAmd64,
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:             "4",
+				memoryKey:          "16384",
+				gpuKey:             "1",
+				gpuTypeKey:         "amd.com/gpu",
+				gpuManufacturerKey: "AMD",
+				gpuModelKey:        "Radeon Pro V520",
+				ephemeralDiskKey:   "150G",
+				labelsKey:          "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=g4ad.xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{
+					gpuTypeKey:         "amd.com/gpu",
+					gpuManufacturerKey: "AMD",
+					gpuModelKey:        "Radeon Pro V520",
+					ephemeralDiskKey:   "150G",
+				}, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=g4ad.xlarge"),
+			},
+		},
+		{
+			name:           "with scheduling attributes",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			instanceType: InstanceType{
+				InstanceType:            "trn1.32xlarge",
+				VCPU:                    128,
+				MemoryMb:                524288,
+				InstanceStorageGB:       7600,
+				CPUArchitecture:         ArchitectureAmd64,
+				CPUManufacturer:         "Intel",
+				NetworkBandwidthMbps:    800000,
+				LocalNVMe:               true,
+				EFASupported:            true,
+				AcceleratorManufacturer: "AWS",
+				AcceleratorName:         "Trainium",
+				AcceleratorCount:        16,
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:           "128",
+				memoryKey:        "524288",
+				gpuKey:           "0",
+				ephemeralDiskKey: "7600G",
+				labelsKey: "kubernetes.io/arch=amd64,machine.openshift.io/instance-accelerator-count=16,machine.openshift.io/instance-accelerator-manufacturer=aws," +
+					"machine.openshift.io/instance-accelerator-name=trainium,machine.openshift.io/instance-cpu-manufacturer=intel,machine.openshift.io/instance-efa-supported=true," +
+					"machine.openshift.io/instance-local-nvme=true,machine.openshift.io/instance-network-bandwidth=800000,node.kubernetes.io/instance-type=trn1.32xlarge",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValueWithNodeLabels(map[string]string{ephemeralDiskKey: "7600G"},
+					"kubernetes.io/arch=amd64,machine.openshift.io/instance-accelerator-count=16,machine.openshift.io/instance-accelerator-manufacturer=aws,"+
+						"machine.openshift.io/instance-accelerator-name=trainium,machine.openshift.io/instance-cpu-manufacturer=intel,machine.openshift.io/instance-efa-supported=true,"+
+						"machine.openshift.io/instance-local-nvme=true,machine.openshift.io/instance-network-bandwidth=800000,node.kubernetes.io/instance-type=trn1.32xlarge",
+					"machine.openshift.io/instance-accelerator-count=16,machine.openshift.io/instance-accelerator-manufacturer=aws,machine.openshift.io/instance-accelerator-name=trainium,"+
+						"machine.openshift.io/instance-cpu-manufacturer=intel,machine.openshift.io/instance-efa-supported=true,machine.openshift.io/instance-local-nvme=true,"+
+						"machine.openshift.io/instance-network-bandwidth=800000"),
+			},
+			expectedNodeLabels: map[string]string{
+				AcceleratorCountLabel:        "16",
+				AcceleratorManufacturerLabel: "aws",
+				AcceleratorNameLabel:         "trainium",
+				CPUManufacturerLabel:         "intel",
+				EFASupportedLabel:            "true",
+				LocalNVMeLabel:               "true",
+				NetworkBandwidthLabel:        "800000",
+			},
+		},
+		{
+			name: "with a root volume, placement and taints",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1a"},
+				BlockDevices: []machinev1beta1.BlockDeviceMappingSpec{
+					{DeviceName: ptr.To("/dev/sdb"), EBS: &machinev1beta1.EBSBlockDeviceSpec{VolumeSize: ptr.To[int64](500)}},
+					{EBS: &machinev1beta1.EBSBlockDeviceSpec{VolumeSize: ptr.To[int64](120)}},
+				},
+			},
+			taints: []corev1.Taint{
+				{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+			},
+			instanceType: InstanceType{
+				InstanceType:      "m5d.24xlarge",
+				VCPU:              96,
+				MemoryMb:          393216,
+				InstanceStorageGB: 3600,
+				CPUArchitecture:   ArchitectureAmd64,
+			},
+			existingAnnotations: map[string]string{
+				labelsKey: "node-role.kubernetes.io/infra=",
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:           "96",
+				memoryKey:        "393216",
+				gpuKey:           "0",
+				ephemeralDiskKey: "120Gi",
+				taintsKey:        "dedicated=infra:NoSchedule,spot:PreferNoSchedule",
+				labelsKey:        "kubernetes.io/arch=amd64,node-role.kubernetes.io/infra=,node.kubernetes.io/instance-type=m5d.24xlarge,topology.kubernetes.io/region=us-east-1,topology.kubernetes.io/zone=us-east-1a",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{
+					ephemeralDiskKey: "120Gi",
+					taintsKey:        "dedicated=infra:NoSchedule,spot:PreferNoSchedule",
+				}, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m5d.24xlarge,topology.kubernetes.io/region=us-east-1,topology.kubernetes.io/zone=us-east-1a"),
+			},
+		},
+		{
+			name:           "with annotations which no longer apply",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			instanceType: InstanceType{
+				InstanceType:    "m5.large",
+				VCPU:            2,
+				MemoryMb:        8192,
+				CPUArchitecture: ArchitectureAmd64,
+			},
+			existingAnnotations: map[string]string{
+				gpuTypeKey:         "nvidia.com/gpu",
+				gpuManufacturerKey: "NVIDIA",
+				gpuModelKey:        "T4",
+				ephemeralDiskKey:   "125G",
+				maxPodsKey:         "29",
+				taintsKey:          "dedicated=gpu:NoSchedule",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{
+					gpuTypeKey:         "nvidia.com/gpu",
+					gpuManufacturerKey: "NVIDIA",
+					gpuModelKey:        "T4",
+					ephemeralDiskKey:   "125G",
+					maxPodsKey:         "29",
+					taintsKey:          "dedicated=gpu:NoSchedule",
+				}, ""),
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:                  "2",
+				memoryKey:               "8192",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m5.large",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m5.large"),
+			},
+		},
+		{
+			name:           "with manually populated annotations",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			instanceType: InstanceType{
+				InstanceType:    "m5.large",
+				VCPU:            2,
+				MemoryMb:        8192,
+				CPUArchitecture: ArchitectureAmd64,
+			},
+			existingAnnotations: map[string]string{
+				ephemeralDiskKey:        "100Gi",
+				taintsKey:               "dedicated=infra:NoSchedule",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(map[string]string{ephemeralDiskKey: "125G"}, ""),
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:                  "2",
+				memoryKey:               "8192",
+				gpuKey:                  "0",
+				ephemeralDiskKey:        "100Gi",
+				taintsKey:               "dedicated=infra:NoSchedule",
+				labelsKey:               "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m5.large",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=amd64,node.kubernetes.io/instance-type=m5.large"),
+			},
+		},
+		{
+			name:           "with manually populated labels",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			instanceType: InstanceType{
+				InstanceType:    "m5.large",
+				VCPU:            2,
+				MemoryMb:        8192,
+				CPUArchitecture: ArchitectureAmd64,
+			},
+			existingAnnotations: map[string]string{
+				labelsKey: "kubernetes.io/arch=arm64,node.kubernetes.io/instance-type=m5.large",
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:                  "2",
+				memoryKey:               "8192",
+				gpuKey:                  "0",
+				labelsKey:               "kubernetes.io/arch=arm64,node.kubernetes.io/instance-type=m5.large",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "node.kubernetes.io/instance-type=m5.large"),
+			},
+		},
+		{
+			name: "with a change of instance type and zone",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1b"},
+			},
+			instanceType: InstanceType{
+				InstanceType:         "m6g.large",
+				VCPU:                 2,
+				MemoryMb:             8192,
+				CPUArchitecture:      ArchitectureArm64,
+				CPUManufacturer:      "AWS",
+				NetworkBandwidthMbps: 750,
+			},
+			existingAnnotations: map[string]string{
+				cpuKey:    "2",
+				memoryKey: "8192",
+				gpuKey:    "0",
+				labelsKey: "kubernetes.io/arch=amd64,machine.openshift.io/instance-cpu-manufacturer=intel,machine.openshift.io/instance-network-bandwidth=750," +
+					"node-role.kubernetes.io/worker=,node.kubernetes.io/instance-type=m5.large,topology.kubernetes.io/region=us-east-1,topology.kubernetes.io/zone=us-east-1a",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValueWithNodeLabels(nil, "kubernetes.io/arch=amd64,machine.openshift.io/instance-cpu-manufacturer=intel,"+
+					"machine.openshift.io/instance-network-bandwidth=750,node.kubernetes.io/instance-type=m5.large,topology.kubernetes.io/region=us-east-1,topology.kubernetes.io/zone=us-east-1a",
+					"machine.openshift.io/instance-cpu-manufacturer=intel,machine.openshift.io/instance-local-nvme=true,machine.openshift.io/instance-network-bandwidth=750"),
+			},
+			existingNodeLabels: map[string]string{
+				CPUManufacturerLabel:  "intel",
+				LocalNVMeLabel:        "true",
+				NetworkBandwidthLabel: "750",
+				"node-role":           "worker",
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:    "2",
+				memoryKey: "8192",
+				gpuKey:    "0",
+				labelsKey: "kubernetes.io/arch=arm64,machine.openshift.io/instance-cpu-manufacturer=aws,machine.openshift.io/instance-network-bandwidth=750," +
+					"node-role.kubernetes.io/worker=,node.kubernetes.io/instance-type=m6g.large,topology.kubernetes.io/region=us-east-1,topology.kubernetes.io/zone=us-east-1b",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValueWithNodeLabels(nil, "kubernetes.io/arch=arm64,machine.openshift.io/instance-cpu-manufacturer=aws,"+
+					"machine.openshift.io/instance-network-bandwidth=750,node.kubernetes.io/instance-type=m6g.large,topology.kubernetes.io/region=us-east-1,topology.kubernetes.io/zone=us-east-1b",
+					"machine.openshift.io/instance-cpu-manufacturer=aws,machine.openshift.io/instance-network-bandwidth=750"),
+			},
+			expectedNodeLabels: map[string]string{
+				CPUManufacturerLabel:  "aws",
+				NetworkBandwidthLabel: "750",
+				"node-role":           "worker",
+			},
+		},
+		{
+			name:           "with node labels set manually on the template",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			instanceType: InstanceType{
+				InstanceType:         "m6g.large",
+				VCPU:                 2,
+				MemoryMb:             8192,
+				CPUArchitecture:      ArchitectureArm64,
+				CPUManufacturer:      "AWS",
+				NetworkBandwidthMbps: 750,
+			},
+			existingAnnotations: map[string]string{
+				scaleFromZeroManagedKey: scaleFromZeroRecordValueWithNodeLabels(nil, "", "machine.openshift.io/instance-network-bandwidth=750"),
+			},
+			existingNodeLabels: map[string]string{
+				CPUManufacturerLabel:  "graviton",
+				NetworkBandwidthLabel: "1000",
+			},
+			expectedAnnotations: map[string]string{
+				cpuKey:    "2",
+				memoryKey: "8192",
+				gpuKey:    "0",
+				labelsKey: "kubernetes.io/arch=arm64,machine.openshift.io/instance-cpu-manufacturer=graviton,machine.openshift.io/instance-network-bandwidth=1000," +
+					"node.kubernetes.io/instance-type=m6g.large",
+				scaleFromZeroManagedKey: scaleFromZeroRecordValue(nil, "kubernetes.io/arch=arm64,machine.openshift.io/instance-cpu-manufacturer=graviton,"+
+					"machine.openshift.io/instance-network-bandwidth=1000,node.kubernetes.io/instance-type=m6g.large"),
+			},
+			expectedNodeLabels: map[string]string{
+				CPUManufacturerLabel:  "graviton",
+				NetworkBandwidthLabel: "1000",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machineSet := &machinev1beta1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.existingAnnotations},
+				Spec: machinev1beta1.MachineSetSpec{
+					Template: machinev1beta1.MachineTemplateSpec{
+						Spec: machinev1beta1.MachineSpec{
+							ObjectMeta: machinev1beta1.ObjectMeta{Labels: tc.existingNodeLabels},
+							Taints:     tc.taints,
+						},
+					},
+				},
+			}
+
+			setScaleFromZeroAnnotations(machineSet, tc.providerConfig, tc.instanceType)
+			g.Expect(machineSet.Annotations).To(Equal(tc.expectedAnnotations))
+			g.Expect(machineSet.Spec.Template.Spec.ObjectMeta.Labels).To(Equal(tc.expectedNodeLabels))
+
+			// The autoscaler simulates the labels the nodes actually get.
+			published := map[string]string{}
+			for _, label := range strings.Split(machineSet.Annotations[labelsKey], ",") {
+				key, value, _ := strings.Cut(label, "=")
+				published[key] = value
+			}
+			for key, value := range machineSet.Spec.Template.Spec.ObjectMeta.Labels {
+				if instanceTypeLabelKeys.Has(key) {
+					g.Expect(published).To(HaveKeyWithValue(key, value))
+				}
+			}
+			for key, value := range published {
+				if instanceTypeLabelKeys.Has(key) && key != corev1.LabelArchStable && key != corev1.LabelInstanceTypeStable {
+					g.Expect(machineSet.Spec.Template.Spec.ObjectMeta.Labels).To(HaveKeyWithValue(key, value))
+				}
+			}
+		})
+	}
+}
+
+// scaleFromZeroRecordValue returns the value of the annotation recording the given annotations and comma separated
+// labels set by the controller.
+func scaleFromZeroRecordValue(annotations map[string]string, labels string) string {
+	return scaleFromZeroRecordValueWithNodeLabels(annotations, labels, "")
+}
+
+// scaleFromZeroRecordValueWithNodeLabels returns the value of the annotation recording the given annotations, and
+// comma separated labels and node labels set by the controller.
+func scaleFromZeroRecordValueWithNodeLabels(annotations map[string]string, labels, nodeLabels string) string {
+	record := scaleFromZeroRecord{Annotations: annotations, Labels: parseLabels(labels), NodeLabels: parseLabels(nodeLabels)}
+	value, err := json.Marshal(record)
+	if err != nil {
+		panic(err)
+	}
+	return string(value)
+}
+
+// parseLabels returns the labels of a comma separated key=value list, or nil if it is empty.
+func parseLabels(labels string) map[string]string {
+	if labels == "" {
+		return nil
+	}
+	parsed := map[string]string{}
+	for _, label := range strings.Split(labels, ",") {
+		key, value, _ := strings.Cut(label, "=")
+		parsed[key] = value
+	}
+	return parsed
+}
+
+func TestTransformInstanceType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		info     *ec2.InstanceTypeInfo
+		expected InstanceType
+	}{
+		{
+			name: "with Neuron devices, EFA and NVMe instance store",
+			info: &ec2.InstanceTypeInfo{
+				InstanceType:  aws.String("trn1.32xlarge"),
+				ProcessorInfo: &ec2.ProcessorInfo{Manufacturer: aws.String("Intel"), SupportedArchitectures: aws.StringSlice([]string{"x86_64"})},
+				InstanceStorageInfo: &ec2.InstanceStorageInfo{
+					TotalSizeInGB: aws.Int64(7600),
+					NvmeSupport:   aws.String(ec2.EphemeralNvmeSupportRequired),
+				},
+				NetworkInfo: &ec2.NetworkInfo{
+					EfaSupported: aws.Bool(true),
+					NetworkCards: []*ec2.NetworkCardInfo{
+						{BaselineBandwidthInGbps: aws.Float64(100)},
+						{BaselineBandwidthInGbps: aws.Float64(100)},
+					},
+				},
+				NeuronInfo: &ec2.NeuronInfo{
+					NeuronDevices: []*ec2.NeuronDeviceInfo{{Name: aws.String("Trainium"), Count: aws.Int64(16)}},
+				},
+			},
+			expected: InstanceType{
+				InstanceType:            "trn1.32xlarge",
+				CPUArchitecture:         ArchitectureAmd64,
+				CPUManufacturer:         "Intel",
+				InstanceStorageGB:       7600,
+				LocalNVMe:               true,
+				EFASupported:            true,
+				NetworkBandwidthMbps:    200000,
+				AcceleratorManufacturer: "AWS",
+				AcceleratorName:         "Trainium",
+				AcceleratorCount:        16,
+			},
+		},
+		{
+			name: "with inference accelerators",
+			info: &ec2.InstanceTypeInfo{
+				InstanceType:  aws.String("inf1.xlarge"),
+				ProcessorInfo: &ec2.ProcessorInfo{Manufacturer: aws.String("Intel"), SupportedArchitectures: aws.StringSlice([]string{"x86_64"})},
+				NetworkInfo: &ec2.NetworkInfo{
+					NetworkCards: []*ec2.NetworkCardInfo{{BaselineBandwidthInGbps: aws.Float64(1.25)}},
+				},
+				InferenceAcceleratorInfo: &ec2.InferenceAcceleratorInfo{
+					Accelerators: []*ec2.InferenceDeviceInfo{{Name: aws.String("Inferentia"), Manufacturer: aws.String("AWS"), Count: aws.Int64(1)}},
+				},
+			},
+			expected: InstanceType{
+				InstanceType:            "inf1.xlarge",
+				CPUArchitecture:         ArchitectureAmd64,
+				CPUManufacturer:         "Intel",
+				NetworkBandwidthMbps:    1250,
+				AcceleratorManufacturer: "AWS",
+				AcceleratorName:         "Inferentia",
+				AcceleratorCount:        1,
+			},
+		},
+		{
+			name: "with a Graviton processor and EBS only",
+			info: &ec2.InstanceTypeInfo{
+				InstanceType:  aws.String("m7g.large"),
+				ProcessorInfo: &ec2.ProcessorInfo{Manufacturer: aws.String("AWS"), SupportedArchitectures: aws.StringSlice([]string{"arm64"})},
+			},
+			expected: InstanceType{
+				InstanceType:    "m7g.large",
+				CPUArchitecture: ArchitectureArm64,
+				CPUManufacturer: "AWS",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(transformInstanceType(tc.info)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestValidateInstanceType(t *testing.T) {
+	m5Large := InstanceType{InstanceType: "m5.large", CPUArchitecture: ArchitectureAmd64}
+	offerings := &ec2.DescribeInstanceTypeOfferingsOutput{
+		InstanceTypeOfferings: []*ec2.InstanceTypeOffering{
+			{InstanceType: aws.String("m5.large"), Location: aws.String("us-east-1a")},
+			{InstanceType: aws.String("m5.large"), Location: aws.String("us-east-1b")},
+			{InstanceType: aws.String("m6g.large"), Location: aws.String("us-east-1a")},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		providerConfig    *machinev1beta1.AWSMachineProviderConfig
+		subnetZone        string
+		imageArchitecture string
+		expectedStatus    corev1.ConditionStatus
+		expectedReason    string
+	}{
+		{
+			name:           "without placement",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{},
+			expectedStatus: corev1.ConditionTrue,
+		},
+		{
+			name: "with an instance type offered in the zone",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1b"},
+			},
+			expectedStatus: corev1.ConditionTrue,
+		},
+		{
+			name: "with an instance type not offered in the zone",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1c"},
+			},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: instanceTypeNotOfferedReason,
+		},
+		{
+			name: "with an instance type not offered in the zone of the subnet",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1"},
+				Subnet:    machinev1beta1.AWSResourceReference{ID: aws.String("subnet-1")},
+			},
+			subnetZone:     "us-east-1c",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: instanceTypeNotOfferedReason,
+		},
+		{
+			name: "with a subnet in another zone",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1a"},
+				Subnet:    machinev1beta1.AWSResourceReference{ID: aws.String("subnet-1")},
+			},
+			subnetZone:     "us-east-1b",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: subnetZoneMismatchReason,
+		},
+		{
+			name: "with a matching AMI",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1a"},
+				AMI:       machinev1beta1.AWSResourceReference{ID: aws.String("ami-1")},
+			},
+			imageArchitecture: ec2.ArchitectureValuesX8664,
+			expectedStatus:    corev1.ConditionTrue,
+		},
+		{
+			name: "with an arm64 AMI",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				Placement: machinev1beta1.Placement{Region: "us-east-1", AvailabilityZone: "us-east-1a"},
+				AMI:       machinev1beta1.AWSResourceReference{ID: aws.String("ami-1")},
+			},
+			imageArchitecture: ec2.ArchitectureValuesArm64,
+			expectedStatus:    corev1.ConditionFalse,
+			expectedReason:    architectureMismatchReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+			mockAWSClient.EXPECT().DescribeInstanceTypeOfferings(gomock.Any(), gomock.Any()).Return(offerings, nil).AnyTimes()
+			if tc.subnetZone != "" {
+				mockAWSClient.EXPECT().DescribeSubnets(gomock.Any(), &ec2.DescribeSubnetsInput{SubnetIds: []*string{aws.String("subnet-1")}}).Return(&ec2.DescribeSubnetsOutput{
+					Subnets: []*ec2.Subnet{{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String(tc.subnetZone)}},
+				}, nil)
+			}
+			if tc.imageArchitecture != "" {
+				mockAWSClient.EXPECT().DescribeImages(gomock.Any(), &ec2.DescribeImagesInput{ImageIds: []*string{aws.String("ami-1")}}).Return(&ec2.DescribeImagesOutput{
+					Images: []*ec2.Image{{ImageId: aws.String("ami-1"), Architecture: aws.String(tc.imageArchitecture)}},
+				}, nil)
+			}
+
+			r := Reconciler{InstanceTypesCache: NewInstanceTypesCache()}
+			condition, _, err := r.validateInstanceType(context.TODO(), mockAWSClient, tc.providerConfig, m5Large)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(condition.Type).To(Equal(InstanceTypeValidCondition))
+			g.Expect(condition.Status).To(Equal(tc.expectedStatus))
+			g.Expect(condition.Reason).To(Equal(tc.expectedReason))
+		})
+	}
+}
+
+func TestInstanceTypeResolver(t *testing.T) {
+	instanceTypes := InstanceTypesSnapshot{
+		InstanceTypes: map[string]InstanceType{
+			"m5.large":    {InstanceType: "m5.large", VCPU: 2, MemoryMb: 8192, CPUArchitecture: ArchitectureAmd64},
+			"m6g.large":   {InstanceType: "m6g.large", VCPU: 2, MemoryMb: 8192, CPUArchitecture: ArchitectureArm64},
+			"t3.large":    {InstanceType: "t3.large", VCPU: 2, MemoryMb: 8192, CPUArchitecture: ArchitectureAmd64, Burstable: true},
+			"g4dn.xlarge": {InstanceType: "g4dn.xlarge", VCPU: 4, MemoryMb: 16384, GPU: 1, CPUArchitecture: ArchitectureAmd64},
+			"x9.large":    {InstanceType: "x9.large", VCPU: 2, MemoryMb: 8192, CPUArchitecture: ArchitectureAmd64},
+			"x9g.medium":  {InstanceType: "x9g.medium", VCPU: 1, MemoryMb: 2048, CPUArchitecture: ArchitectureArm64},
+			"a1.xlarge":   {InstanceType: "a1.xlarge", VCPU: 4, MemoryMb: 8192, CPUArchitecture: ArchitectureArm64},
+		},
+		LastUpdate: time.Now(),
+	}
+	offerings := &ec2.DescribeInstanceTypeOfferingsOutput{
+		InstanceTypeOfferings: []*ec2.InstanceTypeOffering{
+			{InstanceType: aws.String("m5.large"), Location: aws.String("us-east-1a")},
+			{InstanceType: aws.String("m6g.large"), Location: aws.String("us-east-1a")},
+			{InstanceType: aws.String("t3.large"), Location: aws.String("us-east-1a")},
+			{InstanceType: aws.String("g4dn.xlarge"), Location: aws.String("us-east-1a")},
+			{InstanceType: aws.String("m5.large"), Location: aws.String("us-east-1b")},
+			{InstanceType: aws.String("x9.large"), Location: aws.String("us-east-1b")},
+		},
+	}
+	onDemandPrices := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: OnDemandPricesConfigMapName},
+		Data: map[string]string{
+			"us-east-1.m5.large":    "0.096",
+			"us-east-1.m6g.large":   "0.077",
+			"us-east-1.t3.large":    "0.0832",
+			"us-east-1.g4dn.xlarge": "0.526",
+			"us-east-1.a1.xlarge":   "0.0408",
+			"us-west-2.x9.large":    "0.01",
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		requirements utils.InstanceRequirements
+		zone         string
+		withoutPrice bool
+		expected     string
+		expectErr    bool
+	}{
+		{
+			name:         "ranks by price when all matching instance types are listed",
+			requirements: utils.InstanceRequirements{Architecture: "arm64", VCPU: utils.Range{Min: 2}},
+			expected:     "a1.xlarge",
+		},
+		{
+			name:         "ranks by size when a matching instance type is unlisted",
+			requirements: utils.InstanceRequirements{VCPU: utils.Range{Min: 2}},
+			expected:     "m6g.large",
+		},
+		{
+			name:         "with an architecture",
+			requirements: utils.InstanceRequirements{Architecture: "amd64"},
+			expected:     "t3.large",
+		},
+		{
+			name:         "excluding burstable instance types",
+			requirements: utils.InstanceRequirements{Architecture: "amd64", Burstable: ptr.To(false)},
+			expected:     "m5.large",
+		},
+		{
+			name:         "with excluded families",
+			requirements: utils.InstanceRequirements{Architecture: "amd64", ExcludedFamilies: []string{"t3"}},
+			expected:     "m5.large",
+		},
+		{
+			name:         "with GPUs",
+			requirements: utils.InstanceRequirements{GPU: utils.Range{Min: 1}, MemoryMiB: utils.Range{Min: 16384, Max: ptr.To[int64](16384)}},
+			expected:     "g4dn.xlarge",
+		},
+		{
+			name:         "with instance types offered in the zone only",
+			requirements: utils.InstanceRequirements{ExcludedFamilies: []string{"m5"}},
+			zone:         "us-east-1b",
+			expected:     "x9.large",
+		},
+		{
+			name:         "ranking unlisted instance types by size alongside listed ones",
+			requirements: utils.InstanceRequirements{Architecture: "arm64"},
+			expected:     "x9g.medium",
+		},
+		{
+			name:         "without prices",
+			requirements: utils.InstanceRequirements{Architecture: "amd64", GPU: utils.Range{Max: ptr.To[int64](0)}},
+			withoutPrice: true,
+			expected:     "m5.large",
+		},
+		{
+			name:         "when no instance type matches",
+			requirements: utils.InstanceRequirements{VCPU: utils.Range{Min: 64}},
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			mockAWSClient := mockaws.NewMockClient(mockCtrl)
+			mockAWSClient.EXPECT().DescribeInstanceTypeOfferings(gomock.Any(), gomock.Any()).Return(offerings, nil).AnyTimes()
+
+			cache := NewInstanceTypesCache()
+			cache.Restore("us-east-1", instanceTypes)
+			var prices PriceCache
+			if !tc.withoutPrice {
+				prices = NewPriceCache(DefaultPriceRefreshInterval, fake.NewClientBuilder().WithObjects(onDemandPrices.DeepCopy()).Build())
+			}
+
+			instanceType, err := NewInstanceTypeResolver(cache, prices).ResolveInstanceType(context.TODO(), mockAWSClient, "default", "us-east-1", tc.zone, tc.requirements)
+			if tc.expectErr {
+				g.Expect(err).To(MatchError(utils.ErrNoMatchingInstanceType))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(instanceType).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestReconcilerResolveInstanceType(t *testing.T) {
+	instanceTypes := InstanceTypesSnapshot{
+		InstanceTypes: map[string]InstanceType{
+			"m5.large": {InstanceType: "m5.large", VCPU: 2, MemoryMb: 8192, CPUArchitecture: ArchitectureAmd64},
+		},
+		LastUpdate: time.Now(),
+	}
+
+	testCases := []struct {
+		name             string
+		requirements     string
+		instanceType     string
+		expectedResolved bool
+		expectedType     string
+		expectedReason   string
+		expectErr        bool
+	}{
+		{
+			name:             "without requirements",
+			instanceType:     "m5.large",
+			expectedResolved: true,
+			expectedType:     "m5.large",
+		},
+		{
+			name:             "with satisfiable requirements",
+			requirements:     `{"vcpu":{"min":2}}`,
+			expectedResolved: true,
+			expectedType:     "m5.large",
+		},
+		{
+			name:           "with unsatisfiable requirements",
+			requirements:   `{"vcpu":{"min":4}}`,
+			expectedReason: instanceRequirementsUnsatisfiableReason,
+		},
+		{
+			name:             "with requirements overriding a defaulted instance type",
+			requirements:     `{"vcpu":{"min":2}}`,
+			instanceType:     "m6g.large",
+			expectedResolved: true,
+			expectedType:     "m5.large",
+		},
+		{
+			name:         "with invalid requirements",
+			requirements: `{"vcpu":{"min":-1}}`,
+			instanceType: "m6g.large",
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cache := NewInstanceTypesCache()
+			cache.Restore("us-east-1", instanceTypes)
+			r := Reconciler{InstanceTypesCache: cache, recorder: record.NewFakeRecorder(10)}
+
+			machineSet := &machinev1beta1.MachineSet{}
+			if tc.requirements != "" {
+				machineSet.Spec.Template.Annotations = map[string]string{utils.InstanceRequirementsAnnotation: tc.requirements}
+			}
+			providerConfig := &machinev1beta1.AWSMachineProviderConfig{InstanceType: tc.instanceType, Placement: machinev1beta1.Placement{Region: "us-east-1"}}
+
+			resolved, err := r.resolveInstanceType(context.TODO(), nil, machineSet, providerConfig)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resolved).To(Equal(tc.expectedResolved))
+			if tc.expectedReason != "" {
+				condition := conditions.Get(machineSet, InstanceTypeValidCondition)
+				g.Expect(condition).ToNot(BeNil())
+				g.Expect(condition.Reason).To(Equal(tc.expectedReason))
+				g.Expect(machineSet.Annotations).ToNot(HaveKey(utils.ResolvedInstanceTypeAnnotation))
+				return
+			}
+			g.Expect(providerConfig.InstanceType).To(Equal(tc.expectedType))
+			if tc.requirements != "" {
+				g.Expect(machineSet.Annotations).To(HaveKeyWithValue(utils.ResolvedInstanceTypeAnnotation, tc.expectedType))
+			}
+		})
+	}
+}
+
+func TestSetInstanceTypeValidCondition(t *testing.T) {
+	g := NewWithT(t)
+	recorder := record.NewFakeRecorder(10)
+	r := Reconciler{recorder: recorder}
+	machineSet := &machinev1beta1.MachineSet{}
+
+	notOffered := func() *machinev1beta1.Condition {
+		return conditions.FalseCondition(InstanceTypeValidCondition, instanceTypeNotOfferedReason, machinev1beta1.ConditionSeverityError,
+			"instance type m5.large is not offered in availability zone us-east-1c")
+	}
+
+	r.setInstanceTypeValidCondition(machineSet, notOffered())
+	r.setInstanceTypeValidCondition(machineSet, notOffered())
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(ContainSubstring(instanceTypeNotOfferedReason))
+
+	r.setInstanceTypeValidCondition(machineSet, conditions.TrueCondition(InstanceTypeValidCondition))
+	g.Expect(recorder.Events).To(BeEmpty())
+	g.Expect(conditions.IsTrue(machineSet, InstanceTypeValidCondition)).To(BeTrue())
+}
+
+func TestApplyOwnedConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	// The latest MachineSet has a Paused condition set by another controller meanwhile.
+	machineSet := &machinev1beta1.MachineSet{}
+	conditions.Set(machineSet, conditions.TrueCondition(mapimachine.PausedCondition))
+	conditions.Set(machineSet, conditions.TrueCondition(QuotaExceededCondition))
+
+	// The reconcile computed the InstanceTypeValid condition and removed the QuotaExceeded one.
+	computed := &machinev1beta1.MachineSet{}
+	conditions.Set(computed, conditions.TrueCondition(InstanceTypeValidCondition))
+
+	applyOwnedConditions(machineSet, &computed.Status)
+
+	g.Expect(conditions.IsTrue(machineSet, mapimachine.PausedCondition)).To(BeTrue())
+	g.Expect(conditions.IsTrue(machineSet, InstanceTypeValidCondition)).To(BeTrue())
+	g.Expect(conditions.Get(machineSet, QuotaExceededCondition)).To(BeNil())
+}
+
+func TestEarliestRequeue(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(earliestRequeue(ctrl.Result{}, ctrl.Result{RequeueAfter: time.Hour})).To(Equal(ctrl.Result{RequeueAfter: time.Hour}))
+	g.Expect(earliestRequeue(ctrl.Result{RequeueAfter: time.Minute}, ctrl.Result{RequeueAfter: time.Hour})).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+	g.Expect(earliestRequeue(ctrl.Result{RequeueAfter: time.Hour}, ctrl.Result{})).To(Equal(ctrl.Result{RequeueAfter: time.Hour}))
+}
+
+func TestInstanceTypesCacheGetInstanceTypeStale(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().DescribeInstanceTypes(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("service unavailable")).Times(1)
+
+	cache := NewInstanceTypesCache()
+	_, err := cache.GetInstanceType(context.TODO(), mockAWSClient, "us-east-1", "m5.large")
+	g.Expect(err).To(MatchError(ContainSubstring("service unavailable")))
+
+	// The failed refresh is cached, the instance types are not fetched again until the backoff expires.
+	_, err = cache.GetInstanceType(context.TODO(), mockAWSClient, "us-east-1", "m5.large")
+	g.Expect(err).To(MatchError(ContainSubstring("service unavailable")))
+
+	cache.Restore("us-east-1", InstanceTypesSnapshot{
+		InstanceTypes: map[string]InstanceType{"m5.large": {InstanceType: "m5.large", VCPU: 2, MemoryMb: 8192}},
+		LastUpdate:    time.Now().Add(-2 * instanceTypesCacheTTL),
+	})
+	instanceType, err := cache.GetInstanceType(context.TODO(), mockAWSClient, "us-east-1", "m5.large")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(instanceType.VCPU).To(Equal(int64(2)))
+}
+
+func TestInstanceTypesCacheRestore(t *testing.T) {
+	g := NewWithT(t)
+	older := InstanceTypesSnapshot{
+		InstanceTypes: map[string]InstanceType{"m5.large": {InstanceType: "m5.large"}},
+		LastUpdate:    time.Now().Add(-2 * time.Hour),
+	}
+	newer := InstanceTypesSnapshot{
+		InstanceTypes: map[string]InstanceType{"m5.xlarge": {InstanceType: "m5.xlarge"}},
+		LastUpdate:    time.Now().Add(-time.Hour),
+	}
+
+	cache := NewInstanceTypesCache()
+	_, ok := cache.Snapshot("us-east-1")
+	g.Expect(ok).To(BeFalse())
+
+	cache.Restore("us-east-1", InstanceTypesSnapshot{LastUpdate: time.Now()})
+	_, ok = cache.Snapshot("us-east-1")
+	g.Expect(ok).To(BeFalse(), "empty snapshots must be ignored")
+
+	cache.Restore("us-east-1", newer)
+	cache.Restore("us-east-1", older)
+	snapshot, ok := cache.Snapshot("us-east-1")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(snapshot.InstanceTypes).To(HaveKey("m5.xlarge"), "older snapshots must not replace newer instance types")
+}
+
+func TestInstanceTypesCacheWarmer(t *testing.T) {
+	g := NewWithT(t)
+	testScheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(machinev1beta1.AddToScheme(testScheme)).To(Succeed())
+
+	providerSpec, err := providerSpecFromMachine(&machinev1beta1.AWSMachineProviderConfig{
+		InstanceType:      "m5.large",
+		CredentialsSecret: &corev1.LocalObjectReference{Name: "test-credentials"},
+		Placement:         machinev1beta1.Placement{Region: "us-east-1"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	machineSet := &machinev1beta1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: machinev1beta1.MachineSetSpec{
+			Template: machinev1beta1.MachineTemplateSpec{Spec: machinev1beta1.MachineSpec{ProviderSpec: providerSpec}},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(machineSet).Build()
+
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().DescribeInstanceTypes(gomock.Any(), gomock.Any()).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{{
+			InstanceType: aws.String("m5.large"),
+			VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)},
+			MemoryInfo:   &ec2.MemoryInfo{SizeInMiB: aws.Int64(8192)},
+			ProcessorInfo: &ec2.ProcessorInfo{
+				SupportedArchitectures: []*string{aws.String(ec2.ArchitectureTypeX8664)},
+			},
+		}},
+	}, nil).Times(1)
+	awsClientBuilder := func(_ context.Context, _ client.Client, secretName, namespace, region string, _ client.Client, _ awsclient.RegionCache) (awsclient.Client, error) {
+		g.Expect(secretName).To(Equal("test-credentials"))
+		g.Expect(namespace).To(Equal("default"))
+		g.Expect(region).To(Equal("us-east-1"))
+		return mockAWSClient, nil
+	}
+
+	warmer := &InstanceTypesCacheWarmer{
+		Client:             k8sClient,
+		AwsClientBuilder:   awsClientBuilder,
+		InstanceTypesCache: NewInstanceTypesCache(),
+		Interval:           DefaultInstanceTypesWarmInterval,
+		APIReader:          k8sClient,
+	}
+	// The second warm finds the instance types fresh and does not fetch them again.
+	warmer.warm(context.TODO())
+	warmer.warm(context.TODO())
+
+	configMap := &corev1.ConfigMap{}
+	g.Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: InstanceTypesSnapshotConfigMapName}, configMap)).To(Succeed())
+	g.Expect(configMap.BinaryData).To(HaveKey("us-east-1.json.gz"))
+
+	// After a restart, the instance types are restored from the ConfigMap even though EC2 is unavailable.
+	mockAWSClient.EXPECT().DescribeInstanceTypes(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("service unavailable")).AnyTimes()
+	restarted := &InstanceTypesCacheWarmer{
+		Client:             k8sClient,
+		AwsClientBuilder:   awsClientBuilder,
+		InstanceTypesCache: NewInstanceTypesCache(),
+		Interval:           DefaultInstanceTypesWarmInterval,
+		APIReader:          k8sClient,
+	}
+	restarted.warm(context.TODO())
+
+	instanceType, err := restarted.InstanceTypesCache.GetInstanceType(context.TODO(), mockAWSClient, "us-east-1", "m5.large")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(instanceType.VCPU).To(Equal(int64(2)))
+	g.Expect(instanceType.MemoryMb).To(Equal(int64(8192)))
+	g.Expect(instanceType.CPUArchitecture).To(Equal(ArchitectureAmd64))
+}
@@ -14,14 +14,20 @@ limitations under the License.
 package machineset
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 // we define this additional type as the EC2 API returns the architecture in a different format than the one we use.
@@ -41,17 +47,83 @@ type InstanceType struct {
 	MemoryMb        int64
 	GPU             int64
 	CPUArchitecture normalizedArch
+	// GPUManufacturer and GPUModel describe the first GPU of the instance type, if any.
+	GPUManufacturer string
+	GPUModel        string
+	// InstanceStorageGB is the total size of the instance store volumes, 0 if the instance type has none.
+	InstanceStorageGB int64
+	// CPUManufacturer is the manufacturer of the processor, e.g. Intel, AMD or AWS for Graviton.
+	CPUManufacturer string
+	// NetworkBandwidthMbps is the baseline network bandwidth of all network cards, 0 if it is unknown.
+	NetworkBandwidthMbps int64
+	// LocalNVMe tells whether the instance store volumes are NVMe SSDs.
+	LocalNVMe bool
+	// EFASupported tells whether the instance type supports Elastic Fabric Adapters.
+	EFASupported bool
+	// AcceleratorManufacturer, AcceleratorName and AcceleratorCount describe the machine learning accelerators
+	// of the instance type, e.g. Inferentia or Trainium, if any.
+	AcceleratorManufacturer string
+	AcceleratorName         string
+	AcceleratorCount        int64
+	// Burstable tells whether the instance type has burstable CPU performance, e.g. the T families.
+	Burstable bool
+}
+
+const (
+	// instanceTypesCacheTTL is the duration after which cached instance types are refreshed.
+	instanceTypesCacheTTL = 24 * time.Hour
+
+	// instanceTypesRefreshFailureBackoff is the duration for which a failed refresh is cached, so that
+	// reconciles during EC2 API outages or throttling do not each fetch the instance types again.
+	instanceTypesRefreshFailureBackoff = 30 * time.Second
+)
+
+// errInstanceTypeNotFound is returned by GetInstanceType for instance types which are not in the region,
+// as opposed to failures to fetch the instance types of the region.
+var errInstanceTypeNotFound = errors.New("not found")
+
+var instanceTypesCacheLastUpdate = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "mapi_aws_instance_types_cache_last_update_timestamp_seconds",
+		Help: "Time the cached instance types of a region were last fetched from the EC2 API, possibly by a previous manager whose snapshot was restored.",
+	}, []string{"region"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(instanceTypesCacheLastUpdate)
 }
 
 // InstanceTypesCache is a cache for instance type information.
 type InstanceTypesCache interface {
-	GetInstanceType(awsClient awsclient.Client, cacheID string, instanceType string) (InstanceType, error)
+	GetInstanceType(ctx context.Context, awsClient awsclient.Client, cacheID string, instanceType string) (InstanceType, error)
+	// ListInstanceTypes returns all the instance types of the region, refreshing them like GetInstanceType.
+	ListInstanceTypes(ctx context.Context, awsClient awsclient.Client, cacheID string) ([]InstanceType, error)
+	IsInstanceTypeOffered(ctx context.Context, awsClient awsclient.Client, cacheID string, instanceType string, zone string) (bool, error)
+	// Warm refreshes the instance types of the region from the EC2 API if they are missing or older than maxAge.
+	Warm(ctx context.Context, awsClient awsclient.Client, cacheID string, maxAge time.Duration) error
+	// Snapshot returns the instance types of the region, or false if they have not been fetched.
+	Snapshot(cacheID string) (InstanceTypesSnapshot, bool)
+	// Restore fills the instance types of the region from a snapshot, unless they are more recent than it.
+	Restore(cacheID string, snapshot InstanceTypesSnapshot)
+}
+
+// InstanceTypesSnapshot holds the instance types of a region and the time they were fetched,
+// so that they can be persisted across restarts.
+type InstanceTypesSnapshot struct {
+	InstanceTypes map[string]InstanceType `json:"instanceTypes"`
+	LastUpdate    time.Time               `json:"lastUpdate"`
 }
 
 // instanceTypesRegion holds cached instance types for specific region and time when it was last updated.
+// The instance types offered in each availability zone of the region are fetched separately, on first use.
+// The error of the last refresh, if it failed, is kept for instanceTypesRefreshFailureBackoff.
 type instanceTypesRegion struct {
-	instanceTypes map[string]InstanceType
-	lastUpdate    time.Time
+	instanceTypes       map[string]InstanceType
+	lastUpdate          time.Time
+	offerings           map[string]sets.Set[string]
+	offeringsLastUpdate time.Time
+	refreshErr          error
+	refreshFailedAt     time.Time
 }
 
 // instanceTypesCache holds cached instance types per region. Acess is synchronized via rwmutex.
@@ -69,16 +141,15 @@ func NewInstanceTypesCache() InstanceTypesCache {
 }
 
 // GetInstanceType retrievees InstanceType from cache by name. If the cache is stale or nil it is refreshed first from the EC2 API.
+// Errors wrap errInstanceTypeNotFound if the instance type is not in the region.
 // The fetched instance types are specific to the region of the awsClient. Using region name as cacheID is recomended.
-func (i *instanceTypesCache) GetInstanceType(awsClient awsclient.Client, cacheID string, instanceType string) (InstanceType, error) {
+// If the refresh fails, stale instance types are used, if any, so that EC2 API outages do not affect MachineSets.
+func (i *instanceTypesCache) GetInstanceType(ctx context.Context, awsClient awsclient.Client, cacheID string, instanceType string) (InstanceType, error) {
 	i.rwmutex.RLock()
 
-	if !i.isCacheFresh(cacheID) {
+	if err := i.ensureFresh(ctx, awsClient, cacheID); err != nil {
 		i.rwmutex.RUnlock()
-		if err := i.refresh(awsClient, cacheID); err != nil {
-			return InstanceType{}, fmt.Errorf("error refreshing instance types cache: %w", err)
-		}
-		i.rwmutex.RLock()
+		return InstanceType{}, err
 	}
 
 	instanceTypeInfo, ok := i.cache[cacheID].instanceTypes[instanceType]
@@ -88,42 +159,172 @@ func (i *instanceTypesCache) GetInstanceType(awsClient awsclient.Client, cacheID
 			instanceNames = append(instanceNames, instanceType.InstanceType)
 		}
 		i.rwmutex.RUnlock()
-		return InstanceType{}, fmt.Errorf("instance type %q not found: The valid instance types in the current region are: %q", instanceType, instanceNames)
+		return InstanceType{}, fmt.Errorf("instance type %q %w: The valid instance types in the current region are: %q", instanceType, errInstanceTypeNotFound, instanceNames)
 	}
 
 	i.rwmutex.RUnlock()
 	return instanceTypeInfo, nil
 }
 
+// ListInstanceTypes implements InstanceTypesCache
+func (i *instanceTypesCache) ListInstanceTypes(ctx context.Context, awsClient awsclient.Client, cacheID string) ([]InstanceType, error) {
+	i.rwmutex.RLock()
+	defer i.rwmutex.RUnlock()
+
+	if err := i.ensureFresh(ctx, awsClient, cacheID); err != nil {
+		return nil, err
+	}
+
+	instanceTypes := make([]InstanceType, 0, len(i.cache[cacheID].instanceTypes))
+	for _, instanceType := range i.cache[cacheID].instanceTypes {
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+	return instanceTypes, nil
+}
+
+// ensureFresh refreshes the instance types of the region if they are stale, falling back to stale instance types
+// if the refresh fails. It must be called with the read lock held, which it releases while refreshing.
+func (i *instanceTypesCache) ensureFresh(ctx context.Context, awsClient awsclient.Client, cacheID string) error {
+	if i.isCacheFresh(cacheID) {
+		return nil
+	}
+
+	i.rwmutex.RUnlock()
+	err := i.refresh(ctx, awsClient, cacheID, instanceTypesCacheTTL)
+	i.rwmutex.RLock()
+	if err != nil {
+		if i.cache[cacheID].instanceTypes == nil {
+			return fmt.Errorf("error refreshing instance types cache: %w", err)
+		}
+		klog.Warningf("Using instance types of %s last updated %s: %v", cacheID, i.cache[cacheID].lastUpdate.Format(time.RFC3339), err)
+	}
+	return nil
+}
+
+// Warm implements InstanceTypesCache
+func (i *instanceTypesCache) Warm(ctx context.Context, awsClient awsclient.Client, cacheID string, maxAge time.Duration) error {
+	return i.refresh(ctx, awsClient, cacheID, maxAge)
+}
+
+// Snapshot implements InstanceTypesCache
+func (i *instanceTypesCache) Snapshot(cacheID string) (InstanceTypesSnapshot, bool) {
+	i.rwmutex.RLock()
+	defer i.rwmutex.RUnlock()
+
+	cacheForRegion, ok := i.cache[cacheID]
+	if !ok || cacheForRegion.instanceTypes == nil {
+		return InstanceTypesSnapshot{}, false
+	}
+	return InstanceTypesSnapshot{InstanceTypes: cacheForRegion.instanceTypes, LastUpdate: cacheForRegion.lastUpdate}, true
+}
+
+// Restore implements InstanceTypesCache
+func (i *instanceTypesCache) Restore(cacheID string, snapshot InstanceTypesSnapshot) {
+	i.rwmutex.Lock()
+	defer i.rwmutex.Unlock()
+
+	cacheForRegion := i.cache[cacheID]
+	if len(snapshot.InstanceTypes) == 0 || (cacheForRegion.instanceTypes != nil && !cacheForRegion.lastUpdate.Before(snapshot.LastUpdate)) {
+		return
+	}
+	cacheForRegion.instanceTypes = snapshot.InstanceTypes
+	cacheForRegion.lastUpdate = snapshot.LastUpdate
+	i.cache[cacheID] = cacheForRegion
+	instanceTypesCacheLastUpdate.WithLabelValues(cacheID).Set(float64(snapshot.LastUpdate.Unix()))
+}
+
 // isCacheFresh checks whether the cache for given cacheId is populated and has been refreshed in the last 24 hours.
 func (i *instanceTypesCache) isCacheFresh(cacheID string) bool {
+	return i.isUpdatedWithin(cacheID, instanceTypesCacheTTL)
+}
+
+// isUpdatedWithin checks whether the cache for given cacheId is populated and has been refreshed within maxAge.
+func (i *instanceTypesCache) isUpdatedWithin(cacheID string, maxAge time.Duration) bool {
 	cacheForRegion, ok := i.cache[cacheID]
-	return ok && cacheForRegion.instanceTypes != nil && cacheForRegion.lastUpdate.After(time.Now().Add(-24*time.Hour))
+	return ok && cacheForRegion.instanceTypes != nil && cacheForRegion.lastUpdate.After(time.Now().Add(-maxAge))
 }
 
-// refresh ensures that the cache is updated in a thread safe way.
-func (i *instanceTypesCache) refresh(awsClient awsclient.Client, cacheID string) error {
+// refresh ensures that the cache is updated in a thread safe way, if it is older than maxAge.
+// A failed refresh is not retried before instanceTypesRefreshFailureBackoff, its error is returned meanwhile.
+func (i *instanceTypesCache) refresh(ctx context.Context, awsClient awsclient.Client, cacheID string, maxAge time.Duration) error {
 	// Only one thread should refresh the cache at a time.
 	// Parallel refresh does not speed up the process and can cause throttling.
 	i.rwmutex.Lock()
 	defer i.rwmutex.Unlock()
 
-	if i.isCacheFresh(cacheID) {
+	if i.isUpdatedWithin(cacheID, maxAge) {
 		// Another thread has already refreshed the cache.
 		return nil
 	}
 
-	instanceTypes, err := fetchEC2InstanceTypes(awsClient)
+	cacheForRegion := i.cache[cacheID]
+	if cacheForRegion.refreshErr != nil && time.Since(cacheForRegion.refreshFailedAt) < instanceTypesRefreshFailureBackoff {
+		return cacheForRegion.refreshErr
+	}
+
+	instanceTypes, err := fetchEC2InstanceTypes(ctx, awsClient)
+	if err != nil {
+		cacheForRegion.refreshErr = fmt.Errorf("failed to refresh instance types cache: %w", err)
+		cacheForRegion.refreshFailedAt = time.Now()
+		i.cache[cacheID] = cacheForRegion
+		return cacheForRegion.refreshErr
+	}
+
+	cacheForRegion.instanceTypes = instanceTypes
+	cacheForRegion.lastUpdate = time.Now()
+	cacheForRegion.refreshErr = nil
+	i.cache[cacheID] = cacheForRegion
+	instanceTypesCacheLastUpdate.WithLabelValues(cacheID).Set(float64(cacheForRegion.lastUpdate.Unix()))
+	return nil
+}
+
+// IsInstanceTypeOffered returns whether the instance type is offered in the availability zone. If the cached offerings
+// are stale or nil they are refreshed first from the EC2 API. Zones unknown to the region offer no instance types.
+func (i *instanceTypesCache) IsInstanceTypeOffered(ctx context.Context, awsClient awsclient.Client, cacheID string, instanceType string, zone string) (bool, error) {
+	i.rwmutex.RLock()
+
+	if !i.areOfferingsFresh(cacheID) {
+		i.rwmutex.RUnlock()
+		if err := i.refreshOfferings(ctx, awsClient, cacheID); err != nil {
+			return false, fmt.Errorf("error refreshing instance type offerings cache: %w", err)
+		}
+		i.rwmutex.RLock()
+	}
+
+	defer i.rwmutex.RUnlock()
+	return i.cache[cacheID].offerings[zone].Has(instanceType), nil
+}
+
+// areOfferingsFresh checks whether the offerings for given cacheId are populated and have been refreshed in the last 24 hours.
+func (i *instanceTypesCache) areOfferingsFresh(cacheID string) bool {
+	cacheForRegion, ok := i.cache[cacheID]
+	return ok && cacheForRegion.offerings != nil && cacheForRegion.offeringsLastUpdate.After(time.Now().Add(-24*time.Hour))
+}
+
+// refreshOfferings ensures that the offerings are updated in a thread safe way.
+func (i *instanceTypesCache) refreshOfferings(ctx context.Context, awsClient awsclient.Client, cacheID string) error {
+	i.rwmutex.Lock()
+	defer i.rwmutex.Unlock()
+
+	if i.areOfferingsFresh(cacheID) {
+		// Another thread has already refreshed the offerings.
+		return nil
+	}
+
+	offerings, err := fetchEC2InstanceTypeOfferings(ctx, awsClient)
 	if err != nil {
-		return fmt.Errorf("failed to refresh instance types cache: %w", err)
+		return fmt.Errorf("failed to refresh instance type offerings cache: %w", err)
 	}
 
-	i.cache[cacheID] = instanceTypesRegion{instanceTypes: instanceTypes, lastUpdate: time.Now()}
+	cacheForRegion := i.cache[cacheID]
+	cacheForRegion.offerings = offerings
+	cacheForRegion.offeringsLastUpdate = time.Now()
+	i.cache[cacheID] = cacheForRegion
 	return nil
 }
 
 // fetchEC2InstanceTypes fetches all available instance types from EC2 API.
-func fetchEC2InstanceTypes(awsClient awsclient.Client) (map[string]InstanceType, error) {
+func fetchEC2InstanceTypes(ctx context.Context, awsClient awsclient.Client) (map[string]InstanceType, error) {
 	klog.V(3).Info("Refreshing instance types cache")
 
 	if awsClient == nil {
@@ -137,7 +338,7 @@ func fetchEC2InstanceTypes(awsClient awsclient.Client) (map[string]InstanceType,
 	requestCounter := 0
 	for {
 		requestCounter++
-		rawInstanceTypes, err := awsClient.DescribeInstanceTypes(&input)
+		rawInstanceTypes, err := awsClient.DescribeInstanceTypes(ctx, &input)
 		if err != nil {
 			return nil, fmt.Errorf("describeInstanceTypes request failed: %w", err)
 		}
@@ -163,6 +364,47 @@ func fetchEC2InstanceTypes(awsClient awsclient.Client) (map[string]InstanceType,
 	return instanceTypes, nil
 }
 
+// fetchEC2InstanceTypeOfferings fetches the instance types offered in each availability zone from EC2 API.
+func fetchEC2InstanceTypeOfferings(ctx context.Context, awsClient awsclient.Client) (map[string]sets.Set[string], error) {
+	klog.V(3).Info("Refreshing instance type offerings cache")
+
+	if awsClient == nil {
+		return nil, errors.New("awsClient is nil")
+	}
+
+	input := ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+	}
+	offerings := make(map[string]sets.Set[string])
+
+	// AWS API paginates responses, so we need to loop until we get all the results
+	for {
+		rawOfferings, err := awsClient.DescribeInstanceTypeOfferings(ctx, &input)
+		if err != nil {
+			return nil, fmt.Errorf("describeInstanceTypeOfferings request failed: %w", err)
+		}
+		for _, offering := range rawOfferings.InstanceTypeOfferings {
+			zone := aws.StringValue(offering.Location)
+			if offerings[zone] == nil {
+				offerings[zone] = sets.New[string]()
+			}
+			offerings[zone].Insert(aws.StringValue(offering.InstanceType))
+		}
+
+		// If next token is empty, we have all the results
+		if rawOfferings.NextToken == nil {
+			break
+		}
+		input.NextToken = rawOfferings.NextToken
+	}
+
+	if len(offerings) == 0 {
+		return nil, errors.New("unable to load EC2 Instance Type Offerings list")
+	}
+
+	return offerings, nil
+}
+
 // transformInstanceType takes information we care about from ec2.InstanceTypeInfo and transforms it into InstanceType.
 func transformInstanceType(rawInstanceType *ec2.InstanceTypeInfo) InstanceType {
 	instanceType := InstanceType{
@@ -176,7 +418,31 @@ func transformInstanceType(rawInstanceType *ec2.InstanceTypeInfo) InstanceType {
 	}
 	if rawInstanceType.GpuInfo != nil && len(rawInstanceType.GpuInfo.Gpus) > 0 {
 		instanceType.GPU = getGpuCount(rawInstanceType.GpuInfo)
+		if gpu := rawInstanceType.GpuInfo.Gpus[0]; gpu != nil {
+			instanceType.GPUManufacturer = aws.StringValue(gpu.Manufacturer)
+			instanceType.GPUModel = aws.StringValue(gpu.Name)
+		}
+	}
+	if rawInstanceType.InstanceStorageInfo != nil {
+		instanceType.InstanceStorageGB = aws.Int64Value(rawInstanceType.InstanceStorageInfo.TotalSizeInGB)
+		instanceType.LocalNVMe = instanceType.InstanceStorageGB > 0 &&
+			aws.StringValue(rawInstanceType.InstanceStorageInfo.NvmeSupport) != ec2.EphemeralNvmeSupportUnsupported
 	}
+	if rawInstanceType.NetworkInfo != nil {
+		instanceType.EFASupported = aws.BoolValue(rawInstanceType.NetworkInfo.EfaSupported)
+		var bandwidthGbps float64
+		for _, card := range rawInstanceType.NetworkInfo.NetworkCards {
+			if card != nil {
+				bandwidthGbps += aws.Float64Value(card.BaselineBandwidthInGbps)
+			}
+		}
+		instanceType.NetworkBandwidthMbps = int64(math.Round(bandwidthGbps * 1000))
+	}
+	instanceType.Burstable = aws.BoolValue(rawInstanceType.BurstablePerformanceSupported)
+	if rawInstanceType.ProcessorInfo != nil {
+		instanceType.CPUManufacturer = aws.StringValue(rawInstanceType.ProcessorInfo.Manufacturer)
+	}
+	setAccelerators(&instanceType, rawInstanceType)
 	if rawInstanceType.ProcessorInfo != nil && len(rawInstanceType.ProcessorInfo.SupportedArchitectures) > 0 &&
 		rawInstanceType.ProcessorInfo.SupportedArchitectures[0] != nil && *rawInstanceType.ProcessorInfo.SupportedArchitectures[0] != "" {
 		instanceType.CPUArchitecture = normalizeArchitecture(*rawInstanceType.ProcessorInfo.SupportedArchitectures[0])
@@ -186,6 +452,39 @@ func transformInstanceType(rawInstanceType *ec2.InstanceTypeInfo) InstanceType {
 	return instanceType
 }
 
+// setAccelerators sets the machine learning accelerators of the instance type: Neuron devices, i.e. Trainium and
+// Inferentia2, or the inference accelerators of the first Inferentia generation.
+func setAccelerators(instanceType *InstanceType, rawInstanceType *ec2.InstanceTypeInfo) {
+	if rawInstanceType.NeuronInfo != nil {
+		for _, device := range rawInstanceType.NeuronInfo.NeuronDevices {
+			if device == nil {
+				continue
+			}
+			if instanceType.AcceleratorName == "" {
+				// Neuron devices are only made by AWS, the API does not report their manufacturer.
+				instanceType.AcceleratorManufacturer = "AWS"
+				instanceType.AcceleratorName = aws.StringValue(device.Name)
+			}
+			instanceType.AcceleratorCount += aws.Int64Value(device.Count)
+		}
+		if instanceType.AcceleratorCount > 0 {
+			return
+		}
+	}
+	if rawInstanceType.InferenceAcceleratorInfo != nil {
+		for _, accelerator := range rawInstanceType.InferenceAcceleratorInfo.Accelerators {
+			if accelerator == nil {
+				continue
+			}
+			if instanceType.AcceleratorName == "" {
+				instanceType.AcceleratorManufacturer = aws.StringValue(accelerator.Manufacturer)
+				instanceType.AcceleratorName = aws.StringValue(accelerator.Name)
+			}
+			instanceType.AcceleratorCount += aws.Int64Value(accelerator.Count)
+		}
+	}
+}
+
 // getGpuCount counts all the GPUs in GpuInfo.
 func getGpuCount(gpuInfo *ec2.GpuInfo) int64 {
 	gpuCountSum := int64(0)
@@ -0,0 +1,176 @@
+package machineset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// instanceTypeResolver resolves instance requirements by filtering the cached instance types of the region,
+// and ranks matching types by the on-demand prices of the aws-on-demand-prices ConfigMap when it lists them all.
+type instanceTypeResolver struct {
+	instanceTypes InstanceTypesCache
+	prices        PriceCache
+}
+
+// NewInstanceTypeResolver creates an instance type resolver over the instance types cache. Instance types are ranked
+// by on-demand price if prices is not nil and the aws-on-demand-prices ConfigMap lists all the matching types,
+// otherwise by vCPUs and memory.
+func NewInstanceTypeResolver(instanceTypes InstanceTypesCache, prices PriceCache) utils.InstanceTypeResolver {
+	return &instanceTypeResolver{
+		instanceTypes: instanceTypes,
+		prices:        prices,
+	}
+}
+
+// ResolveInstanceType implements InstanceTypeResolver
+func (r *instanceTypeResolver) ResolveInstanceType(ctx context.Context, awsClient awsclient.Client, namespace, region, zone string, requirements utils.InstanceRequirements) (string, error) {
+	instanceTypes, err := r.instanceTypes.ListInstanceTypes(ctx, awsClient, region)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []InstanceType{}
+	for _, instanceType := range instanceTypes {
+		if !matchesInstanceRequirements(instanceType, requirements) {
+			continue
+		}
+		if zone != "" {
+			offered, err := r.instanceTypes.IsInstanceTypeOffered(ctx, awsClient, region, instanceType.InstanceType, zone)
+			if err != nil {
+				return "", err
+			}
+			if !offered {
+				continue
+			}
+		}
+		candidates = append(candidates, instanceType)
+	}
+	if len(candidates) == 0 {
+		if zone != "" {
+			return "", fmt.Errorf("%w in availability zone %s", utils.ErrNoMatchingInstanceType, zone)
+		}
+		return "", fmt.Errorf("%w in region %s", utils.ErrNoMatchingInstanceType, region)
+	}
+
+	prices := map[string]float64{}
+	if r.prices != nil {
+		prices, err = r.prices.OnDemandPrices(ctx, namespace, region)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// The ConfigMap may not list every instance type. Ranking unlisted instance types after listed ones would pick
+	// a larger listed type over a smaller unlisted one, so prices only rank candidates of the same size unless all
+	// of them are listed.
+	byPrice := true
+	for _, candidate := range candidates {
+		if _, listed := prices[candidate.InstanceType]; !listed {
+			byPrice = false
+			break
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return isCheaper(prices, candidates[i], candidates[j], byPrice)
+	})
+	klog.V(3).Infof("Resolved instance requirements to instance type %s among %d candidates", candidates[0].InstanceType, len(candidates))
+	return candidates[0].InstanceType, nil
+}
+
+// isCheaper returns whether a ranks before b: by on-demand price if byPrice is set, otherwise by vCPUs and memory,
+// then among instance types of the same size by on-demand price, listed ones first, and by name so that the order
+// is stable.
+func isCheaper(prices map[string]float64, a, b InstanceType, byPrice bool) bool {
+	priceA, listedA := prices[a.InstanceType]
+	priceB, listedB := prices[b.InstanceType]
+	if byPrice && priceA != priceB {
+		return priceA < priceB
+	}
+	if a.VCPU != b.VCPU {
+		return a.VCPU < b.VCPU
+	}
+	if a.MemoryMb != b.MemoryMb {
+		return a.MemoryMb < b.MemoryMb
+	}
+	if listedA != listedB {
+		return listedA
+	}
+	if listedA && priceA != priceB {
+		return priceA < priceB
+	}
+	return a.InstanceType < b.InstanceType
+}
+
+// matchesInstanceRequirements returns whether the instance type has all the required attributes.
+func matchesInstanceRequirements(instanceType InstanceType, requirements utils.InstanceRequirements) bool {
+	if !requirements.VCPU.Contains(instanceType.VCPU) || !requirements.MemoryMiB.Contains(instanceType.MemoryMb) || !requirements.GPU.Contains(instanceType.GPU) {
+		return false
+	}
+	if requirements.Architecture != "" && requirements.Architecture != string(instanceType.CPUArchitecture) {
+		return false
+	}
+	if requirements.Burstable != nil && *requirements.Burstable != instanceType.Burstable {
+		return false
+	}
+
+	family, _, _ := strings.Cut(instanceType.InstanceType, ".")
+	for _, excluded := range requirements.ExcludedFamilies {
+		if family == excluded {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveInstanceType sets the instance type of the provider config of MachineSets with instance requirements in
+// their template to the type their Machines would currently resolve to, overriding the instance type of the provider
+// config like Machines do, and records it on the MachineSet.
+// It returns false if the requirements cannot be resolved, after reflecting it in the InstanceTypeValid condition.
+func (r *Reconciler) resolveInstanceType(ctx context.Context, awsClient awsclient.Client, machineSet *machinev1beta1.MachineSet, providerConfig *machinev1beta1.AWSMachineProviderConfig) (bool, error) {
+	requirements, err := utils.GetInstanceRequirements(machineSet.Spec.Template.Annotations)
+	if err != nil {
+		return false, err
+	}
+	if requirements == nil {
+		if machineSet.Annotations != nil {
+			delete(machineSet.Annotations, utils.ResolvedInstanceTypeAnnotation)
+		}
+		return true, nil
+	}
+	if providerConfig.InstanceType != "" {
+		klog.V(3).Infof("%v: instance type %s of the provider spec is overridden by the %s annotation", machineSet.Name, providerConfig.InstanceType, utils.InstanceRequirementsAnnotation)
+	}
+
+	instanceType, err := NewInstanceTypeResolver(r.InstanceTypesCache, r.Prices).ResolveInstanceType(ctx, awsClient, machineSet.Namespace, providerConfig.Placement.Region, providerConfig.Placement.AvailabilityZone, *requirements)
+	if errors.Is(err, utils.ErrNoMatchingInstanceType) {
+		if machineSet.Annotations != nil {
+			delete(machineSet.Annotations, utils.ResolvedInstanceTypeAnnotation)
+		}
+		r.setInstanceTypeValidCondition(machineSet, conditions.FalseCondition(InstanceTypeValidCondition, instanceRequirementsUnsatisfiableReason,
+			machinev1beta1.ConditionSeverityError, "%v", err))
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error resolving instance requirements: %w", err)
+	}
+
+	if machineSet.Annotations == nil {
+		machineSet.Annotations = make(map[string]string)
+	}
+	if previous := machineSet.Annotations[utils.ResolvedInstanceTypeAnnotation]; previous != instanceType {
+		r.recorder.Eventf(machineSet, corev1.EventTypeNormal, "InstanceTypeResolved", "Instance requirements resolved to instance type %s", instanceType)
+	}
+	machineSet.Annotations[utils.ResolvedInstanceTypeAnnotation] = instanceType
+	providerConfig.InstanceType = instanceType
+	return true, nil
+}
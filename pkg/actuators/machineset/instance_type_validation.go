@@ -0,0 +1,116 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// InstanceTypeValidCondition reports whether Machines of the MachineSet can be launched with the instance type
+	// of the template in its availability zone and subnet, and with its AMI.
+	InstanceTypeValidCondition machinev1beta1.ConditionType = "InstanceTypeValid"
+
+	instanceTypeUnknownReason      = "InstanceTypeUnknown"
+	instanceTypesUnavailableReason = "InstanceTypesUnavailable"
+	instanceTypeNotOfferedReason   = "InstanceTypeNotOffered"
+	subnetZoneMismatchReason       = "SubnetZoneMismatch"
+	architectureMismatchReason     = "ArchitectureMismatch"
+
+	instanceRequirementsUnsatisfiableReason = "InstanceRequirementsUnsatisfiable"
+)
+
+// validateInstanceType checks that Machines of the MachineSet can be launched: the instance type must be offered in
+// the availability zone, either configured or that of the subnet, the subnet must be in the configured availability
+// zone, and the architecture of the AMI must match that of the instance type.
+// Checks are skipped when the information they need is not configured, e.g. when the subnet is selected by filters.
+// The availability zone Machines are launched in is returned along with the condition, if it is known.
+func (r *Reconciler) validateInstanceType(ctx context.Context, awsClient awsclient.Client, providerConfig *machinev1beta1.AWSMachineProviderConfig, instanceType InstanceType) (*machinev1beta1.Condition, string, error) {
+	zone := providerConfig.Placement.AvailabilityZone
+
+	if subnetID := aws.StringValue(providerConfig.Subnet.ID); subnetID != "" {
+		subnetZone, err := getSubnetZone(ctx, awsClient, subnetID)
+		if err != nil {
+			return nil, "", err
+		}
+		if zone != "" && subnetZone != "" && zone != subnetZone {
+			return conditions.FalseCondition(InstanceTypeValidCondition, subnetZoneMismatchReason, machinev1beta1.ConditionSeverityError,
+				"subnet %s is in availability zone %s, not in %s", subnetID, subnetZone, zone), zone, nil
+		}
+		if zone == "" {
+			zone = subnetZone
+		}
+	}
+
+	if zone != "" {
+		offered, err := r.InstanceTypesCache.IsInstanceTypeOffered(ctx, awsClient, providerConfig.Placement.Region, instanceType.InstanceType, zone)
+		if err != nil {
+			return nil, "", err
+		}
+		if !offered {
+			return conditions.FalseCondition(InstanceTypeValidCondition, instanceTypeNotOfferedReason, machinev1beta1.ConditionSeverityError,
+				"instance type %s is not offered in availability zone %s", instanceType.InstanceType, zone), zone, nil
+		}
+	}
+
+	if amiID := aws.StringValue(providerConfig.AMI.ID); amiID != "" {
+		architecture, err := getImageArchitecture(ctx, awsClient, amiID)
+		if err != nil {
+			return nil, "", err
+		}
+		if architecture != "" && architecture != instanceType.CPUArchitecture {
+			return conditions.FalseCondition(InstanceTypeValidCondition, architectureMismatchReason, machinev1beta1.ConditionSeverityError,
+				"AMI %s is built for %s, but instance type %s is %s", amiID, architecture, instanceType.InstanceType, instanceType.CPUArchitecture), zone, nil
+		}
+	}
+
+	return conditions.TrueCondition(InstanceTypeValidCondition), zone, nil
+}
+
+// setInstanceTypeValidCondition sets the condition on the MachineSet, and emits an event when it becomes false.
+func (r *Reconciler) setInstanceTypeValidCondition(machineSet *machinev1beta1.MachineSet, condition *machinev1beta1.Condition) {
+	previous := conditions.Get(machineSet, InstanceTypeValidCondition)
+	if condition.Status == corev1.ConditionFalse && (previous == nil || previous.Status != corev1.ConditionFalse || previous.Reason != condition.Reason) {
+		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, condition.Reason, "Machines cannot be launched: %s", condition.Message)
+	}
+	conditions.Set(machineSet, condition)
+}
+
+// getSubnetZone returns the availability zone of the subnet.
+func getSubnetZone(ctx context.Context, awsClient awsclient.Client, subnetID string) (string, error) {
+	output, err := awsClient.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: []*string{aws.String(subnetID)}})
+	if err != nil {
+		return "", fmt.Errorf("error describing subnet %s: %w", subnetID, err)
+	}
+	if len(output.Subnets) == 0 {
+		return "", fmt.Errorf("subnet %s not found", subnetID)
+	}
+	return aws.StringValue(output.Subnets[0].AvailabilityZone), nil
+}
+
+// getImageArchitecture returns the normalized architecture of the AMI,
+// or an empty string if it has no architecture supported by the Machine API.
+func getImageArchitecture(ctx context.Context, awsClient awsclient.Client, amiID string) (normalizedArch, error) {
+	output, err := awsClient.DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []*string{aws.String(amiID)}})
+	if err != nil {
+		return "", fmt.Errorf("error describing AMI %s: %w", amiID, err)
+	}
+	if len(output.Images) == 0 {
+		return "", fmt.Errorf("AMI %s not found", amiID)
+	}
+
+	// Mac AMIs report e.g. arm64_mac, they run on the same architecture.
+	switch architecture := strings.TrimSuffix(aws.StringValue(output.Images[0].Architecture), "_mac"); architecture {
+	case ec2.ArchitectureTypeX8664, ec2.ArchitectureTypeArm64:
+		return normalizeArchitecture(architecture), nil
+	default:
+		return "", nil
+	}
+}
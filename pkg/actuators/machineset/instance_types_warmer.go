@@ -0,0 +1,220 @@
+package machineset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InstanceTypesSnapshotConfigMapName is the name of the ConfigMap, in the namespace of the MachineSets,
+	// the instance types cache is snapshotted to. It holds a gzipped JSON snapshot per region.
+	InstanceTypesSnapshotConfigMapName = "aws-instance-types-cache"
+
+	// DefaultInstanceTypesWarmInterval is the default interval at which the instance types cache is warmed.
+	DefaultInstanceTypesWarmInterval = time.Hour
+
+	// instanceTypesRefreshAge is the age after which instance types are refreshed in the background,
+	// ahead of their expiry so that reconciles do not have to wait for them.
+	instanceTypesRefreshAge = instanceTypesCacheTTL * 3 / 4
+)
+
+// InstanceTypesCacheWarmer fills the instance types cache in the background for every region MachineSets are in,
+// and refreshes it ahead of expiry. The cache is snapshotted to a ConfigMap, and restored from it on startup,
+// so that restarts and EC2 API outages still give scale-from-zero data.
+type InstanceTypesCacheWarmer struct {
+	Client              client.Client
+	AwsClientBuilder    awsclient.AwsClientBuilderFuncType
+	RegionCache         awsclient.RegionCache
+	ConfigManagedClient client.Client
+	InstanceTypesCache  InstanceTypesCache
+	Interval            time.Duration
+
+	// APIReader reads the snapshot ConfigMap, uncached so that the manager does not watch ConfigMaps cluster-wide.
+	// The snapshot ConfigMap, in the namespace of the MachineSets, is created and updated with Client.
+	APIReader client.Reader
+
+	// snapshots holds the last update time of the instance types snapshotted for each region.
+	// Regions are restored from their snapshot the first time they are warmed.
+	snapshots map[string]time.Time
+}
+
+// Start implements manager.Runnable, warming the cache at the interval until the context is done.
+func (w *InstanceTypesCacheWarmer) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, w.warm, w.Interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader reconciles MachineSets.
+func (w *InstanceTypesCacheWarmer) NeedLeaderElection() bool {
+	return true
+}
+
+// warm restores, refreshes and snapshots the instance types of each region MachineSets are in.
+func (w *InstanceTypesCacheWarmer) warm(ctx context.Context) {
+	if w.snapshots == nil {
+		w.snapshots = map[string]time.Time{}
+	}
+
+	machineSets := &machinev1beta1.MachineSetList{}
+	if err := w.Client.List(ctx, machineSets); err != nil {
+		klog.Errorf("Unable to warm instance types cache: failed to list MachineSets: %v", err)
+		return
+	}
+
+	for _, machineSet := range regionMachineSets(machineSets.Items) {
+		if err := w.warmRegion(ctx, machineSet); err != nil {
+			klog.Errorf("Unable to warm instance types cache of %s: %v", machineSet.region, err)
+		}
+	}
+}
+
+// warmRegion restores the instance types of the region from its snapshot if it was not yet, refreshes them
+// if they are due and snapshots them if they changed.
+func (w *InstanceTypesCacheWarmer) warmRegion(ctx context.Context, machineSet regionMachineSet) error {
+	if _, restored := w.snapshots[machineSet.region]; !restored {
+		snapshot, err := w.loadSnapshot(ctx, machineSet.namespace, machineSet.region)
+		if err != nil {
+			klog.Warningf("Unable to restore instance types cache of %s: %v", machineSet.region, err)
+		}
+		if snapshot != nil {
+			klog.V(3).Infof("Restoring instance types cache of %s from snapshot of %s", machineSet.region, snapshot.LastUpdate.Format(time.RFC3339))
+			w.InstanceTypesCache.Restore(machineSet.region, *snapshot)
+			w.snapshots[machineSet.region] = snapshot.LastUpdate
+		} else {
+			w.snapshots[machineSet.region] = time.Time{}
+		}
+	}
+
+	awsClient, err := w.AwsClientBuilder(ctx, w.Client, machineSet.credentialsSecret, machineSet.namespace, machineSet.region, w.ConfigManagedClient, w.RegionCache)
+	if err != nil {
+		return fmt.Errorf("error creating aws client: %w", err)
+	}
+	if err := w.InstanceTypesCache.Warm(ctx, awsClient, machineSet.region, instanceTypesRefreshAge); err != nil {
+		return err
+	}
+
+	snapshot, ok := w.InstanceTypesCache.Snapshot(machineSet.region)
+	if !ok || !snapshot.LastUpdate.After(w.snapshots[machineSet.region]) {
+		return nil
+	}
+	if err := w.saveSnapshot(ctx, machineSet.namespace, machineSet.region, snapshot); err != nil {
+		return fmt.Errorf("error saving snapshot: %w", err)
+	}
+	w.snapshots[machineSet.region] = snapshot.LastUpdate
+	return nil
+}
+
+// loadSnapshot returns the snapshot of the region, or nil if there is none.
+func (w *InstanceTypesCacheWarmer) loadSnapshot(ctx context.Context, namespace, region string) (*InstanceTypesSnapshot, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := w.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: InstanceTypesSnapshotConfigMapName}, configMap); apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting %s ConfigMap: %w", InstanceTypesSnapshotConfigMapName, err)
+	}
+
+	compressed, ok := configMap.BinaryData[snapshotKey(region)]
+	if !ok {
+		return nil, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing snapshot: %w", err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing snapshot: %w", err)
+	}
+
+	snapshot := &InstanceTypesSnapshot{}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// saveSnapshot stores the snapshot of the region in the ConfigMap, creating it if needed.
+func (w *InstanceTypesCacheWarmer) saveSnapshot(ctx context.Context, namespace, region string, snapshot InstanceTypesSnapshot) error {
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	if _, err := writer.Write(content); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = w.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: InstanceTypesSnapshotConfigMapName}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: InstanceTypesSnapshotConfigMapName},
+			BinaryData: map[string][]byte{snapshotKey(region): compressed.Bytes()},
+		}
+		return w.Client.Create(ctx, configMap)
+	} else if err != nil {
+		return err
+	}
+
+	if configMap.BinaryData == nil {
+		configMap.BinaryData = map[string][]byte{}
+	}
+	configMap.BinaryData[snapshotKey(region)] = compressed.Bytes()
+	return w.Client.Update(ctx, configMap)
+}
+
+// snapshotKey returns the key of the snapshot of the region in the ConfigMap.
+func snapshotKey(region string) string {
+	return fmt.Sprintf("%s.json.gz", region)
+}
+
+// regionMachineSet identifies a MachineSet whose credentials are used to warm the cache of its region.
+type regionMachineSet struct {
+	region            string
+	namespace         string
+	credentialsSecret string
+}
+
+// regionMachineSets returns a MachineSet for each region MachineSets are in.
+func regionMachineSets(machineSets []machinev1beta1.MachineSet) []regionMachineSet {
+	regions := map[string]bool{}
+	result := []regionMachineSet{}
+	for _, machineSet := range machineSets {
+		if !machineSet.DeletionTimestamp.IsZero() {
+			continue
+		}
+		providerConfig, err := utils.ProviderSpecFromRawExtension(machineSet.Spec.Template.Spec.ProviderSpec.Value)
+		if err != nil || providerConfig.CredentialsSecret == nil || providerConfig.Placement.Region == "" {
+			continue
+		}
+		if regions[providerConfig.Placement.Region] {
+			continue
+		}
+		regions[providerConfig.Placement.Region] = true
+		result = append(result, regionMachineSet{
+			region:            providerConfig.Placement.Region,
+			namespace:         machineSet.Namespace,
+			credentialsSecret: providerConfig.CredentialsSecret.Name,
+		})
+	}
+	return result
+}
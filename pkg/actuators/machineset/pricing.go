@@ -0,0 +1,186 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// HourlyPriceAnnotation is set on MachineSets to the current hourly price in USD of their instance type:
+	// the latest spot price in their availability zone for spot MachineSets, the on-demand price otherwise.
+	HourlyPriceAnnotation = "machine.openshift.io/hourly-price"
+
+	// SpotMaxPriceAnnotation is set on spot MachineSets to their configured maximum hourly price in USD.
+	SpotMaxPriceAnnotation = "machine.openshift.io/spot-max-price"
+
+	// OnDemandPricesConfigMapName is the name of the ConfigMap, in the namespace of the Machines and MachineSets,
+	// listing on-demand prices. Its keys are <region>.<instance type>, e.g. us-east-1.m5.large, and its values hourly
+	// prices in USD. It is generated by hack/generate-on-demand-prices.sh, on-demand prices are unknown without it.
+	OnDemandPricesConfigMapName = "aws-on-demand-prices"
+
+	// DefaultPriceRefreshInterval is the default interval at which spot prices are refreshed.
+	DefaultPriceRefreshInterval = time.Hour
+
+	spotPriceProductDescription = "Linux/UNIX"
+)
+
+// PriceCache provides the hourly prices of instance types.
+type PriceCache interface {
+	// SpotPrice returns the latest spot price of the instance type in the availability zone,
+	// refreshing it from the EC2 API once it is older than the refresh interval.
+	SpotPrice(ctx context.Context, awsClient awsclient.Client, region, zone, instanceType string) (float64, error)
+	// OnDemandPrices returns the on-demand prices of the instance types of the region listed in the
+	// aws-on-demand-prices ConfigMap of the namespace, none if there is no such ConfigMap.
+	OnDemandPrices(ctx context.Context, namespace, region string) (map[string]float64, error)
+	// RefreshInterval returns the interval at which prices are refreshed.
+	RefreshInterval() time.Duration
+}
+
+// priceCache holds the spot prices fetched per region, zone and instance type, and reads on-demand prices from
+// the aws-on-demand-prices ConfigMaps.
+type priceCache struct {
+	reader     client.Reader
+	spotPrices *valueCache
+}
+
+// NewPriceCache creates a price cache refreshing spot prices at the given interval. The reader should be uncached,
+// so that the manager does not watch ConfigMaps.
+func NewPriceCache(refreshInterval time.Duration, reader client.Reader) PriceCache {
+	return &priceCache{
+		reader:     reader,
+		spotPrices: newValueCache(refreshInterval),
+	}
+}
+
+// SpotPrice implements PriceCache.
+// Failures are logged once per fetch, as they are cached until the next refresh.
+func (p *priceCache) SpotPrice(ctx context.Context, awsClient awsclient.Client, region, zone, instanceType string) (float64, error) {
+	return p.spotPrices.get(fmt.Sprintf("%s/%s/%s", region, zone, instanceType), func() (float64, error) {
+		price, err := fetchSpotPrice(ctx, awsClient, zone, instanceType)
+		if err != nil {
+			klog.Warningf("Unable to get spot price of instance type %s in %s: %v", instanceType, zone, err)
+		}
+		return price, err
+	})
+}
+
+// OnDemandPrices implements PriceCache
+func (p *priceCache) OnDemandPrices(ctx context.Context, namespace, region string) (map[string]float64, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := p.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: OnDemandPricesConfigMapName}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return map[string]float64{}, nil
+		}
+		return nil, fmt.Errorf("error getting %s ConfigMap: %w", OnDemandPricesConfigMapName, err)
+	}
+
+	prices := map[string]float64{}
+	for key, value := range configMap.Data {
+		instanceType, ok := strings.CutPrefix(key, region+".")
+		if !ok {
+			continue
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price %q for %s in %s ConfigMap: %w", value, instanceType, OnDemandPricesConfigMapName, err)
+		}
+		prices[instanceType] = price
+	}
+	return prices, nil
+}
+
+// RefreshInterval implements PriceCache
+func (p *priceCache) RefreshInterval() time.Duration {
+	return p.spotPrices.refreshInterval
+}
+
+// fetchSpotPrice fetches the latest spot price of the instance type in the availability zone from the EC2 API.
+func fetchSpotPrice(ctx context.Context, awsClient awsclient.Client, zone, instanceType string) (float64, error) {
+	klog.V(3).Infof("Fetching spot price of %s in %s", instanceType, zone)
+
+	// With a start time of now, only the price in effect is returned.
+	output, err := awsClient.DescribeSpotPriceHistory(ctx, &ec2.DescribeSpotPriceHistoryInput{
+		AvailabilityZone:    aws.String(zone),
+		InstanceTypes:       []*string{aws.String(instanceType)},
+		ProductDescriptions: []*string{aws.String(spotPriceProductDescription)},
+		StartTime:           aws.Time(time.Now()),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("describeSpotPriceHistory request failed: %w", err)
+	}
+
+	var latest *ec2.SpotPrice
+	for _, price := range output.SpotPriceHistory {
+		if latest == nil || aws.TimeValue(price.Timestamp).After(aws.TimeValue(latest.Timestamp)) {
+			latest = price
+		}
+	}
+	if latest == nil {
+		return 0, fmt.Errorf("no spot price for instance type %s in availability zone %s", instanceType, zone)
+	}
+
+	price, err := strconv.ParseFloat(aws.StringValue(latest.SpotPrice), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid spot price %q: %w", aws.StringValue(latest.SpotPrice), err)
+	}
+	return price, nil
+}
+
+// setPriceAnnotations annotates the MachineSet with the hourly price of its instance type, and its maximum price
+// for spot MachineSets. Prices which are unknown, including spot prices which cannot be fetched, are removed.
+// The MachineSet is requeued so that prices follow the refresh interval.
+func (r *Reconciler) setPriceAnnotations(ctx context.Context, awsClient awsclient.Client, machineSet *machinev1beta1.MachineSet, providerConfig *machinev1beta1.AWSMachineProviderConfig, zone string) (ctrl.Result, error) {
+	if machineSet.Annotations == nil {
+		machineSet.Annotations = make(map[string]string)
+	}
+	region := providerConfig.Placement.Region
+
+	var price float64
+	var known bool
+	if isSpot(providerConfig) {
+		if providerConfig.SpotMarketOptions != nil && aws.StringValue(providerConfig.SpotMarketOptions.MaxPrice) != "" {
+			machineSet.Annotations[SpotMaxPriceAnnotation] = aws.StringValue(providerConfig.SpotMarketOptions.MaxPrice)
+		} else {
+			delete(machineSet.Annotations, SpotMaxPriceAnnotation)
+		}
+
+		if zone != "" {
+			spotPrice, err := r.Prices.SpotPrice(ctx, awsClient, region, zone, providerConfig.InstanceType)
+			if err != nil {
+				klog.V(3).Infof("%v: spot price of instance type %s in %s is unknown: %v", machineSet.Name, providerConfig.InstanceType, zone, err)
+			} else {
+				price, known = spotPrice, true
+			}
+		}
+	} else {
+		delete(machineSet.Annotations, SpotMaxPriceAnnotation)
+
+		onDemandPrices, err := r.Prices.OnDemandPrices(ctx, machineSet.Namespace, region)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		price, known = onDemandPrices[providerConfig.InstanceType]
+	}
+
+	if !known {
+		klog.V(3).Infof("%v: hourly price of instance type %s is unknown", machineSet.Name, providerConfig.InstanceType)
+		delete(machineSet.Annotations, HourlyPriceAnnotation)
+	} else {
+		machineSet.Annotations[HourlyPriceAnnotation] = strconv.FormatFloat(price, 'f', -1, 64)
+	}
+
+	return ctrl.Result{RequeueAfter: r.Prices.RefreshInterval()}, nil
+}
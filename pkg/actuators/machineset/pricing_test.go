@@ -0,0 +1,160 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetPriceAnnotations(t *testing.T) {
+	spotPrices := &ec2.DescribeSpotPriceHistoryOutput{
+		SpotPriceHistory: []*ec2.SpotPrice{
+			{InstanceType: aws.String("m5.large"), SpotPrice: aws.String("0.035000"), Timestamp: aws.Time(time.Now().Add(-2 * time.Hour))},
+			{InstanceType: aws.String("m5.large"), SpotPrice: aws.String("0.041200"), Timestamp: aws.Time(time.Now().Add(-time.Hour))},
+		},
+	}
+	onDemandPrices := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: OnDemandPricesConfigMapName},
+		Data:       map[string]string{"us-east-1.m5.large": "0.096", "us-west-2.m5.xlarge": "0.192"},
+	}
+
+	testCases := []struct {
+		name                string
+		providerConfig      *machinev1beta1.AWSMachineProviderConfig
+		zone                string
+		spotPriceErr        error
+		existingAnnotations map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name: "on-demand price from the ConfigMap",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				InstanceType: "m5.large",
+				Placement:    machinev1beta1.Placement{Region: "us-east-1"},
+			},
+			expectedAnnotations: map[string]string{HourlyPriceAnnotation: "0.096"},
+		},
+		{
+			name: "on-demand price listed for another region only",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				InstanceType: "m5.xlarge",
+				Placement:    machinev1beta1.Placement{Region: "us-east-1"},
+			},
+			existingAnnotations: map[string]string{HourlyPriceAnnotation: "0.192"},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name: "unknown on-demand price",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				InstanceType: "m5.large",
+				Placement:    machinev1beta1.Placement{Region: "eu-central-1"},
+			},
+			existingAnnotations: map[string]string{HourlyPriceAnnotation: "0.1", SpotMaxPriceAnnotation: "0.05"},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name: "spot price and max price",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				InstanceType:      "m5.large",
+				Placement:         machinev1beta1.Placement{Region: "us-east-1"},
+				SpotMarketOptions: &machinev1beta1.SpotMarketOptions{MaxPrice: aws.String("0.05")},
+			},
+			zone:                "us-east-1a",
+			expectedAnnotations: map[string]string{HourlyPriceAnnotation: "0.0412", SpotMaxPriceAnnotation: "0.05"},
+		},
+		{
+			name: "spot price which cannot be fetched",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				InstanceType: "m5.large",
+				Placement:    machinev1beta1.Placement{Region: "us-east-1"},
+				MarketType:   machinev1beta1.MarketTypeSpot,
+			},
+			zone:                "us-east-1a",
+			spotPriceErr:        fmt.Errorf("access denied"),
+			existingAnnotations: map[string]string{HourlyPriceAnnotation: "0.1"},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name: "spot price without a zone",
+			providerConfig: &machinev1beta1.AWSMachineProviderConfig{
+				InstanceType: "m5.large",
+				Placement:    machinev1beta1.Placement{Region: "us-east-1"},
+				MarketType:   machinev1beta1.MarketTypeSpot,
+			},
+			existingAnnotations: map[string]string{HourlyPriceAnnotation: "0.1"},
+			expectedAnnotations: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			mockAWSClient := mockaws.NewMockClient(mockCtrl)
+			if tc.spotPriceErr != nil {
+				mockAWSClient.EXPECT().DescribeSpotPriceHistory(gomock.Any(), gomock.Any()).Return(nil, tc.spotPriceErr)
+			} else if tc.zone != "" {
+				mockAWSClient.EXPECT().DescribeSpotPriceHistory(gomock.Any(), gomock.Any()).Return(spotPrices, nil)
+			}
+
+			r := Reconciler{
+				Prices: NewPriceCache(DefaultPriceRefreshInterval, fake.NewClientBuilder().WithObjects(onDemandPrices.DeepCopy()).Build()),
+			}
+			machineSet := &machinev1beta1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", Annotations: tc.existingAnnotations},
+			}
+
+			result, err := r.setPriceAnnotations(context.TODO(), mockAWSClient, machineSet, tc.providerConfig, tc.zone)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(DefaultPriceRefreshInterval))
+			g.Expect(machineSet.Annotations).To(Equal(tc.expectedAnnotations))
+		})
+	}
+}
+
+func TestPriceCacheSpotPrice(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+	mockAWSClient.EXPECT().DescribeSpotPriceHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+			g.Expect(aws.StringValue(input.AvailabilityZone)).To(Equal("us-east-1a"))
+			g.Expect(aws.StringValueSlice(input.InstanceTypes)).To(ConsistOf("m5.large"))
+			return &ec2.DescribeSpotPriceHistoryOutput{
+				SpotPriceHistory: []*ec2.SpotPrice{{SpotPrice: aws.String("0.04"), Timestamp: aws.Time(time.Now())}},
+			}, nil
+		}).Times(1)
+
+	prices := NewPriceCache(time.Hour, nil)
+	for i := 0; i < 2; i++ {
+		price, err := prices.SpotPrice(context.TODO(), mockAWSClient, "us-east-1", "us-east-1a", "m5.large")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(price).To(Equal(0.04))
+	}
+}
+
+func TestPriceCacheCachesErrors(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().DescribeSpotPriceHistory(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("access denied")).Times(1)
+
+	prices := NewPriceCache(time.Hour, nil)
+	for i := 0; i < 2; i++ {
+		_, err := prices.SpotPrice(context.TODO(), mockAWSClient, "us-east-1", "us-east-1a", "m5.large")
+		g.Expect(err).To(MatchError(ContainSubstring("access denied")))
+	}
+}
@@ -0,0 +1,334 @@
+package machineset
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Labels describing the instance type, set on the nodes through the Machine template of MachineSets and published in
+// the autoscaler labels annotation so that pods selecting them can trigger a scale from zero. Values are lower case,
+// e.g. intel, amd or aws for the CPU manufacturer, and the network bandwidth is in Mbps. Values set manually on the
+// template are kept, and published instead. Nodes of Machines created before the template changed are not relabelled.
+const (
+	CPUManufacturerLabel         = "machine.openshift.io/instance-cpu-manufacturer"
+	NetworkBandwidthLabel        = "machine.openshift.io/instance-network-bandwidth"
+	LocalNVMeLabel               = "machine.openshift.io/instance-local-nvme"
+	EFASupportedLabel            = "machine.openshift.io/instance-efa-supported"
+	AcceleratorManufacturerLabel = "machine.openshift.io/instance-accelerator-manufacturer"
+	AcceleratorNameLabel         = "machine.openshift.io/instance-accelerator-name"
+	AcceleratorCountLabel        = "machine.openshift.io/instance-accelerator-count"
+)
+
+// scaleFromZeroManagedKey records the optional scale from zero annotations and the labels set by the controller,
+// in the labels annotation and on the nodes of the template, and their values. Only those are replaced or removed
+// when they no longer apply, values populated manually are kept.
+const scaleFromZeroManagedKey = "machine.openshift.io/scale-from-zero-managed"
+
+// instanceTypeLabelKeys are the keys of the labels describing the instance type in the labels annotation.
+var instanceTypeLabelKeys = sets.New(corev1.LabelArchStable, corev1.LabelInstanceTypeStable, CPUManufacturerLabel, NetworkBandwidthLabel,
+	LocalNVMeLabel, EFASupportedLabel, AcceleratorManufacturerLabel, AcceleratorNameLabel, AcceleratorCountLabel)
+
+// gpuResourceNames maps the GPU manufacturers reported by EC2 to the extended resource names of their device plugins.
+var gpuResourceNames = map[string]string{
+	"NVIDIA": "nvidia.com/gpu",
+	"AMD":    "amd.com/gpu",
+}
+
+// scaleFromZeroRecord is the content of the scaleFromZeroManagedKey annotation.
+type scaleFromZeroRecord struct {
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	NodeLabels  map[string]string `json:"nodeLabels,omitempty"`
+}
+
+// getScaleFromZeroRecord returns what the controller recorded on the MachineSet. An invalid record is treated
+// as empty, so that nothing is removed.
+func getScaleFromZeroRecord(machineSet *machinev1beta1.MachineSet) scaleFromZeroRecord {
+	record := scaleFromZeroRecord{}
+	if value, ok := machineSet.Annotations[scaleFromZeroManagedKey]; ok {
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			return scaleFromZeroRecord{}
+		}
+	}
+	return record
+}
+
+// setScaleFromZeroRecord records what the controller set on the MachineSet, or removes the record if it is empty.
+func setScaleFromZeroRecord(machineSet *machinev1beta1.MachineSet, record scaleFromZeroRecord) {
+	if len(record.Annotations) == 0 && len(record.Labels) == 0 && len(record.NodeLabels) == 0 {
+		delete(machineSet.Annotations, scaleFromZeroManagedKey)
+		return
+	}
+	// Marshalling maps of strings can't fail, and sorts their keys.
+	value, _ := json.Marshal(record)
+	machineSet.Annotations[scaleFromZeroManagedKey] = string(value)
+}
+
+// setScaleFromZeroAnnotations annotates the MachineSet with the capacity, labels and taints of its nodes,
+// so that the autoscaler can simulate them when scaling from zero.
+// Annotations are only set for the information known about the instance type and the template. Those previously set
+// by the controller are removed otherwise so that they do not outlive a change of either.
+func setScaleFromZeroAnnotations(machineSet *machinev1beta1.MachineSet, providerConfig *machinev1beta1.AWSMachineProviderConfig, instanceType InstanceType) {
+	if machineSet.Annotations == nil {
+		machineSet.Annotations = make(map[string]string)
+	}
+
+	previous := getScaleFromZeroRecord(machineSet)
+	record := scaleFromZeroRecord{Annotations: map[string]string{}, Labels: map[string]string{}, NodeLabels: map[string]string{}}
+	setOrRemoveAnnotation := func(key, value string) {
+		setOrRemoveManagedAnnotation(machineSet, previous, record, key, value)
+	}
+
+	// TODO: get annotations keys from machine API
+	machineSet.Annotations[cpuKey] = strconv.FormatInt(instanceType.VCPU, 10)
+	machineSet.Annotations[memoryKey] = strconv.FormatInt(instanceType.MemoryMb, 10)
+	machineSet.Annotations[gpuKey] = strconv.FormatInt(instanceType.GPU, 10)
+
+	gpuType, gpuManufacturer, gpuModel := "", "", ""
+	if instanceType.GPU > 0 {
+		gpuType = gpuResourceNames[strings.ToUpper(instanceType.GPUManufacturer)]
+		gpuManufacturer = instanceType.GPUManufacturer
+		gpuModel = instanceType.GPUModel
+	}
+	setOrRemoveAnnotation(gpuTypeKey, gpuType)
+	setOrRemoveAnnotation(gpuManufacturerKey, gpuManufacturer)
+	setOrRemoveAnnotation(gpuModelKey, gpuModel)
+
+	setOrRemoveAnnotation(ephemeralDiskKey, ephemeralDiskCapacity(providerConfig, instanceType))
+
+	// The max pods of the nodes do not depend on the instance type: the pods of OVN-Kubernetes and OpenShift SDN do
+	// not use the IP addresses of the network interfaces of the instance, and the kubelet allows 250 pods. The
+	// annotation computed from the network interface limits by earlier releases is removed.
+	removeManagedAnnotation(machineSet, previous, maxPodsKey)
+
+	setOrRemoveAnnotation(taintsKey, formatTaints(machineSet.Spec.Template.Spec.Taints))
+
+	labels := []string{
+		fmt.Sprintf("kubernetes.io/arch=%s", instanceType.CPUArchitecture),
+		fmt.Sprintf("%s=%s", corev1.LabelInstanceTypeStable, instanceType.InstanceType),
+	}
+	labels = append(labels, setInstanceTypeNodeLabels(machineSet, previous, record, instanceTypeLabels(instanceType))...)
+	if providerConfig.Placement.Region != "" {
+		labels = append(labels, fmt.Sprintf("%s=%s", corev1.LabelTopologyRegion, providerConfig.Placement.Region))
+	}
+	if providerConfig.Placement.AvailabilityZone != "" {
+		labels = append(labels, fmt.Sprintf("%s=%s", corev1.LabelTopologyZone, providerConfig.Placement.AvailabilityZone))
+	}
+	// We guarantee that any existing labels provided via the capacity annotations are preserved.
+	// See https://github.com/kubernetes/autoscaler/pull/5382 and https://github.com/kubernetes/autoscaler/pull/5697
+	// The labels previously set by the controller are replaced though, so that they follow changes of the instance
+	// type and placement, and attribute labels which no longer apply are dropped.
+	existing := withoutManagedLabels(machineSet.Annotations[labelsKey], previous, sets.KeySet(previous.Labels))
+	merged := mergeLabels(strings.Join(labels, ","), strings.Join(existing, ","))
+	machineSet.Annotations[labelsKey] = merged
+	// Labels overridden by existing values were not set by the controller.
+	mergedLabels := strings.Split(merged, ",")
+	for _, label := range labels {
+		if slices.Contains(mergedLabels, label) {
+			key, value, _ := strings.Cut(label, "=")
+			record.Labels[key] = value
+		}
+	}
+
+	setScaleFromZeroRecord(machineSet, record)
+}
+
+// removeStaleScaleFromZeroAnnotations removes the scale from zero annotations set for an instance type other than the
+// given one, e.g. when the instance type of the MachineSet is changed to one which is unknown. Annotations without an
+// instance type label were not set by the controller and are kept, as they may have been populated manually, and so
+// are optional annotations and labels whose value differs from the one recorded by the controller.
+func removeStaleScaleFromZeroAnnotations(machineSet *machinev1beta1.MachineSet, instanceType string) {
+	labels := machineSet.Annotations[labelsKey]
+	if labels == "" {
+		return
+	}
+
+	previousInstanceType := ""
+	for _, label := range strings.Split(labels, ",") {
+		if key, value, ok := strings.Cut(label, "="); ok && key == corev1.LabelInstanceTypeStable {
+			previousInstanceType = value
+		}
+	}
+	if previousInstanceType == "" || previousInstanceType == instanceType {
+		return
+	}
+
+	for _, key := range []string{cpuKey, memoryKey, gpuKey} {
+		delete(machineSet.Annotations, key)
+	}
+	previous := getScaleFromZeroRecord(machineSet)
+	for key := range previous.Annotations {
+		removeManagedAnnotation(machineSet, previous, key)
+	}
+
+	// New nodes no longer get the labels describing the previous instance type.
+	setInstanceTypeNodeLabels(machineSet, previous, scaleFromZeroRecord{NodeLabels: map[string]string{}}, nil)
+	remaining := withoutManagedLabels(labels, previous, instanceTypeLabelKeys)
+	// The labels describing the placement still apply and stay recorded.
+	record := scaleFromZeroRecord{Labels: map[string]string{}}
+	for key, value := range previous.Labels {
+		if !instanceTypeLabelKeys.Has(key) {
+			record.Labels[key] = value
+		}
+	}
+	setScaleFromZeroRecord(machineSet, record)
+
+	if len(remaining) == 0 {
+		delete(machineSet.Annotations, labelsKey)
+		return
+	}
+	machineSet.Annotations[labelsKey] = strings.Join(remaining, ",")
+}
+
+// setOrRemoveManagedAnnotation sets the annotation of the MachineSet to the value and adds it to the record.
+// If the value is empty the annotation is removed, provided it was previously set by the controller.
+func setOrRemoveManagedAnnotation(machineSet *machinev1beta1.MachineSet, previous, record scaleFromZeroRecord, key, value string) {
+	if value == "" {
+		removeManagedAnnotation(machineSet, previous, key)
+		return
+	}
+	machineSet.Annotations[key] = value
+	record.Annotations[key] = value
+}
+
+// removeManagedAnnotation removes the annotation of the MachineSet if it still has the value previously recorded
+// by the controller. Other values were populated manually and are kept.
+func removeManagedAnnotation(machineSet *machinev1beta1.MachineSet, previous scaleFromZeroRecord, key string) {
+	if recorded, ok := previous.Annotations[key]; ok && machineSet.Annotations[key] == recorded {
+		delete(machineSet.Annotations, key)
+	}
+}
+
+// setInstanceTypeNodeLabels sets the labels describing the instance type on the nodes of the template of the
+// MachineSet, adds them to the record, and returns the labels the nodes will have, which are those set manually on
+// the template when they differ. Labels which no longer apply are removed, provided they still have the value
+// previously recorded by the controller.
+func setInstanceTypeNodeLabels(machineSet *machinev1beta1.MachineSet, previous, record scaleFromZeroRecord, labels []string) []string {
+	templateLabels := machineSet.Spec.Template.Spec.ObjectMeta.Labels
+	desired := sets.New[string]()
+	nodeLabels := []string{}
+	for _, label := range labels {
+		key, value, _ := strings.Cut(label, "=")
+		desired.Insert(key)
+		current, ok := templateLabels[key]
+		if recorded, managed := previous.NodeLabels[key]; ok && (!managed || current != recorded) {
+			nodeLabels = append(nodeLabels, fmt.Sprintf("%s=%s", key, current))
+			continue
+		}
+		if templateLabels == nil {
+			templateLabels = make(map[string]string)
+			machineSet.Spec.Template.Spec.ObjectMeta.Labels = templateLabels
+		}
+		templateLabels[key] = value
+		record.NodeLabels[key] = value
+		nodeLabels = append(nodeLabels, label)
+	}
+
+	for key, recorded := range previous.NodeLabels {
+		if current, ok := templateLabels[key]; ok && current == recorded && !desired.Has(key) {
+			delete(templateLabels, key)
+		}
+	}
+	return nodeLabels
+}
+
+// instanceTypeLabels returns the labels describing the attributes of the instance type which are known.
+func instanceTypeLabels(instanceType InstanceType) []string {
+	labels := []string{}
+	if instanceType.CPUManufacturer != "" {
+		labels = append(labels, fmt.Sprintf("%s=%s", CPUManufacturerLabel, labelValue(instanceType.CPUManufacturer)))
+	}
+	if instanceType.NetworkBandwidthMbps > 0 {
+		labels = append(labels, fmt.Sprintf("%s=%d", NetworkBandwidthLabel, instanceType.NetworkBandwidthMbps))
+	}
+	if instanceType.LocalNVMe {
+		labels = append(labels, fmt.Sprintf("%s=true", LocalNVMeLabel))
+	}
+	if instanceType.EFASupported {
+		labels = append(labels, fmt.Sprintf("%s=true", EFASupportedLabel))
+	}
+	if instanceType.AcceleratorCount > 0 {
+		labels = append(labels,
+			fmt.Sprintf("%s=%s", AcceleratorManufacturerLabel, labelValue(instanceType.AcceleratorManufacturer)),
+			fmt.Sprintf("%s=%s", AcceleratorNameLabel, labelValue(instanceType.AcceleratorName)),
+			fmt.Sprintf("%s=%d", AcceleratorCountLabel, instanceType.AcceleratorCount),
+		)
+	}
+	return labels
+}
+
+// labelValue lower cases the value and replaces the characters which are not allowed in label values by dashes.
+func labelValue(value string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return unicode.ToLower(r)
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, value), "-_.")
+}
+
+// ephemeralDiskCapacity returns the ephemeral storage of the nodes, which is the size of the root volume if it is
+// configured, or the size of the instance store otherwise. An empty string is returned if the size is unknown.
+func ephemeralDiskCapacity(providerConfig *machinev1beta1.AWSMachineProviderConfig, instanceType InstanceType) string {
+	for _, blockDevice := range providerConfig.BlockDevices {
+		if blockDevice.DeviceName == nil && blockDevice.EBS != nil && blockDevice.EBS.VolumeSize != nil && *blockDevice.EBS.VolumeSize > 0 {
+			return fmt.Sprintf("%dGi", *blockDevice.EBS.VolumeSize)
+		}
+	}
+	if instanceType.InstanceStorageGB > 0 {
+		return fmt.Sprintf("%dG", instanceType.InstanceStorageGB)
+	}
+	return ""
+}
+
+// formatTaints formats taints in the key=value:Effect format of the autoscaler taints annotation.
+func formatTaints(taints []corev1.Taint) string {
+	formatted := []string{}
+	for _, taint := range taints {
+		if taint.Value == "" {
+			formatted = append(formatted, fmt.Sprintf("%s:%s", taint.Key, taint.Effect))
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+	}
+	return strings.Join(formatted, ",")
+}
+
+// withoutManagedLabels returns the labels of the comma separated key=value list, without those whose key is in keys
+// and which still have the value previously recorded by the controller.
+func withoutManagedLabels(labels string, previous scaleFromZeroRecord, keys sets.Set[string]) []string {
+	remaining := []string{}
+	if labels == "" {
+		return remaining
+	}
+	for _, label := range strings.Split(labels, ",") {
+		key, value, _ := strings.Cut(label, "=")
+		if recorded, ok := previous.Labels[key]; ok && recorded == value && keys.Has(key) {
+			continue
+		}
+		remaining = append(remaining, label)
+	}
+	return remaining
+}
+
+// mergeLabels merges comma separated key=value lists, later lists taking precedence, and sorts the result
+// so that the annotation does not change between reconciles.
+func mergeLabels(lists ...string) string {
+	merged := strings.Split(util.MergeCommaSeparatedKeyValuePairs(lists...), ",")
+	sort.Strings(merged)
+	return strings.Join(merged, ",")
+}
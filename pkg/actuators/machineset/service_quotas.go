@@ -0,0 +1,283 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// QuotaExceededCondition reports whether the vCPUs of the MachineSets of a region sharing a vCPU quota,
+	// at their desired replicas, and of the Machines of the region not owned by a MachineSet, exceed the quota.
+	// Machines launched past the quota fail with VcpuLimitExceeded. The vCPUs are an estimate: only MachineSets and
+	// Machines of the namespace of the MachineSet are counted, not other instances of the account.
+	QuotaExceededCondition machinev1beta1.ConditionType = "QuotaExceeded"
+
+	// ServiceQuotasConfigMapName is the name of the ConfigMap, in the namespace of the MachineSets, overriding the
+	// quotas of the Service Quotas API. Its keys are <region>.<quota code>, e.g. us-east-1.L-1216C47A,
+	// and its values numbers of vCPUs.
+	ServiceQuotasConfigMapName = "aws-service-quotas"
+
+	// DefaultServiceQuotaRefreshInterval is the default interval at which quotas are refreshed.
+	DefaultServiceQuotaRefreshInterval = time.Hour
+
+	vcpuLimitExceededReason = "VcpuLimitExceeded"
+	vcpuWithinQuotaReason   = "VcpuWithinQuota"
+
+	ec2ServiceCode = "ec2"
+)
+
+// vcpuQuotaCodes are the codes of the on-demand and spot vCPU quotas of instance families.
+type vcpuQuotaCodes struct {
+	onDemand string
+	spot     string
+}
+
+var (
+	standardQuotaCodes = vcpuQuotaCodes{onDemand: "L-1216C47A", spot: "L-34B43A08"}
+	gQuotaCodes        = vcpuQuotaCodes{onDemand: "L-DB2E81BA", spot: "L-3819A6DF"}
+	pQuotaCodes        = vcpuQuotaCodes{onDemand: "L-417A185B", spot: "L-7212CCBC"}
+	fQuotaCodes        = vcpuQuotaCodes{onDemand: "L-74FC7D96", spot: "L-88CF9481"}
+	xQuotaCodes        = vcpuQuotaCodes{onDemand: "L-7295265B", spot: "L-E3A00192"}
+)
+
+// instanceFamilyQuotaCodes maps the instance families, the letters the instance type starts with,
+// to their vCPU quotas. Families with other quotas, e.g. inf or trn, are not checked.
+var instanceFamilyQuotaCodes = map[string]vcpuQuotaCodes{
+	"a": standardQuotaCodes, "c": standardQuotaCodes, "d": standardQuotaCodes, "h": standardQuotaCodes,
+	"i": standardQuotaCodes, "im": standardQuotaCodes, "is": standardQuotaCodes, "m": standardQuotaCodes,
+	"r": standardQuotaCodes, "t": standardQuotaCodes, "z": standardQuotaCodes,
+	"g": gQuotaCodes, "vt": gQuotaCodes,
+	"p": pQuotaCodes,
+	"f": fQuotaCodes,
+	"x": xQuotaCodes,
+}
+
+// ServiceQuotaCache provides the values of vCPU quotas.
+type ServiceQuotaCache interface {
+	// Quota returns the value of the EC2 quota in the region from the Service Quotas API,
+	// refreshing it once it is older than the refresh interval.
+	Quota(ctx context.Context, awsClient awsclient.Client, region, quotaCode string) (float64, error)
+	// RefreshInterval returns the interval at which quotas are refreshed.
+	RefreshInterval() time.Duration
+}
+
+// serviceQuotaCache holds the quotas fetched per region and quota code.
+type serviceQuotaCache struct {
+	quotas *valueCache
+}
+
+// NewServiceQuotaCache creates a quota cache refreshing quotas at the given interval.
+func NewServiceQuotaCache(refreshInterval time.Duration) ServiceQuotaCache {
+	return &serviceQuotaCache{
+		quotas: newValueCache(refreshInterval),
+	}
+}
+
+// Quota implements ServiceQuotaCache.
+// Failures are logged once per fetch, as they are cached until the next refresh.
+func (s *serviceQuotaCache) Quota(ctx context.Context, awsClient awsclient.Client, region, quotaCode string) (float64, error) {
+	return s.quotas.get(fmt.Sprintf("%s/%s", region, quotaCode), func() (float64, error) {
+		quota, err := fetchServiceQuota(ctx, awsClient, region, quotaCode)
+		if err != nil {
+			klog.Warningf("Unable to check vCPU quota %s in %s: %v", quotaCode, region, err)
+		}
+		return quota, err
+	})
+}
+
+// RefreshInterval implements ServiceQuotaCache
+func (s *serviceQuotaCache) RefreshInterval() time.Duration {
+	return s.quotas.refreshInterval
+}
+
+// fetchServiceQuota fetches the value of the EC2 quota in the region from the Service Quotas API.
+func fetchServiceQuota(ctx context.Context, awsClient awsclient.Client, region, quotaCode string) (float64, error) {
+	klog.V(3).Infof("Fetching quota %s in %s", quotaCode, region)
+
+	output, err := awsClient.GetServiceQuota(ctx, &awsclient.GetServiceQuotaInput{
+		ServiceCode: aws.String(ec2ServiceCode),
+		QuotaCode:   aws.String(quotaCode),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("getServiceQuota request failed: %w", err)
+	}
+	if output.Quota == nil || output.Quota.Value == nil {
+		return 0, fmt.Errorf("no value for quota %s", quotaCode)
+	}
+	return aws.Float64Value(output.Quota.Value), nil
+}
+
+// vcpuQuotaCode returns the code of the vCPU quota of the instance type,
+// or an empty string if its instance family is not known.
+func vcpuQuotaCode(instanceType string, spot bool) string {
+	i := strings.IndexFunc(instanceType, unicode.IsDigit)
+	if i <= 0 {
+		return ""
+	}
+
+	codes, ok := instanceFamilyQuotaCodes[instanceType[:i]]
+	if !ok {
+		return ""
+	}
+	if spot {
+		return codes.spot
+	}
+	return codes.onDemand
+}
+
+// setQuotaExceededCondition sets the QuotaExceeded condition on the MachineSet, comparing the vCPUs of the
+// MachineSets and standalone Machines of its namespace and region which share its vCPU quota with the quota. The quota is read from the
+// override ConfigMap, or from the Service Quotas API. The condition is removed when the quota is unknown.
+// The MachineSet is requeued so that the condition follows the refresh interval.
+func (r *Reconciler) setQuotaExceededCondition(ctx context.Context, awsClient awsclient.Client, machineSet *machinev1beta1.MachineSet, providerConfig *machinev1beta1.AWSMachineProviderConfig, instanceType InstanceType) (ctrl.Result, error) {
+	region := providerConfig.Placement.Region
+	spot := isSpot(providerConfig)
+	quotaCode := vcpuQuotaCode(providerConfig.InstanceType, spot)
+	if quotaCode == "" {
+		klog.V(3).Infof("%v: no vCPU quota known for instance type %s", machineSet.Name, providerConfig.InstanceType)
+		removeCondition(machineSet, QuotaExceededCondition)
+		return ctrl.Result{}, nil
+	}
+	result := ctrl.Result{RequeueAfter: r.Quotas.RefreshInterval()}
+
+	quota, ok, err := r.vcpuQuota(ctx, awsClient, machineSet.Namespace, region, quotaCode)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ok {
+		removeCondition(machineSet, QuotaExceededCondition)
+		return result, nil
+	}
+
+	demand, err := r.vcpuDemand(ctx, awsClient, machineSet, instanceType, region, quotaCode)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var condition *machinev1beta1.Condition
+	if float64(demand) > quota {
+		condition = conditions.TrueConditionWithReason(QuotaExceededCondition, vcpuLimitExceededReason,
+			"Machines of namespace %s using quota %s in %s require an estimated %d vCPUs, the quota is %v vCPUs",
+			machineSet.Namespace, quotaCode, region, demand, quota)
+	} else {
+		condition = conditions.FalseCondition(QuotaExceededCondition, vcpuWithinQuotaReason, machinev1beta1.ConditionSeverityInfo,
+			"Machines of namespace %s using quota %s in %s require an estimated %d of %v vCPUs, instances outside of the namespace are not counted",
+			machineSet.Namespace, quotaCode, region, demand, quota)
+	}
+
+	previous := conditions.Get(machineSet, QuotaExceededCondition)
+	if condition.Status == corev1.ConditionTrue && (previous == nil || previous.Status != corev1.ConditionTrue) {
+		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, vcpuLimitExceededReason, "Machines will fail to launch: %s", condition.Message)
+	}
+	conditions.Set(machineSet, condition)
+	return result, nil
+}
+
+// vcpuQuota returns the value of the quota in the region, from the override ConfigMap if it lists it, from the
+// Service Quotas API otherwise. Failures of the API, e.g. due to missing permissions, make the quota unknown.
+func (r *Reconciler) vcpuQuota(ctx context.Context, awsClient awsclient.Client, namespace, region, quotaCode string) (float64, bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := r.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ServiceQuotasConfigMapName}, configMap)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return 0, false, fmt.Errorf("error getting %s ConfigMap: %w", ServiceQuotasConfigMapName, err)
+	}
+
+	if value, ok := configMap.Data[fmt.Sprintf("%s.%s", region, quotaCode)]; ok {
+		quota, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid quota %q for %s in %s ConfigMap: %w", value, quotaCode, ServiceQuotasConfigMapName, err)
+		}
+		return quota, true, nil
+	}
+
+	quota, err := r.Quotas.Quota(ctx, awsClient, region, quotaCode)
+	if err != nil {
+		klog.V(3).Infof("vCPU quota %s in %s is unknown: %v", quotaCode, region, err)
+		return 0, false, nil
+	}
+	return quota, true, nil
+}
+
+// vcpuDemand returns the vCPUs of the MachineSets in the namespace of the MachineSet and in the region which use
+// the quota, at their desired replicas, and of the Machines there which are not owned by a MachineSet.
+// MachineSets and Machines whose instance type is unknown are not counted.
+func (r *Reconciler) vcpuDemand(ctx context.Context, awsClient awsclient.Client, machineSet *machinev1beta1.MachineSet, instanceType InstanceType, region, quotaCode string) (int64, error) {
+	machineSets := &machinev1beta1.MachineSetList{}
+	if err := r.Client.List(ctx, machineSets, client.InNamespace(machineSet.Namespace)); err != nil {
+		return 0, fmt.Errorf("error listing MachineSets: %w", err)
+	}
+	machines := &machinev1beta1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(machineSet.Namespace)); err != nil {
+		return 0, fmt.Errorf("error listing Machines: %w", err)
+	}
+
+	demand := replicas(machineSet) * instanceType.VCPU
+	for i := range machineSets.Items {
+		other := &machineSets.Items[i]
+		if other.Name == machineSet.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		vcpu, err := r.quotaVCPU(ctx, awsClient, other.Spec.Template.Spec.ProviderSpec, region, quotaCode)
+		if err != nil {
+			klog.V(3).Infof("%v: not counting vCPUs of MachineSet %s: %v", machineSet.Name, other.Name, err)
+			continue
+		}
+		demand += replicas(other) * vcpu
+	}
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if owner := metav1.GetControllerOf(machine); (owner != nil && owner.Kind == "MachineSet") || !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		vcpu, err := r.quotaVCPU(ctx, awsClient, machine.Spec.ProviderSpec, region, quotaCode)
+		if err != nil {
+			klog.V(3).Infof("%v: not counting vCPUs of Machine %s: %v", machineSet.Name, machine.Name, err)
+			continue
+		}
+		demand += vcpu
+	}
+	return demand, nil
+}
+
+// quotaVCPU returns the vCPUs of an instance of the provider spec counted against the quota of the region,
+// 0 if it is in another region or uses another quota.
+func (r *Reconciler) quotaVCPU(ctx context.Context, awsClient awsclient.Client, providerSpec machinev1beta1.ProviderSpec, region, quotaCode string) (int64, error) {
+	providerConfig, err := utils.ProviderSpecFromRawExtension(providerSpec.Value)
+	if err != nil || providerConfig.Placement.Region != region || vcpuQuotaCode(providerConfig.InstanceType, isSpot(providerConfig)) != quotaCode {
+		return 0, nil
+	}
+	instanceType, err := r.InstanceTypesCache.GetInstanceType(ctx, awsClient, region, providerConfig.InstanceType)
+	if err != nil {
+		return 0, err
+	}
+	return instanceType.VCPU, nil
+}
+
+// isSpot returns whether Machines of the provider config are spot instances.
+func isSpot(providerConfig *machinev1beta1.AWSMachineProviderConfig) bool {
+	return providerConfig.SpotMarketOptions != nil || providerConfig.MarketType == machinev1beta1.MarketTypeSpot
+}
+
+// replicas returns the desired replicas of the MachineSet, which default to 1.
+func replicas(machineSet *machinev1beta1.MachineSet) int64 {
+	if machineSet.Spec.Replicas == nil {
+		return 1
+	}
+	return int64(*machineSet.Spec.Replicas)
+}
@@ -0,0 +1,222 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVcpuQuotaCode(t *testing.T) {
+	testCases := []struct {
+		instanceType string
+		spot         bool
+		expected     string
+	}{
+		{instanceType: "m5.large", expected: "L-1216C47A"},
+		{instanceType: "m5.large", spot: true, expected: "L-34B43A08"},
+		{instanceType: "im4gn.xlarge", expected: "L-1216C47A"},
+		{instanceType: "g4dn.xlarge", expected: "L-DB2E81BA"},
+		{instanceType: "vt1.3xlarge", spot: true, expected: "L-3819A6DF"},
+		{instanceType: "p4d.24xlarge", expected: "L-417A185B"},
+		{instanceType: "x2idn.16xlarge", expected: "L-7295265B"},
+		{instanceType: "inf2.xlarge", expected: ""},
+		{instanceType: "u-6tb1.metal", expected: ""},
+		{instanceType: "", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s spot=%t", tc.instanceType, tc.spot), func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(vcpuQuotaCode(tc.instanceType, tc.spot)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSetQuotaExceededCondition(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(testScheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := machinev1beta1.AddToScheme(testScheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newMachineSet := func(name, instanceType, region string, replicas int32, spot bool) *machinev1beta1.MachineSet {
+		providerConfig := &machinev1beta1.AWSMachineProviderConfig{
+			InstanceType: instanceType,
+			Placement:    machinev1beta1.Placement{Region: region},
+		}
+		if spot {
+			providerConfig.SpotMarketOptions = &machinev1beta1.SpotMarketOptions{}
+		}
+		providerSpec, err := providerSpecFromMachine(providerConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &machinev1beta1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: machinev1beta1.MachineSetSpec{
+				Replicas: ptr.To(replicas),
+				Template: machinev1beta1.MachineTemplateSpec{Spec: machinev1beta1.MachineSpec{ProviderSpec: providerSpec}},
+			},
+		}
+	}
+	instanceTypes := InstanceTypesSnapshot{
+		InstanceTypes: map[string]InstanceType{
+			"m5.xlarge":  {InstanceType: "m5.xlarge", VCPU: 4},
+			"m5.4xlarge": {InstanceType: "m5.4xlarge", VCPU: 16},
+			"c5.2xlarge": {InstanceType: "c5.2xlarge", VCPU: 8},
+		},
+		LastUpdate: time.Now(),
+	}
+	newMachine := func(name, instanceType string, ownerKind string) *machinev1beta1.Machine {
+		providerSpec, err := providerSpecFromMachine(&machinev1beta1.AWSMachineProviderConfig{
+			InstanceType: instanceType,
+			Placement:    machinev1beta1.Placement{Region: "us-east-1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		machine := &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       machinev1beta1.MachineSpec{ProviderSpec: providerSpec},
+		}
+		if ownerKind != "" {
+			machine.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", Controller: ptr.To(true)}}
+		}
+		return machine
+	}
+	// Only MachineSets of the same region and quota, and Machines not owned by a MachineSet, add to the demand:
+	// 5*4 + 2*8 + 4 = 40 vCPUs.
+	others := []client.Object{
+		newMachineSet("same-quota", "c5.2xlarge", "us-east-1", 2, false),
+		newMachineSet("other-region", "m5.4xlarge", "us-west-2", 10, false),
+		newMachineSet("spot", "m5.4xlarge", "us-east-1", 10, true),
+		newMachine("standalone", "m5.xlarge", ""),
+		newMachine("owned", "m5.4xlarge", "MachineSet"),
+	}
+
+	testCases := []struct {
+		name           string
+		quota          float64
+		override       string
+		quotaErr       error
+		expectedStatus corev1.ConditionStatus
+		expectEvent    bool
+	}{
+		{
+			name:           "within the quota",
+			quota:          64,
+			expectedStatus: corev1.ConditionFalse,
+		},
+		{
+			name:           "exceeding the quota",
+			quota:          32,
+			expectedStatus: corev1.ConditionTrue,
+			expectEvent:    true,
+		},
+		{
+			name:           "exceeding the override",
+			override:       "32",
+			expectedStatus: corev1.ConditionTrue,
+			expectEvent:    true,
+		},
+		{
+			name:     "unknown quota",
+			quotaErr: awserr.New("AccessDeniedException", "not authorized to perform servicequotas:GetServiceQuota", nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			mockAWSClient := mockaws.NewMockClient(mockCtrl)
+			if tc.override == "" {
+				mockAWSClient.EXPECT().GetServiceQuota(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+						g.Expect(aws.StringValue(input.ServiceCode)).To(Equal("ec2"))
+						g.Expect(aws.StringValue(input.QuotaCode)).To(Equal("L-1216C47A"))
+						if tc.quotaErr != nil {
+							return nil, tc.quotaErr
+						}
+						return &awsclient.GetServiceQuotaOutput{Quota: &awsclient.ServiceQuota{Value: aws.Float64(tc.quota)}}, nil
+					})
+			}
+
+			objects := append([]client.Object{}, others...)
+			if tc.override != "" {
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: ServiceQuotasConfigMapName},
+					Data:       map[string]string{"us-east-1.L-1216C47A": tc.override},
+				})
+			}
+			instanceTypesCache := NewInstanceTypesCache()
+			instanceTypesCache.Restore("us-east-1", instanceTypes)
+			recorder := record.NewFakeRecorder(10)
+			k8sClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(objects...).Build()
+			r := Reconciler{
+				Client:             k8sClient,
+				APIReader:          k8sClient,
+				InstanceTypesCache: instanceTypesCache,
+				Quotas:             NewServiceQuotaCache(DefaultServiceQuotaRefreshInterval),
+				recorder:           recorder,
+			}
+
+			machineSet := newMachineSet("test", "m5.xlarge", "us-east-1", 5, false)
+			providerConfig, err := utils.ProviderSpecFromRawExtension(machineSet.Spec.Template.Spec.ProviderSpec.Value)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			result, err := r.setQuotaExceededCondition(context.TODO(), mockAWSClient, machineSet, providerConfig, instanceTypes.InstanceTypes["m5.xlarge"])
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(DefaultServiceQuotaRefreshInterval))
+
+			condition := conditions.Get(machineSet, QuotaExceededCondition)
+			if tc.expectedStatus == "" {
+				g.Expect(condition).To(BeNil())
+			} else {
+				g.Expect(condition).ToNot(BeNil())
+				g.Expect(condition.Status).To(Equal(tc.expectedStatus))
+				g.Expect(condition.Message).To(ContainSubstring("require an estimated 40"))
+			}
+			if tc.expectEvent {
+				g.Expect(recorder.Events).To(HaveLen(1))
+				g.Expect(<-recorder.Events).To(ContainSubstring(vcpuLimitExceededReason))
+			} else {
+				g.Expect(recorder.Events).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestServiceQuotaCacheCachesErrors(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().GetServiceQuota(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("access denied")).Times(1)
+
+	quotas := NewServiceQuotaCache(time.Hour)
+	for i := 0; i < 2; i++ {
+		_, err := quotas.Quota(context.TODO(), mockAWSClient, "us-east-1", "L-1216C47A")
+		g.Expect(err).To(MatchError(ContainSubstring("access denied")))
+	}
+}
@@ -0,0 +1,47 @@
+package machineset
+
+import (
+	"sync"
+	"time"
+)
+
+// cachedValue is a cached value, or the error fetching it, and the time it was fetched.
+type cachedValue struct {
+	value     float64
+	err       error
+	fetchedAt time.Time
+}
+
+// valueCache holds values fetched per key until they are older than the refresh interval. Errors are cached as well,
+// so that missing permissions do not cause a request on every reconcile. Access is synchronized via mutex.
+type valueCache struct {
+	refreshInterval time.Duration
+	values          map[string]cachedValue
+	mutex           sync.Mutex
+}
+
+// newValueCache creates a value cache refreshing values at the given interval.
+func newValueCache(refreshInterval time.Duration) *valueCache {
+	return &valueCache{
+		refreshInterval: refreshInterval,
+		values:          map[string]cachedValue{},
+	}
+}
+
+// get returns the value of the key, or the error fetching it, calling fetch if it is not cached or is older than the
+// refresh interval.
+func (c *valueCache) get(key string, fetch func() (float64, error)) (float64, error) {
+	c.mutex.Lock()
+	cached, ok := c.values[key]
+	c.mutex.Unlock()
+	if ok && time.Since(cached.fetchedAt) < c.refreshInterval {
+		return cached.value, cached.err
+	}
+
+	value, err := fetch()
+
+	c.mutex.Lock()
+	c.values[key] = cachedValue{value: value, err: err, fetchedAt: time.Now()}
+	c.mutex.Unlock()
+	return value, err
+}
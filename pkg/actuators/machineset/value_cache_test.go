@@ -0,0 +1,42 @@
+package machineset
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValueCache(t *testing.T) {
+	g := NewWithT(t)
+
+	fetches := 0
+	fetch := func(value float64, err error) func() (float64, error) {
+		return func() (float64, error) {
+			fetches++
+			return value, err
+		}
+	}
+
+	cache := newValueCache(time.Hour)
+	for i := 0; i < 2; i++ {
+		value, err := cache.get("a", fetch(1, nil))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(value).To(Equal(1.0))
+	}
+	g.Expect(fetches).To(Equal(1))
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.get("b", fetch(0, fmt.Errorf("access denied")))
+		g.Expect(err).To(MatchError("access denied"))
+	}
+	g.Expect(fetches).To(Equal(2))
+
+	// Values older than the refresh interval are fetched again.
+	cache.values["a"] = cachedValue{value: 1, fetchedAt: time.Now().Add(-2 * time.Hour)}
+	value, err := cache.get("a", fetch(2, nil))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(value).To(Equal(2.0))
+	g.Expect(fetches).To(Equal(3))
+}
@@ -0,0 +1,320 @@
+package machineset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/go-logr/logr"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/controller/machine"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ZoneSpreadReplicasAnnotation makes a MachineSet the template of one child MachineSet per availability zone
+	// of the subnets matching its subnet filters. Its value is the replicas children are created with; the replicas
+	// of existing children are left to their users, e.g. the autoscaler. The template itself must have 0 replicas,
+	// otherwise its children are not synced since it would create Machines besides them.
+	ZoneSpreadReplicasAnnotation = "machine.openshift.io/zone-spread-replicas"
+
+	// ZoneSpreadTemplateLabel is set on child MachineSets to the name of their template.
+	ZoneSpreadTemplateLabel = "machine.openshift.io/zone-spread-template"
+
+	// machineSetLabel is the label selecting the Machines of a MachineSet.
+	machineSetLabel = "machine.openshift.io/cluster-api-machineset"
+
+	// zoneSpreadFailedReason is the reason of the events emitted when children cannot be synced.
+	zoneSpreadFailedReason = "ZoneSpreadFailed"
+)
+
+// ZoneSpreadReconciler keeps one child MachineSet per availability zone in sync with template MachineSets,
+// annotated with ZoneSpreadReplicasAnnotation. Children are copies of the template pinned to the availability
+// zone and subnet discovered with the subnet filters of the template. Children of zones which no longer have
+// a matching subnet are deleted. Removing the annotation stops the sync, leaving the children as they are.
+type ZoneSpreadReconciler struct {
+	Client              client.Client
+	Log                 logr.Logger
+	AwsClientBuilder    awsclient.AwsClientBuilderFuncType
+	RegionCache         awsclient.RegionCache
+	ConfigManagedClient client.Client
+
+	recorder record.EventRecorder
+}
+
+// SetupWithManager creates a new controller for a manager.
+func (r *ZoneSpreadReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		Named("machineset-zone-spread").
+		For(&machinev1beta1.MachineSet{}).
+		Owns(&machinev1beta1.MachineSet{}).
+		WithOptions(options).
+		Build(r)
+
+	if err != nil {
+		return fmt.Errorf("failed setting up with a controller manager: %w", err)
+	}
+
+	r.recorder = mgr.GetEventRecorderFor("machineset-zone-spread-controller")
+	return nil
+}
+
+// Reconcile implements controller runtime Reconciler interface.
+func (r *ZoneSpreadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	template := &machinev1beta1.MachineSet{}
+	if err := r.Client.Get(ctx, req.NamespacedName, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Children are garbage collected with their template.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	value, ok := template.Annotations[ZoneSpreadReplicasAnnotation]
+	if !ok || !template.DeletionTimestamp.IsZero() || conditions.IsTrue(template, machine.PausedCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	logger := r.Log.WithValues("machineset", req.Name, "namespace", req.Namespace)
+	logger.V(3).Info("Reconciling zone spread")
+
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || replicas < 0 {
+		r.recorder.Eventf(template, corev1.EventTypeWarning, zoneSpreadFailedReason, "Invalid %s annotation %q, it must be a number of replicas", ZoneSpreadReplicasAnnotation, value)
+		return ctrl.Result{}, nil
+	}
+
+	// The template is a MachineSet of its own, which defaults to 1 replica.
+	if template.Spec.Replicas == nil || *template.Spec.Replicas != 0 {
+		r.recorder.Eventf(template, corev1.EventTypeWarning, zoneSpreadFailedReason, "The replicas of a MachineSet with the %s annotation must be set to 0, its children are not synced", ZoneSpreadReplicasAnnotation)
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.reconcile(ctx, template, int32(replicas)); err != nil {
+		logger.Error(err, "Failed to sync zone spread MachineSets")
+		if isInvalidConfigurationError(err) {
+			r.recorder.Eventf(template, corev1.EventTypeWarning, string(machinev1beta1.InvalidConfigurationMachineError), "%v", err)
+			return ctrl.Result{}, nil
+		}
+		r.recorder.Eventf(template, corev1.EventTypeWarning, zoneSpreadFailedReason, "%v", err)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcile creates or updates the child of each availability zone, and deletes the children of other zones.
+func (r *ZoneSpreadReconciler) reconcile(ctx context.Context, template *machinev1beta1.MachineSet, replicas int32) error {
+	providerConfig, err := utils.ProviderSpecFromRawExtension(template.Spec.Template.Spec.ProviderSpec.Value)
+	if err != nil {
+		return machine.InvalidMachineConfiguration("failed to get providerConfig: %v", err)
+	}
+	if providerConfig.CredentialsSecret == nil {
+		return machine.InvalidMachineConfiguration("nil credentialsSecret for machineSet %s", template.Name)
+	}
+	if len(providerConfig.Subnet.Filters) == 0 {
+		return machine.InvalidMachineConfiguration("machineSet %s must select its subnets with filters to be spread across zones", template.Name)
+	}
+
+	awsClient, err := r.AwsClientBuilder(ctx, r.Client, providerConfig.CredentialsSecret.Name, template.Namespace, providerConfig.Placement.Region, r.ConfigManagedClient, r.RegionCache)
+	if err != nil {
+		return fmt.Errorf("error creating aws client: %w", err)
+	}
+
+	zoneSubnets, err := getZoneSubnets(ctx, awsClient, providerConfig.Subnet.Filters)
+	if err != nil {
+		return err
+	}
+
+	children := &machinev1beta1.MachineSetList{}
+	if err := r.Client.List(ctx, children, client.InNamespace(template.Namespace), client.MatchingLabels{ZoneSpreadTemplateLabel: template.Name}); err != nil {
+		return fmt.Errorf("error listing zone spread MachineSets: %w", err)
+	}
+	existing := map[string]*machinev1beta1.MachineSet{}
+	for i := range children.Items {
+		existing[children.Items[i].Name] = &children.Items[i]
+	}
+
+	zones := make([]string, 0, len(zoneSubnets))
+	for zone := range zoneSubnets {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	// The names of the children are the values of the label selecting their Machines, they are checked before any
+	// child is created so that a long template name does not leave the zones half synced.
+	for _, zone := range zones {
+		if errs := validation.IsValidLabelValue(childName(template, zone)); len(errs) > 0 {
+			return machine.InvalidMachineConfiguration("name %s of the MachineSet of availability zone %s is not a valid %s label value, shorten the name of machineSet %s: %s",
+				childName(template, zone), zone, machineSetLabel, template.Name, strings.Join(errs, ", "))
+		}
+	}
+
+	for _, zone := range zones {
+		desired, err := r.childMachineSet(template, providerConfig, zone, zoneSubnets[zone])
+		if err != nil {
+			return err
+		}
+
+		child, ok := existing[desired.Name]
+		delete(existing, desired.Name)
+		if !ok {
+			klog.V(3).Infof("%v: creating zone spread MachineSet %s", template.Name, desired.Name)
+			desired.Spec.Replicas = &replicas
+			if err := r.Client.Create(ctx, desired); err != nil {
+				return fmt.Errorf("error creating MachineSet %s: %w", desired.Name, err)
+			}
+			continue
+		}
+
+		if err := r.updateChild(ctx, child, desired); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range existing {
+		if !metav1.IsControlledBy(child, template) || !child.DeletionTimestamp.IsZero() {
+			continue
+		}
+		klog.V(3).Infof("%v: deleting zone spread MachineSet %s, its zone has no matching subnet", template.Name, child.Name)
+		if err := r.Client.Delete(ctx, child); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting MachineSet %s: %w", child.Name, err)
+		}
+	}
+	return nil
+}
+
+// childName returns the name of the child of the template in the availability zone.
+func childName(template *machinev1beta1.MachineSet, zone string) string {
+	return fmt.Sprintf("%s-%s", template.Name, zone)
+}
+
+// childMachineSet returns the child of the template in the availability zone, pinned to the subnet.
+// Its Machines are selected by its own name instead of the name of the template.
+func (r *ZoneSpreadReconciler) childMachineSet(template *machinev1beta1.MachineSet, providerConfig *machinev1beta1.AWSMachineProviderConfig, zone, subnetID string) (*machinev1beta1.MachineSet, error) {
+	name := childName(template, zone)
+
+	childConfig := providerConfig.DeepCopy()
+	childConfig.Placement.AvailabilityZone = zone
+	childConfig.Subnet = machinev1beta1.AWSResourceReference{ID: aws.String(subnetID)}
+	rawProviderConfig, err := utils.RawExtensionFromProviderSpec(childConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	child := &machinev1beta1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: template.Namespace,
+			Labels:    map[string]string{},
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	for key, value := range template.Labels {
+		child.Labels[key] = value
+	}
+	child.Labels[ZoneSpreadTemplateLabel] = template.Name
+
+	child.Spec.Template.Spec.ProviderSpec.Value = rawProviderConfig
+	if child.Spec.Selector.MatchLabels == nil {
+		child.Spec.Selector.MatchLabels = map[string]string{}
+	}
+	child.Spec.Selector.MatchLabels[machineSetLabel] = name
+	if child.Spec.Template.Labels == nil {
+		child.Spec.Template.Labels = map[string]string{}
+	}
+	child.Spec.Template.Labels[machineSetLabel] = name
+
+	if err := controllerutil.SetControllerReference(template, child, r.Client.Scheme()); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// updateChild updates the labels and spec of the child, except its replicas, if they differ from the desired ones.
+// Provider specs are compared decoded, as their encoding changes when they are stored.
+func (r *ZoneSpreadReconciler) updateChild(ctx context.Context, child, desired *machinev1beta1.MachineSet) error {
+	currentConfig, err := utils.ProviderSpecFromRawExtension(child.Spec.Template.Spec.ProviderSpec.Value)
+	if err != nil {
+		return fmt.Errorf("failed to get providerConfig of MachineSet %s: %w", child.Name, err)
+	}
+	desiredConfig, err := utils.ProviderSpecFromRawExtension(desired.Spec.Template.Spec.ProviderSpec.Value)
+	if err != nil {
+		return err
+	}
+
+	currentSpec := child.Spec.DeepCopy()
+	currentSpec.Template.Spec.ProviderSpec.Value = nil
+	desiredSpec := desired.Spec.DeepCopy()
+	desiredSpec.Template.Spec.ProviderSpec.Value = nil
+	desiredSpec.Replicas = currentSpec.Replicas
+
+	labelsInSync := true
+	for key, value := range desired.Labels {
+		if child.Labels[key] != value {
+			labelsInSync = false
+		}
+	}
+	if labelsInSync && equality.Semantic.DeepEqual(currentSpec, desiredSpec) && equality.Semantic.DeepEqual(currentConfig, desiredConfig) {
+		return nil
+	}
+
+	klog.V(3).Infof("Updating zone spread MachineSet %s", child.Name)
+	patchBase := client.MergeFrom(child.DeepCopy())
+	if child.Labels == nil {
+		child.Labels = map[string]string{}
+	}
+	for key, value := range desired.Labels {
+		child.Labels[key] = value
+	}
+	replicas := child.Spec.Replicas
+	child.Spec = desired.Spec
+	child.Spec.Replicas = replicas
+	if err := r.Client.Patch(ctx, child, patchBase); err != nil {
+		return fmt.Errorf("error updating MachineSet %s: %w", child.Name, err)
+	}
+	return nil
+}
+
+// getZoneSubnets returns a subnet per availability zone of the available subnets matching the filters.
+// When a zone has several matching subnets, the one with the lowest ID is used so that the choice is stable.
+// No matching subnet is an error, so that a typo in the filters does not delete every child.
+func getZoneSubnets(ctx context.Context, awsClient awsclient.Client, filters []machinev1beta1.Filter) (map[string]string, error) {
+	output, err := awsClient.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		Filters: append(utils.BuildEC2Filters(filters), &ec2.Filter{
+			Name:   aws.String("state"),
+			Values: []*string{aws.String(ec2.SubnetStateAvailable)},
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing subnets: %w", err)
+	}
+
+	zoneSubnets := map[string]string{}
+	for _, subnet := range output.Subnets {
+		zone, subnetID := aws.StringValue(subnet.AvailabilityZone), aws.StringValue(subnet.SubnetId)
+		if current, ok := zoneSubnets[zone]; !ok || subnetID < current {
+			zoneSubnets[zone] = subnetID
+		}
+	}
+	if len(zoneSubnets) == 0 {
+		return nil, fmt.Errorf("no available subnet matches the subnet filters")
+	}
+	return zoneSubnets, nil
+}
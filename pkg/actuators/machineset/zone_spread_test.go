@@ -0,0 +1,187 @@
+package machineset
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	utils "github.com/openshift/machine-api-provider-aws/pkg/actuators/machine"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestZoneSpreadReconcile(t *testing.T) {
+	g := NewWithT(t)
+	testScheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(machinev1beta1.AddToScheme(testScheme)).To(Succeed())
+
+	providerSpec, err := providerSpecFromMachine(&machinev1beta1.AWSMachineProviderConfig{
+		InstanceType:      "m5.large",
+		CredentialsSecret: &corev1.LocalObjectReference{Name: "test-credentials"},
+		Placement:         machinev1beta1.Placement{Region: "us-east-1"},
+		Subnet: machinev1beta1.AWSResourceReference{
+			Filters: []machinev1beta1.Filter{{Name: "tag:Name", Values: []string{"workers-*"}}},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	template := &machinev1beta1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "workers",
+			UID:         "template-uid",
+			Labels:      map[string]string{"team": "ml"},
+			Annotations: map[string]string{ZoneSpreadReplicasAnnotation: "2"},
+		},
+		Spec: machinev1beta1.MachineSetSpec{
+			Replicas: ptr.To[int32](0),
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{machineSetLabel: "workers"}},
+			Template: machinev1beta1.MachineTemplateSpec{
+				ObjectMeta: machinev1beta1.ObjectMeta{Labels: map[string]string{machineSetLabel: "workers"}},
+				Spec:       machinev1beta1.MachineSpec{ProviderSpec: providerSpec},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(template).Build()
+
+	subnets := []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-0b"), AvailabilityZone: aws.String("us-east-1a")},
+		{SubnetId: aws.String("subnet-0a"), AvailabilityZone: aws.String("us-east-1a")},
+		{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String("us-east-1b")},
+	}
+	mockCtrl := gomock.NewController(t)
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().DescribeSubnets(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+			g.Expect(input.Filters).To(HaveLen(2))
+			g.Expect(aws.StringValue(input.Filters[0].Name)).To(Equal("tag:Name"))
+			return &ec2.DescribeSubnetsOutput{Subnets: subnets}, nil
+		}).AnyTimes()
+
+	recorder := record.NewFakeRecorder(10)
+	r := &ZoneSpreadReconciler{
+		Client: k8sClient,
+		Log:    log.Log,
+		AwsClientBuilder: func(_ context.Context, _ client.Client, _, _, _ string, _ client.Client, _ awsclient.RegionCache) (awsclient.Client, error) {
+			return mockAWSClient, nil
+		},
+		recorder: recorder,
+	}
+	reconcile := func() {
+		_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)})
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+	getChildren := func() map[string]machinev1beta1.MachineSet {
+		children := &machinev1beta1.MachineSetList{}
+		g.Expect(k8sClient.List(context.TODO(), children, client.MatchingLabels{ZoneSpreadTemplateLabel: "workers"})).To(Succeed())
+		byName := map[string]machinev1beta1.MachineSet{}
+		for _, child := range children.Items {
+			byName[child.Name] = child
+		}
+		return byName
+	}
+
+	reconcile()
+	children := getChildren()
+	g.Expect(children).To(HaveLen(2))
+	child := children["workers-us-east-1a"]
+	g.Expect(*child.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(child.Labels).To(HaveKeyWithValue("team", "ml"))
+	g.Expect(child.Spec.Selector.MatchLabels).To(HaveKeyWithValue(machineSetLabel, "workers-us-east-1a"))
+	g.Expect(child.Spec.Template.Labels).To(HaveKeyWithValue(machineSetLabel, "workers-us-east-1a"))
+	g.Expect(metav1.IsControlledBy(&child, template)).To(BeTrue())
+	childConfig, err := utils.ProviderSpecFromRawExtension(child.Spec.Template.Spec.ProviderSpec.Value)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(childConfig.Placement.AvailabilityZone).To(Equal("us-east-1a"))
+	g.Expect(aws.StringValue(childConfig.Subnet.ID)).To(Equal("subnet-0a"), "the lowest subnet ID of a zone must be used")
+	g.Expect(childConfig.Subnet.Filters).To(BeEmpty())
+
+	// Scale a child, change the template and move the subnets from us-east-1a to us-east-1c.
+	child = children["workers-us-east-1b"]
+	child.Spec.Replicas = ptr.To[int32](5)
+	g.Expect(k8sClient.Update(context.TODO(), &child)).To(Succeed())
+	g.Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(template), template)).To(Succeed())
+	providerSpec, err = providerSpecFromMachine(&machinev1beta1.AWSMachineProviderConfig{
+		InstanceType:      "m5.xlarge",
+		CredentialsSecret: &corev1.LocalObjectReference{Name: "test-credentials"},
+		Placement:         machinev1beta1.Placement{Region: "us-east-1"},
+		Subnet: machinev1beta1.AWSResourceReference{
+			Filters: []machinev1beta1.Filter{{Name: "tag:Name", Values: []string{"workers-*"}}},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	template.Spec.Template.Spec.ProviderSpec = providerSpec
+	g.Expect(k8sClient.Update(context.TODO(), template)).To(Succeed())
+	subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String("us-east-1b")},
+		{SubnetId: aws.String("subnet-2"), AvailabilityZone: aws.String("us-east-1c")},
+	}
+
+	reconcile()
+	children = getChildren()
+	g.Expect(children).To(HaveLen(2))
+	g.Expect(children).To(HaveKey("workers-us-east-1c"))
+	child = children["workers-us-east-1b"]
+	g.Expect(*child.Spec.Replicas).To(Equal(int32(5)), "the replicas of existing children must be kept")
+	childConfig, err = utils.ProviderSpecFromRawExtension(child.Spec.Template.Spec.ProviderSpec.Value)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(childConfig.InstanceType).To(Equal("m5.xlarge"))
+
+	// No matching subnet keeps the children.
+	subnets = nil
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(getChildren()).To(HaveLen(2))
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	// A template with replicas, which default to 1, is not synced.
+	g.Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(template), template)).To(Succeed())
+	template.Spec.Replicas = nil
+	g.Expect(k8sClient.Update(context.TODO(), template)).To(Succeed())
+	subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-3"), AvailabilityZone: aws.String("us-east-1d")},
+	}
+
+	reconcile()
+	g.Expect(getChildren()).To(HaveLen(2))
+	g.Expect(getChildren()).ToNot(HaveKey("workers-us-east-1d"))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(zoneSpreadFailedReason)))
+
+	// A template whose children names are not valid label values is rejected before any child is created.
+	longTemplate := template.DeepCopy()
+	longTemplate.ObjectMeta = metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "workers-" + strings.Repeat("x", 45),
+		Annotations: map[string]string{ZoneSpreadReplicasAnnotation: "1"},
+	}
+	longTemplate.Spec.Replicas = ptr.To[int32](0)
+	g.Expect(k8sClient.Create(context.TODO(), longTemplate)).To(Succeed())
+	subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String("us-east-1b")},
+		{SubnetId: aws.String("subnet-4"), AvailabilityZone: aws.String("ap-southeast-2-akl-1a")},
+	}
+
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(longTemplate)})
+	g.Expect(err).ToNot(HaveOccurred())
+	longChildren := &machinev1beta1.MachineSetList{}
+	g.Expect(k8sClient.List(context.TODO(), longChildren, client.MatchingLabels{ZoneSpreadTemplateLabel: longTemplate.Name})).To(Succeed())
+	g.Expect(longChildren.Items).To(BeEmpty())
+	g.Expect(recorder.Events).To(Receive(And(ContainSubstring(string(machinev1beta1.InvalidConfigurationMachineError)), ContainSubstring("ap-southeast-2-akl-1a"))))
+}
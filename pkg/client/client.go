@@ -67,6 +67,9 @@ const (
 	cloudCABundleKey = "ca-bundle.pem"
 	// awsRegionsCacheExpirationDuration is the duration for which the AWS regions cache is valid
 	awsRegionsCacheExpirationDuration = time.Minute * 30
+	// defaultCallTimeout is the deadline applied to every AWS request on top of the caller's context,
+	// so that an unresponsive endpoint can't block a reconcile indefinitely.
+	defaultCallTimeout = time.Minute
 )
 
 var (
@@ -75,42 +78,52 @@ var (
 )
 
 // AwsClientBuilderFuncType is function type for building aws client
-type AwsClientBuilderFuncType func(client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache RegionCache) (Client, error)
+type AwsClientBuilderFuncType func(ctx context.Context, client client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache RegionCache) (Client, error)
 
 // Client is a wrapper object for actual AWS SDK clients to allow for easier testing.
 type Client interface {
-	DescribeImages(*ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error)
-	DescribeDHCPOptions(input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error)
-	DescribeVpcs(*ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error)
-	DescribeSubnets(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
-	DescribeAvailabilityZones(*ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error)
-	DescribeSecurityGroups(*ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
-	DescribePlacementGroups(*ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error)
-	DescribeInstanceTypes(*ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error)
-	DescribeHosts(*ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error)
-	AllocateHosts(*ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error)
-	ReleaseHosts(*ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error)
-	RunInstances(*ec2.RunInstancesInput) (*ec2.Reservation, error)
-	DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
-	TerminateInstances(*ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error)
-	DescribeVolumes(*ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error)
-	CreateTags(*ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
-	CreatePlacementGroup(*ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error)
-	DeletePlacementGroup(*ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error)
-
-	RegisterInstancesWithLoadBalancer(*elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error)
-	ELBv2DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error)
-	ELBv2DescribeTargetGroups(*elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error)
-	ELBv2DescribeTargetHealth(*elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error)
-	ELBv2RegisterTargets(*elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error)
-	ELBv2DeregisterTargets(*elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error)
+	DescribeImages(ctx context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error)
+	DescribeDHCPOptions(ctx context.Context, input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error)
+	DescribeVpcs(ctx context.Context, input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error)
+	DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
+	DescribeAvailabilityZones(ctx context.Context, input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error)
+	DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
+	DescribePlacementGroups(ctx context.Context, input *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error)
+	DescribeInstanceTypes(ctx context.Context, input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error)
+	DescribeInstanceTypeOfferings(ctx context.Context, input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error)
+	DescribeSpotPriceHistory(ctx context.Context, input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error)
+	DescribeCapacityReservations(ctx context.Context, input *ec2.DescribeCapacityReservationsInput) (*ec2.DescribeCapacityReservationsOutput, error)
+	DescribeHosts(ctx context.Context, input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error)
+	AllocateHosts(ctx context.Context, input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error)
+	ReleaseHosts(ctx context.Context, input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error)
+	RunInstances(ctx context.Context, input *ec2.RunInstancesInput) (*ec2.Reservation, error)
+	DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+	TerminateInstances(ctx context.Context, input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error)
+	DescribeSpotInstanceRequests(ctx context.Context, input *ec2.DescribeSpotInstanceRequestsInput) (*ec2.DescribeSpotInstanceRequestsOutput, error)
+	CancelSpotInstanceRequests(ctx context.Context, input *ec2.CancelSpotInstanceRequestsInput) (*ec2.CancelSpotInstanceRequestsOutput, error)
+	DescribeVolumes(ctx context.Context, input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error)
+	CreateTags(ctx context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
+	CreatePlacementGroup(ctx context.Context, input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error)
+	DeletePlacementGroup(ctx context.Context, input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error)
+
+	RegisterInstancesWithLoadBalancer(ctx context.Context, input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error)
+	ELBv2DescribeLoadBalancers(ctx context.Context, input *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error)
+	ELBv2DescribeTargetGroups(ctx context.Context, input *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error)
+	ELBv2DescribeTargetHealth(ctx context.Context, input *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error)
+	ELBv2RegisterTargets(ctx context.Context, input *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error)
+	ELBv2DeregisterTargets(ctx context.Context, input *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error)
+
+	GetServiceQuota(ctx context.Context, input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error)
 }
 
 type awsClient struct {
-	ec2Client   ec2iface.EC2API
-	elbClient   elbiface.ELBAPI
-	elbv2Client elbv2iface.ELBV2API
-	session     *session.Session
+	ec2Client           ec2iface.EC2API
+	elbClient           elbiface.ELBAPI
+	elbv2Client         elbv2iface.ELBV2API
+	serviceQuotasClient *serviceQuotas
+	session             *session.Session
+	// callTimeout bounds the duration of every single AWS request, including retries.
+	callTimeout time.Duration
 }
 
 func (c *awsClient) CloseIdleConnections() {
@@ -120,117 +133,152 @@ func (c *awsClient) CloseIdleConnections() {
 	}
 }
 
-func (c *awsClient) DescribeDHCPOptions(input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
-	return c.ec2Client.DescribeDhcpOptions(input)
+func (c *awsClient) DescribeDHCPOptions(ctx context.Context, input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
+	return c.ec2Client.DescribeDhcpOptionsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
-	return c.ec2Client.DescribeImages(input)
+func (c *awsClient) DescribeImages(ctx context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	return c.ec2Client.DescribeImagesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
-	return c.ec2Client.DescribeVpcs(input)
+func (c *awsClient) DescribeVpcs(ctx context.Context, input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return c.ec2Client.DescribeVpcsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
-	return c.ec2Client.DescribeSubnets(input)
+func (c *awsClient) DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return c.ec2Client.DescribeSubnetsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
-	return c.ec2Client.DescribeAvailabilityZones(input)
+func (c *awsClient) DescribeAvailabilityZones(ctx context.Context, input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return c.ec2Client.DescribeAvailabilityZonesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
-	return c.ec2Client.DescribeSecurityGroups(input)
+func (c *awsClient) DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return c.ec2Client.DescribeSecurityGroupsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribePlacementGroups(input *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
-	return c.ec2Client.DescribePlacementGroups(input)
+func (c *awsClient) DescribePlacementGroups(ctx context.Context, input *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
+	return c.ec2Client.DescribePlacementGroupsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
-	return c.ec2Client.DescribeInstanceTypes(input)
+func (c *awsClient) DescribeInstanceTypes(ctx context.Context, input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	return c.ec2Client.DescribeInstanceTypesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeHosts(input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error) {
-	return c.ec2Client.DescribeHosts(input)
+func (c *awsClient) DescribeInstanceTypeOfferings(ctx context.Context, input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	return c.ec2Client.DescribeInstanceTypeOfferingsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) AllocateHosts(input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
-	return c.ec2Client.AllocateHosts(input)
+func (c *awsClient) DescribeSpotPriceHistory(ctx context.Context, input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	return c.ec2Client.DescribeSpotPriceHistoryWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) ReleaseHosts(input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
-	return c.ec2Client.ReleaseHosts(input)
+func (c *awsClient) DescribeCapacityReservations(ctx context.Context, input *ec2.DescribeCapacityReservationsInput) (*ec2.DescribeCapacityReservationsOutput, error) {
+	return c.ec2Client.DescribeCapacityReservationsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) RunInstances(input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
-	return c.ec2Client.RunInstances(input)
+func (c *awsClient) DescribeHosts(ctx context.Context, input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error) {
+	return c.ec2Client.DescribeHostsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
-	return c.ec2Client.DescribeInstances(input)
+func (c *awsClient) AllocateHosts(ctx context.Context, input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
+	return c.ec2Client.AllocateHostsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) TerminateInstances(input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
-	return c.ec2Client.TerminateInstances(input)
+func (c *awsClient) ReleaseHosts(ctx context.Context, input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
+	return c.ec2Client.ReleaseHostsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DescribeVolumes(input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
-	return c.ec2Client.DescribeVolumes(input)
+func (c *awsClient) RunInstances(ctx context.Context, input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+	return c.ec2Client.RunInstancesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
-	return c.ec2Client.CreateTags(input)
+func (c *awsClient) DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return c.ec2Client.DescribeInstancesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) CreatePlacementGroup(input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
-	return c.ec2Client.CreatePlacementGroup(input)
+func (c *awsClient) TerminateInstances(ctx context.Context, input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	return c.ec2Client.TerminateInstancesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) DeletePlacementGroup(input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
-	return c.ec2Client.DeletePlacementGroup(input)
+func (c *awsClient) DescribeSpotInstanceRequests(ctx context.Context, input *ec2.DescribeSpotInstanceRequestsInput) (*ec2.DescribeSpotInstanceRequestsOutput, error) {
+	return c.ec2Client.DescribeSpotInstanceRequestsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) RegisterInstancesWithLoadBalancer(input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
-	return c.elbClient.RegisterInstancesWithLoadBalancer(input)
+func (c *awsClient) CancelSpotInstanceRequests(ctx context.Context, input *ec2.CancelSpotInstanceRequestsInput) (*ec2.CancelSpotInstanceRequestsOutput, error) {
+	return c.ec2Client.CancelSpotInstanceRequestsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) ELBv2DescribeLoadBalancers(input *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
-	return c.elbv2Client.DescribeLoadBalancers(input)
+func (c *awsClient) DescribeVolumes(ctx context.Context, input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+	return c.ec2Client.DescribeVolumesWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) ELBv2DescribeTargetGroups(input *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
-	return c.elbv2Client.DescribeTargetGroups(input)
+func (c *awsClient) CreateTags(ctx context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	return c.ec2Client.CreateTagsWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) ELBv2DescribeTargetHealth(input *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
-	return c.elbv2Client.DescribeTargetHealth(input)
+func (c *awsClient) CreatePlacementGroup(ctx context.Context, input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
+	return c.ec2Client.CreatePlacementGroupWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) ELBv2RegisterTargets(input *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
-	return c.elbv2Client.RegisterTargets(input)
+func (c *awsClient) DeletePlacementGroup(ctx context.Context, input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
+	return c.ec2Client.DeletePlacementGroupWithContext(ctx, input, withCallTimeout(c.callTimeout))
 }
 
-func (c *awsClient) ELBv2DeregisterTargets(input *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
-	return c.elbv2Client.DeregisterTargets(input)
+func (c *awsClient) RegisterInstancesWithLoadBalancer(ctx context.Context, input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
+	return c.elbClient.RegisterInstancesWithLoadBalancerWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
+
+func (c *awsClient) ELBv2DescribeLoadBalancers(ctx context.Context, input *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+	return c.elbv2Client.DescribeLoadBalancersWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
+
+func (c *awsClient) ELBv2DescribeTargetGroups(ctx context.Context, input *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+	return c.elbv2Client.DescribeTargetGroupsWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
+
+func (c *awsClient) ELBv2DescribeTargetHealth(ctx context.Context, input *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	return c.elbv2Client.DescribeTargetHealthWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
+
+func (c *awsClient) ELBv2RegisterTargets(ctx context.Context, input *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+	return c.elbv2Client.RegisterTargetsWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
+
+func (c *awsClient) ELBv2DeregisterTargets(ctx context.Context, input *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
+	return c.elbv2Client.DeregisterTargetsWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
+
+// withCallTimeout returns a request option that bounds the request with the given timeout.
+// The derived context is released once the request completes.
+func withCallTimeout(timeout time.Duration) request.Option {
+	return func(r *request.Request) {
+		if timeout <= 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		r.SetContext(ctx)
+		r.Handlers.Complete.PushBack(func(*request.Request) { cancel() })
+	}
 }
 
 // NewClient creates our client wrapper object for the actual AWS clients we use.
 // For authentication the underlying clients will use either the cluster AWS credentials
 // secret if defined (i.e. in the root cluster),
 // otherwise the IAM profile of the master where the actuator will run. (target clusters)
-func NewClient(ctrlRuntimeClient client.Client, secretName, namespace, region string, configManagedClient client.Client) (Client, error) {
-	s, err := newAWSSession(ctrlRuntimeClient, secretName, namespace, region, configManagedClient)
+func NewClient(ctx context.Context, ctrlRuntimeClient client.Client, secretName, namespace, region string, configManagedClient client.Client) (Client, error) {
+	s, err := newAWSSession(ctx, ctrlRuntimeClient, secretName, namespace, region, configManagedClient)
 	if err != nil {
 		return nil, err
 	}
 
 	return &awsClient{
-		ec2Client:   ec2.New(s),
-		elbClient:   elb.New(s),
-		elbv2Client: elbv2.New(s),
-		session:     s,
+		ec2Client:           ec2.New(s),
+		elbClient:           elb.New(s),
+		elbv2Client:         elbv2.New(s),
+		serviceQuotasClient: newServiceQuotas(s),
+		session:             s,
+		callTimeout:         defaultCallTimeout,
 	}, nil
 }
 
@@ -253,10 +301,12 @@ func NewClientFromKeys(accessKey, secretAccessKey, region string) (Client, error
 	s.Handlers.Build.PushBackNamed(addProviderVersionToUserAgent)
 
 	return &awsClient{
-		ec2Client:   ec2.New(s),
-		elbClient:   elb.New(s),
-		elbv2Client: elbv2.New(s),
-		session:     s,
+		ec2Client:           ec2.New(s),
+		elbClient:           elb.New(s),
+		elbv2Client:         elbv2.New(s),
+		serviceQuotasClient: newServiceQuotas(s),
+		session:             s,
+		callTimeout:         defaultCallTimeout,
 	}, nil
 }
 
@@ -275,7 +325,7 @@ type regionCache struct {
 
 // RegionCache caches successful DescribeRegions API calls and region validation results.
 type RegionCache interface {
-	GetCachedDescribeRegions(awsSession *session.Session) (*ec2.DescribeRegionsOutput, error)
+	GetCachedDescribeRegions(ctx context.Context, awsSession *session.Session) (*ec2.DescribeRegionsOutput, error)
 	IsRegionValidated(awsSession *session.Session, region string) (bool, error)
 	SetRegionValidated(awsSession *session.Session, region string) error
 }
@@ -290,7 +340,7 @@ func NewRegionCache() RegionCache {
 
 // GetCachedDescribeRegions returns DescribeRegionsOutput from DescribeRegions AWS API call.
 // It is cached to avoid AWS API calls on each reconcile loop.
-func (c *regionCache) GetCachedDescribeRegions(awsSession *session.Session) (*ec2.DescribeRegionsOutput, error) {
+func (c *regionCache) GetCachedDescribeRegions(ctx context.Context, awsSession *session.Session) (*ec2.DescribeRegionsOutput, error) {
 	creds, err := awsSession.Config.Credentials.Get()
 	if err != nil {
 		return nil, err
@@ -308,10 +358,10 @@ func (c *regionCache) GetCachedDescribeRegions(awsSession *session.Session) (*ec
 	currentRegion := awsSession.Config.Region
 	// Use default region to send our request
 	awsSession.Config.Region = aws.String("us-east-1")
-	describeRegionsOutput, err := ec2.New(awsSession).DescribeRegions(&ec2.DescribeRegionsInput{
+	describeRegionsOutput, err := ec2.New(awsSession).DescribeRegionsWithContext(ctx, &ec2.DescribeRegionsInput{
 		AllRegions: aws.Bool(true),
 		DryRun:     aws.Bool(false),
-	})
+	}, withCallTimeout(defaultCallTimeout))
 	// Restore the original region
 	awsSession.Config.Region = currentRegion
 	if err != nil {
@@ -386,8 +436,8 @@ func validateRegion(describeRegionsOutput *ec2.DescribeRegionsOutput, region str
 // NewValidatedClient creates our client wrapper object for the actual AWS clients we use.
 // This should behave the same as NewClient except it will validate the client configuration
 // (eg the region) before returning the client.
-func NewValidatedClient(ctrlRuntimeClient client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache RegionCache) (Client, error) {
-	s, err := newAWSSession(ctrlRuntimeClient, secretName, namespace, region, configManagedClient)
+func NewValidatedClient(ctx context.Context, ctrlRuntimeClient client.Client, secretName, namespace, region string, configManagedClient client.Client, regionCache RegionCache) (Client, error) {
+	s, err := newAWSSession(ctx, ctrlRuntimeClient, secretName, namespace, region, configManagedClient)
 	if err != nil {
 		return nil, err
 	}
@@ -410,7 +460,7 @@ func NewValidatedClient(ctrlRuntimeClient client.Client, secretName, namespace,
 			case endpoints.UnknownEndpointError:
 				klog.Infof("Region %s is not recognized by aws-sdk, trying to validate using API", region)
 				var describeRegionsOutput *ec2.DescribeRegionsOutput
-				describeRegionsOutput, err = regionCache.GetCachedDescribeRegions(s)
+				describeRegionsOutput, err = regionCache.GetCachedDescribeRegions(ctx, s)
 				if err != nil {
 					return nil, fmt.Errorf("could not retrieve region data: %w", err)
 				}
@@ -430,14 +480,16 @@ func NewValidatedClient(ctrlRuntimeClient client.Client, secretName, namespace,
 	}
 
 	return &awsClient{
-		ec2Client:   ec2.New(s),
-		elbClient:   elb.New(s),
-		elbv2Client: elbv2.New(s),
-		session:     s,
+		ec2Client:           ec2.New(s),
+		elbClient:           elb.New(s),
+		elbv2Client:         elbv2.New(s),
+		serviceQuotasClient: newServiceQuotas(s),
+		session:             s,
+		callTimeout:         defaultCallTimeout,
 	}, nil
 }
 
-func newAWSSession(ctrlRuntimeClient client.Client, secretName, namespace, region string, configManagedClient client.Client) (s *session.Session, err error) {
+func newAWSSession(ctx context.Context, ctrlRuntimeClient client.Client, secretName, namespace, region string, configManagedClient client.Client) (s *session.Session, err error) {
 	sessionOptions := session.Options{
 		Config: aws.Config{
 			Region: aws.String(region),
@@ -446,7 +498,7 @@ func newAWSSession(ctrlRuntimeClient client.Client, secretName, namespace, regio
 
 	if secretName != "" {
 		var secret corev1.Secret
-		if err := ctrlRuntimeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: secretName}, &secret); err != nil {
+		if err := ctrlRuntimeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, &secret); err != nil {
 			if apimachineryerrors.IsNotFound(err) {
 				return nil, machineapiapierrors.InvalidMachineConfiguration("aws credentials secret %s/%s: %v not found", namespace, secretName, err)
 			}
@@ -471,11 +523,12 @@ func newAWSSession(ctrlRuntimeClient client.Client, secretName, namespace, regio
 	}
 
 	// Resolve custom endpoints
-	if err := resolveEndpoints(&sessionOptions.Config, ctrlRuntimeClient, region); err != nil {
+	endpointFeatures, err := resolveEndpoints(ctx, &sessionOptions.Config, ctrlRuntimeClient, configManagedClient, region)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := useCustomCABundle(&sessionOptions, configManagedClient); err != nil {
+	if err := useCustomCABundle(ctx, &sessionOptions, configManagedClient); err != nil {
 		return nil, fmt.Errorf("failed to set the custom CA bundle: %w", err)
 	}
 
@@ -485,7 +538,7 @@ func newAWSSession(ctrlRuntimeClient client.Client, secretName, namespace, regio
 		return nil, err
 	}
 
-	s.Handlers.Build.PushBackNamed(addProviderVersionToUserAgent)
+	s.Handlers.Build.PushBackNamed(providerUserAgentHandler(endpointFeatures...))
 
 	return s, nil
 }
@@ -497,39 +550,79 @@ var addProviderVersionToUserAgent = request.NamedHandler{
 	Fn:   request.MakeAddToUserAgentHandler("openshift.io cluster-api-provider-aws", version.Version.String()),
 }
 
-func resolveEndpoints(awsConfig *aws.Config, ctrlRuntimeClient client.Client, region string) error {
+// resolveEndpoints configures the endpoint resolution of the session: explicit service endpoints from the
+// Infrastructure object, additional partitions from the kube cloud config, and FIPS and dual-stack endpoint variants.
+// FIPS endpoints are preferred when the host runs in FIPS mode, dual-stack endpoints when the cluster is dual-stack
+// and opts into them in the kube cloud config. The variants only apply to the endpoints resolved from the partitions,
+// explicit service endpoints are used as they are so that they can opt services out of them. It returns the endpoint
+// features in use, so they can be reported in the user agent.
+func resolveEndpoints(ctx context.Context, awsConfig *aws.Config, ctrlRuntimeClient client.Client, configManagedClient client.Client, region string) ([]string, error) {
 	infra := &configv1.Infrastructure{}
 	infraName := client.ObjectKey{Name: GlobalInfrastuctureName}
 
-	if err := ctrlRuntimeClient.Get(context.Background(), infraName, infra); err != nil {
-		return err
+	if err := ctrlRuntimeClient.Get(ctx, infraName, infra); err != nil {
+		return nil, err
 	}
 
-	// Do nothing when custom endpoints are missing
-	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.AWS == nil {
-		return nil
+	cloudConfig, err := kubeCloudConfig(ctx, configManagedClient)
+	if err != nil {
+		return nil, err
 	}
 
-	customEndpointsMap := buildCustomEndpointsMap(infra.Status.PlatformStatus.AWS.ServiceEndpoints)
+	features := []string{}
+	fips := isFIPSEnabled()
+	if fips {
+		features = append(features, fipsEndpointFeature)
+	}
+	dualStack := useDualStackEndpoints(infra, cloudConfig)
+	if dualStack {
+		features = append(features, dualStackEndpointFeature)
+	}
 
-	if len(customEndpointsMap) == 0 {
-		return nil
+	partitions, err := customPartitions(cloudConfig)
+	if err != nil {
+		return nil, err
+	}
+	if partition, ok := endpoints.PartitionForRegion(partitions, region); ok {
+		features = append(features, "partition="+partition.ID())
+	}
+
+	if len(features) > 0 {
+		klog.V(3).Infof("Resolving AWS endpoints for region %s with: %s", region, strings.Join(features, ", "))
 	}
 
+	customEndpointsMap := map[string]string{}
+	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.AWS != nil {
+		customEndpointsMap = buildCustomEndpointsMap(infra.Status.PlatformStatus.AWS.ServiceEndpoints)
+	}
+
+	// Do nothing when neither custom endpoints, custom partitions nor endpoint variants are configured
+	if len(customEndpointsMap) == 0 && len(partitions) == 0 && !fips && !dualStack {
+		return features, nil
+	}
+
+	partitionResolver := variantResolver(endpoints.ResolverFunc(func(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		if partition, ok := endpoints.PartitionForRegion(partitions, region); ok {
+			return partition.EndpointFor(service, region, optFns...)
+		}
+		return endpoints.DefaultResolver().EndpointFor(service, region, optFns...)
+	}), fips, dualStack)
+
 	customResolver := func(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
 		if url, ok := customEndpointsMap[service]; ok {
+			// The FIPS and dual-stack variants are not used, the endpoint may not provide them.
 			return endpoints.ResolvedEndpoint{
 				URL:           url,
 				SigningRegion: region,
 			}, nil
 
 		}
-		return endpoints.DefaultResolver().EndpointFor(service, region, optFns...)
+		return partitionResolver.EndpointFor(service, region, optFns...)
 	}
 
 	awsConfig.EndpointResolver = endpoints.ResolverFunc(customResolver)
 
-	return nil
+	return features, nil
 }
 
 // buildCustomEndpointsMap constructs a map that links endpoint name and it's url
@@ -592,10 +685,10 @@ func newConfigForStaticCreds(accessKey string, accessSecret string) []byte {
 
 // useCustomCABundle will set up a custom CA bundle in the AWS options if a CA bundle is configured in the
 // kube cloud config.
-func useCustomCABundle(awsOptions *session.Options, configManagedClient client.Client) error {
+func useCustomCABundle(ctx context.Context, awsOptions *session.Options, configManagedClient client.Client) error {
 	cm := &corev1.ConfigMap{}
 	switch err := configManagedClient.Get(
-		context.Background(),
+		ctx,
 		client.ObjectKey{Namespace: KubeCloudConfigNamespace, Name: kubeCloudConfigName},
 		cm,
 	); {
@@ -1,16 +1,20 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -62,7 +66,7 @@ func TestUseCustomCABundle(t *testing.T) {
 			}
 			ctrlRuntimeClient := fake.NewClientBuilder().WithRuntimeObjects(resources...).Build()
 			awsOptions := &session.Options{}
-			err := useCustomCABundle(awsOptions, ctrlRuntimeClient)
+			err := useCustomCABundle(context.Background(), awsOptions, ctrlRuntimeClient)
 			if err != nil {
 				t.Fatalf("unexpected error from useCustomCABundle: %v", err)
 			}
@@ -192,3 +196,117 @@ func TestCloseIdleConnectionsNilSession(t *testing.T) {
 	c := &awsClient{}
 	c.CloseIdleConnections()
 }
+
+// blockingTransport blocks every request until its context is done.
+type blockingTransport struct{}
+
+func (blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestRequestsHonourContext(t *testing.T) {
+	s := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		HTTPClient:  &http.Client{Transport: blockingTransport{}},
+		MaxRetries:  aws.Int(0),
+	}))
+
+	t.Run("call timeout", func(t *testing.T) {
+		c := &awsClient{ec2Client: ec2.New(s), callTimeout: 50 * time.Millisecond}
+
+		start := time.Now()
+		if _, err := c.DescribeImages(context.Background(), &ec2.DescribeImagesInput{}); err == nil {
+			t.Fatal("expected the request to time out")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected the request to be cancelled by the call timeout, took %v", elapsed)
+		}
+	})
+
+	t.Run("cancelled caller context", func(t *testing.T) {
+		c := &awsClient{ec2Client: ec2.New(s), callTimeout: time.Hour}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := c.DescribeImages(ctx, &ec2.DescribeImagesInput{}); err == nil {
+			t.Fatal("expected the request to be cancelled")
+		}
+	})
+}
+
+// recordingTransport records the request and responds with the given status and body.
+type recordingTransport struct {
+	request    *http.Request
+	body       string
+	statusCode int
+	response   string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.request = req
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.body = string(body)
+	return &http.Response{
+		StatusCode: r.statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       ioutil.NopCloser(strings.NewReader(r.response)),
+		Request:    req,
+	}, nil
+}
+
+func TestGetServiceQuota(t *testing.T) {
+	newClient := func(transport http.RoundTripper) *awsClient {
+		s := session.Must(session.NewSession(&aws.Config{
+			Region:      aws.String("eu-west-1"),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+			HTTPClient:  &http.Client{Transport: transport},
+			MaxRetries:  aws.Int(0),
+		}))
+		return &awsClient{serviceQuotasClient: newServiceQuotas(s), callTimeout: time.Minute}
+	}
+
+	t.Run("quota", func(t *testing.T) {
+		transport := &recordingTransport{
+			statusCode: http.StatusOK,
+			response:   `{"Quota":{"QuotaCode":"L-1216C47A","ServiceCode":"ec2","Unit":"None","Value":640.0}}`,
+		}
+		output, err := newClient(transport).GetServiceQuota(context.Background(), &GetServiceQuotaInput{
+			ServiceCode: aws.String("ec2"),
+			QuotaCode:   aws.String("L-1216C47A"),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if value := aws.Float64Value(output.Quota.Value); value != 640 {
+			t.Errorf("expected quota of 640, got %v", value)
+		}
+		if host := transport.request.URL.Host; host != "servicequotas.eu-west-1.amazonaws.com" {
+			t.Errorf("expected request to the regional endpoint, got %s", host)
+		}
+		if target := transport.request.Header.Get("X-Amz-Target"); target != "ServiceQuotasV20190624.GetServiceQuota" {
+			t.Errorf("unexpected target %q", target)
+		}
+		if transport.body != `{"QuotaCode":"L-1216C47A","ServiceCode":"ec2"}` {
+			t.Errorf("unexpected body %s", transport.body)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		transport := &recordingTransport{
+			statusCode: http.StatusBadRequest,
+			response:   `{"__type":"NoSuchResourceException","message":"The request failed because the specified service quota does not exist."}`,
+		}
+		_, err := newClient(transport).GetServiceQuota(context.Background(), &GetServiceQuotaInput{
+			ServiceCode: aws.String("ec2"),
+			QuotaCode:   aws.String("L-00000000"),
+		})
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != ErrCodeNoSuchResourceException {
+			t.Errorf("expected %s error, got %v", ErrCodeNoSuchResourceException, err)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	describeImagesResource            = "images"
+	describeSubnetsResource           = "subnets"
+	describeAvailabilityZonesResource = "availability_zones"
+	describeSecurityGroupsResource    = "security_groups"
+	describeVpcsResource              = "vpcs"
+	describeDHCPOptionsResource       = "dhcp_options"
+
+	describeCacheHit  = "hit"
+	describeCacheMiss = "miss"
+)
+
+var describeCacheRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "mapi_aws_describe_cache_requests_total",
+		Help: "Number of AWS describe requests answered by the describe cache (hit) or sent to AWS (miss).",
+	}, []string{"resource", "result"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(describeCacheRequests)
+}
+
+// DescribeCacheTTLs configures for how long the results of each kind of describe call are cached.
+// A zero TTL disables caching for that kind of call.
+type DescribeCacheTTLs struct {
+	// Images applies to DescribeImages.
+	Images time.Duration
+	// Subnets applies to DescribeSubnets.
+	Subnets time.Duration
+	// AvailabilityZones applies to DescribeAvailabilityZones.
+	AvailabilityZones time.Duration
+	// SecurityGroups applies to DescribeSecurityGroups.
+	SecurityGroups time.Duration
+	// DHCPOptions applies to DescribeVpcs and DescribeDHCPOptions, which are used together to look up the VPC domain names.
+	DHCPOptions time.Duration
+}
+
+// DefaultDescribeCacheTTLs returns the default TTLs of the describe cache.
+func DefaultDescribeCacheTTLs() DescribeCacheTTLs {
+	return DescribeCacheTTLs{
+		Images:            time.Hour,
+		Subnets:           10 * time.Minute,
+		AvailabilityZones: 24 * time.Hour,
+		SecurityGroups:    10 * time.Minute,
+		DHCPOptions:       10 * time.Minute,
+	}
+}
+
+// DescribeCache caches the results of describe calls for resources that rarely change once a cluster is installed,
+// such as AMIs, subnets, availability zones, security groups and DHCP options.
+type DescribeCache interface {
+	// WrapClient returns a Client that answers the cached describe calls from the cache.
+	// The cacheID must identify the region and credentials of the client, results are never shared between cache IDs.
+	WrapClient(client Client, cacheID string) Client
+}
+
+// describeCacheEntry holds a cached describe output and the time it expires.
+type describeCacheEntry struct {
+	output    interface{}
+	expiresAt time.Time
+}
+
+// describeCache holds cached describe outputs keyed by cache ID, resource and input. Access is synchronized via rwmutex.
+type describeCache struct {
+	ttls    DescribeCacheTTLs
+	entries map[string]describeCacheEntry
+	rwmutex sync.RWMutex
+}
+
+// NewDescribeCache creates an empty describe cache.
+func NewDescribeCache(ttls DescribeCacheTTLs) DescribeCache {
+	return &describeCache{
+		ttls:    ttls,
+		entries: map[string]describeCacheEntry{},
+	}
+}
+
+// WrapClient returns a Client whose cacheable describe calls go through the cache.
+func (c *describeCache) WrapClient(client Client, cacheID string) Client {
+	return &cachedClient{
+		Client:  client,
+		cache:   c,
+		cacheID: cacheID,
+	}
+}
+
+func (c *describeCache) ttl(resource string) time.Duration {
+	switch resource {
+	case describeImagesResource:
+		return c.ttls.Images
+	case describeSubnetsResource:
+		return c.ttls.Subnets
+	case describeAvailabilityZonesResource:
+		return c.ttls.AvailabilityZones
+	case describeSecurityGroupsResource:
+		return c.ttls.SecurityGroups
+	case describeVpcsResource, describeDHCPOptionsResource:
+		return c.ttls.DHCPOptions
+	}
+	return 0
+}
+
+func (c *describeCache) get(key string) (interface{}, bool) {
+	c.rwmutex.RLock()
+	defer c.rwmutex.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.output, true
+}
+
+func (c *describeCache) set(key string, output interface{}, ttl time.Duration) {
+	c.rwmutex.Lock()
+	defer c.rwmutex.Unlock()
+
+	// Entries are only added on a miss, which is rare enough to drop expired entries here.
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = describeCacheEntry{output: output, expiresAt: now.Add(ttl)}
+}
+
+// cachedDescribe answers a describe call from the cache, or calls AWS and caches the successful result.
+func cachedDescribe[I any, O any](ctx context.Context, c *cachedClient, resource string, input I, describe func(context.Context, I) (O, error)) (O, error) {
+	ttl := c.cache.ttl(resource)
+	if ttl <= 0 {
+		return describe(ctx, input)
+	}
+
+	rawInput, err := json.Marshal(input)
+	if err != nil {
+		// Inputs are plain structs, this should never happen, but caching is only an optimisation.
+		klog.V(4).Infof("Unable to build describe cache key for %s: %v", resource, err)
+		return describe(ctx, input)
+	}
+	key := fmt.Sprintf("%s/%s/%s", c.cacheID, resource, rawInput)
+
+	if output, ok := c.cache.get(key); ok {
+		describeCacheRequests.WithLabelValues(resource, describeCacheHit).Inc()
+		klog.V(4).Infof("Using cached %s describe result", resource)
+		return output.(O), nil
+	}
+	describeCacheRequests.WithLabelValues(resource, describeCacheMiss).Inc()
+
+	output, err := describe(ctx, input)
+	if err != nil {
+		return output, err
+	}
+
+	c.cache.set(key, output, ttl)
+	return output, nil
+}
+
+// cachedClient is a Client which answers describe calls of rarely changing resources from a describeCache.
+// All other calls are passed through to the wrapped Client.
+type cachedClient struct {
+	Client
+
+	cache   *describeCache
+	cacheID string
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped client, if it supports it.
+func (c *cachedClient) CloseIdleConnections() {
+	if closer, ok := c.Client.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (c *cachedClient) DescribeImages(ctx context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	return cachedDescribe(ctx, c, describeImagesResource, input, c.Client.DescribeImages)
+}
+
+func (c *cachedClient) DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return cachedDescribe(ctx, c, describeSubnetsResource, input, c.Client.DescribeSubnets)
+}
+
+func (c *cachedClient) DescribeAvailabilityZones(ctx context.Context, input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return cachedDescribe(ctx, c, describeAvailabilityZonesResource, input, c.Client.DescribeAvailabilityZones)
+}
+
+func (c *cachedClient) DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return cachedDescribe(ctx, c, describeSecurityGroupsResource, input, c.Client.DescribeSecurityGroups)
+}
+
+func (c *cachedClient) DescribeVpcs(ctx context.Context, input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return cachedDescribe(ctx, c, describeVpcsResource, input, c.Client.DescribeVpcs)
+}
+
+func (c *cachedClient) DescribeDHCPOptions(ctx context.Context, input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
+	return cachedDescribe(ctx, c, describeDHCPOptionsResource, input, c.Client.DescribeDHCPOptions)
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// countingClient counts the describe calls that reach AWS.
+type countingClient struct {
+	Client
+
+	subnetsCalls int
+	imagesCalls  int
+	err          error
+}
+
+func (c *countingClient) DescribeSubnets(_ context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	c.subnetsCalls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{{SubnetId: input.SubnetIds[0]}}}, nil
+}
+
+func (c *countingClient) DescribeImages(_ context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	c.imagesCalls++
+	return &ec2.DescribeImagesOutput{}, nil
+}
+
+func TestDescribeCache(t *testing.T) {
+	subnetInput := func(id string) *ec2.DescribeSubnetsInput {
+		return &ec2.DescribeSubnetsInput{SubnetIds: []*string{aws.String(id)}}
+	}
+
+	t.Run("repeated calls are answered from the cache", func(t *testing.T) {
+		stub := &countingClient{}
+		client := NewDescribeCache(DefaultDescribeCacheTTLs()).WrapClient(stub, "us-east-1")
+
+		for i := 0; i < 3; i++ {
+			output, err := client.DescribeSubnets(context.Background(), subnetInput("subnet-1"))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if aws.StringValue(output.Subnets[0].SubnetId) != "subnet-1" {
+				t.Errorf("Expected subnet-1, got %v", output.Subnets)
+			}
+		}
+		if stub.subnetsCalls != 1 {
+			t.Errorf("Expected 1 DescribeSubnets call, got %d", stub.subnetsCalls)
+		}
+
+		if _, err := client.DescribeSubnets(context.Background(), subnetInput("subnet-2")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stub.subnetsCalls != 2 {
+			t.Errorf("Expected a different input to miss the cache, got %d calls", stub.subnetsCalls)
+		}
+	})
+
+	t.Run("zero TTL disables caching", func(t *testing.T) {
+		stub := &countingClient{}
+		ttls := DefaultDescribeCacheTTLs()
+		ttls.Images = 0
+		client := NewDescribeCache(ttls).WrapClient(stub, "us-east-1")
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.DescribeImages(context.Background(), &ec2.DescribeImagesInput{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if stub.imagesCalls != 2 {
+			t.Errorf("Expected 2 DescribeImages calls, got %d", stub.imagesCalls)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		stub := &countingClient{err: errors.New("RequestLimitExceeded")}
+		client := NewDescribeCache(DefaultDescribeCacheTTLs()).WrapClient(stub, "us-east-1")
+
+		if _, err := client.DescribeSubnets(context.Background(), subnetInput("subnet-1")); err == nil {
+			t.Fatal("Expected an error")
+		}
+		stub.err = nil
+		if _, err := client.DescribeSubnets(context.Background(), subnetInput("subnet-1")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stub.subnetsCalls != 2 {
+			t.Errorf("Expected 2 DescribeSubnets calls, got %d", stub.subnetsCalls)
+		}
+	})
+
+	t.Run("results are not shared between cache IDs", func(t *testing.T) {
+		stub := &countingClient{}
+		cache := NewDescribeCache(DefaultDescribeCacheTTLs())
+
+		for _, cacheID := range []string{"us-east-1/ns/a", "us-east-1/ns/b"} {
+			if _, err := cache.WrapClient(stub, cacheID).DescribeSubnets(context.Background(), subnetInput("subnet-1")); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if stub.subnetsCalls != 2 {
+			t.Errorf("Expected 2 DescribeSubnets calls, got %d", stub.subnetsCalls)
+		}
+	})
+
+	t.Run("expired entries are refreshed", func(t *testing.T) {
+		stub := &countingClient{}
+		ttls := DefaultDescribeCacheTTLs()
+		ttls.Subnets = time.Millisecond
+		client := NewDescribeCache(ttls).WrapClient(stub, "us-east-1")
+
+		if _, err := client.DescribeSubnets(context.Background(), subnetInput("subnet-1")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+		if _, err := client.DescribeSubnets(context.Background(), subnetInput("subnet-1")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stub.subnetsCalls != 2 {
+			t.Errorf("Expected 2 DescribeSubnets calls, got %d", stub.subnetsCalls)
+		}
+	})
+}
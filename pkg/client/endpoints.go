@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/machine-api-provider-aws/pkg/version"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// cloudEndpointsModelKey is the key in the kube cloud config ConfigMap holding additional AWS partitions
+	// in the endpoints model format of the AWS SDK. It allows regions unknown to the SDK, such as ISO or
+	// sovereign regions, to be resolved.
+	cloudEndpointsModelKey = "endpoints.json"
+
+	// cloudDualStackEndpointsKey is the key in the kube cloud config ConfigMap which opts dual-stack clusters into
+	// the dual-stack endpoints of AWS services, when set to "true".
+	cloudDualStackEndpointsKey = "dualstack-endpoints"
+
+	fipsEndpointFeature      = "fips"
+	dualStackEndpointFeature = "dualstack"
+)
+
+// fipsEnabledFile reports whether the host runs in FIPS mode. It is a variable so we can reference it in unit tests.
+var fipsEnabledFile = "/proc/sys/crypto/fips_enabled"
+
+// isFIPSEnabled returns true if the host runs in FIPS mode, in which case FIPS endpoints must be used.
+func isFIPSEnabled() bool {
+	data, err := os.ReadFile(fipsEnabledFile)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(data)) == "1"
+}
+
+// isDualStack returns true if the cluster was installed with dual-stack networking.
+func isDualStack(infra *configv1.Infrastructure) bool {
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.AWS == nil {
+		return false
+	}
+	return infra.Status.PlatformStatus.AWS.IPFamily == configv1.DualStackIPv6Primary ||
+		infra.Status.PlatformStatus.AWS.IPFamily == configv1.DualStackIPv4Primary
+}
+
+// useDualStackEndpoints returns true if the cluster was installed with dual-stack networking and the kube cloud
+// config opts into dual-stack endpoints.
+func useDualStackEndpoints(infra *configv1.Infrastructure, cm *corev1.ConfigMap) bool {
+	return isDualStack(infra) && cm != nil && strings.TrimSpace(cm.Data[cloudDualStackEndpointsKey]) == "true"
+}
+
+// kubeCloudConfig returns the kube cloud config ConfigMap, or nil if it does not exist.
+func kubeCloudConfig(ctx context.Context, configManagedClient client.Client) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	switch err := configManagedClient.Get(
+		ctx,
+		client.ObjectKey{Namespace: KubeCloudConfigNamespace, Name: kubeCloudConfigName},
+		cm,
+	); {
+	case apimachineryerrors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get kube-cloud-config ConfigMap: %w", err)
+	}
+	return cm, nil
+}
+
+// customPartitions loads the additional partitions configured in the kube cloud config ConfigMap, if any.
+func customPartitions(cm *corev1.ConfigMap) ([]endpoints.Partition, error) {
+	if cm == nil {
+		return nil, nil
+	}
+
+	model, ok := cm.Data[cloudEndpointsModelKey]
+	if !ok {
+		return nil, nil
+	}
+
+	resolver, err := endpoints.DecodeModel(strings.NewReader(model))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s from kube-cloud-config ConfigMap: %w", cloudEndpointsModelKey, err)
+	}
+
+	enum, ok := resolver.(endpoints.EnumPartitions)
+	if !ok {
+		return nil, fmt.Errorf("failed to list partitions of %s from kube-cloud-config ConfigMap", cloudEndpointsModelKey)
+	}
+	return enum.Partitions(), nil
+}
+
+// variantResolver returns a resolver preferring the FIPS and dual-stack variants of the endpoints resolved by
+// the given resolver. A variant is only used when the partition metadata lists it for the service and region,
+// FIPS endpoints for instance only exist in a few regions, otherwise the resolver falls back to the standard endpoint.
+func variantResolver(resolver endpoints.Resolver, fips, dualStack bool) endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		for _, variant := range []struct{ fips, dualStack bool }{
+			{fips: fips, dualStack: dualStack},
+			{fips: fips},
+			{dualStack: dualStack},
+		} {
+			if !variant.fips && !variant.dualStack {
+				continue
+			}
+			resolved, err := resolver.EndpointFor(service, region, append(optFns, endpoints.StrictMatchingOption, func(o *endpoints.Options) {
+				if variant.fips {
+					o.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+				}
+				if variant.dualStack {
+					o.UseDualStackEndpoint = endpoints.DualStackEndpointStateEnabled
+				}
+			})...)
+			if err == nil {
+				return resolved, nil
+			}
+		}
+		if fips {
+			klog.V(3).Infof("No FIPS endpoint for service %s in region %s, using the standard endpoint", service, region)
+		}
+		return resolver.EndpointFor(service, region, optFns...)
+	})
+}
+
+// providerUserAgentHandler returns a named handler that adds cluster-api-provider-aws version information
+// and the given endpoint features to requests made by the AWS SDK.
+// It replaces addProviderVersionToUserAgent as both share the same name.
+func providerUserAgentHandler(features ...string) request.NamedHandler {
+	return request.NamedHandler{
+		Name: addProviderVersionToUserAgent.Name,
+		Fn:   request.MakeAddToUserAgentHandler("openshift.io cluster-api-provider-aws", version.Version.String(), features...),
+	}
+}
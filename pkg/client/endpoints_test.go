@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testEndpointsModel = `{
+  "version": 3,
+  "partitions": [{
+    "partition": "aws-test",
+    "partitionName": "AWS Test",
+    "dnsSuffix": "test.example",
+    "regionRegex": "^xx\\-test\\-\\d+$",
+    "defaults": {
+      "hostname": "{service}.{region}.{dnsSuffix}",
+      "protocols": ["https"],
+      "signatureVersions": ["v4"]
+    },
+    "regions": {"xx-test-1": {"description": "Test region"}},
+    "services": {"ec2": {"endpoints": {"xx-test-1": {}}}}
+  }]
+}`
+
+func TestResolveEndpoints(t *testing.T) {
+	cases := []struct {
+		name             string
+		region           string
+		fips             bool
+		ipFamily         configv1.IPFamilyType
+		dualStackOptIn   bool
+		endpointsModel   string
+		serviceEndpoints []configv1.AWSServiceEndpoint
+		expectedFeatures []string
+		expectedEC2URL   string
+	}{
+		{
+			name:             "defaults",
+			region:           "us-east-1",
+			expectedFeatures: []string{},
+			expectedEC2URL:   "https://ec2.us-east-1.amazonaws.com",
+		},
+		{
+			name:             "FIPS mode",
+			region:           "us-east-1",
+			fips:             true,
+			expectedFeatures: []string{"fips"},
+			expectedEC2URL:   "https://ec2-fips.us-east-1.amazonaws.com",
+		},
+		{
+			name:             "FIPS mode in a region without FIPS endpoints",
+			region:           "eu-west-1",
+			fips:             true,
+			expectedFeatures: []string{"fips"},
+			expectedEC2URL:   "https://ec2.eu-west-1.amazonaws.com",
+		},
+		{
+			name:             "dual-stack cluster without opting into dual-stack endpoints",
+			region:           "us-east-1",
+			ipFamily:         configv1.DualStackIPv4Primary,
+			expectedFeatures: []string{},
+			expectedEC2URL:   "https://ec2.us-east-1.amazonaws.com",
+		},
+		{
+			name:             "dual-stack cluster opting into dual-stack endpoints",
+			region:           "us-east-1",
+			ipFamily:         configv1.DualStackIPv4Primary,
+			dualStackOptIn:   true,
+			expectedFeatures: []string{"dualstack"},
+			expectedEC2URL:   "https://ec2.us-east-1.api.aws",
+		},
+		{
+			name:             "single-stack cluster opting into dual-stack endpoints",
+			region:           "us-east-1",
+			dualStackOptIn:   true,
+			expectedFeatures: []string{},
+			expectedEC2URL:   "https://ec2.us-east-1.amazonaws.com",
+		},
+		{
+			name:             "FIPS mode in a dual-stack cluster",
+			region:           "us-east-1",
+			fips:             true,
+			ipFamily:         configv1.DualStackIPv4Primary,
+			dualStackOptIn:   true,
+			expectedFeatures: []string{"fips", "dualstack"},
+			expectedEC2URL:   "https://ec2-fips.us-east-1.amazonaws.com",
+		},
+		{
+			name:             "custom partition",
+			region:           "xx-test-1",
+			endpointsModel:   testEndpointsModel,
+			expectedFeatures: []string{"partition=aws-test"},
+			expectedEC2URL:   "https://ec2.xx-test-1.test.example",
+		},
+		{
+			name:           "service endpoints take precedence over custom partitions",
+			region:         "xx-test-1",
+			endpointsModel: testEndpointsModel,
+			serviceEndpoints: []configv1.AWSServiceEndpoint{
+				{Name: "ec2", URL: "https://ec2.custom.example"},
+			},
+			expectedFeatures: []string{"partition=aws-test"},
+			expectedEC2URL:   "https://ec2.custom.example",
+		},
+		{
+			name:           "service endpoints are used without the FIPS and dual-stack variants",
+			region:         "us-east-1",
+			fips:           true,
+			ipFamily:       configv1.DualStackIPv4Primary,
+			dualStackOptIn: true,
+			serviceEndpoints: []configv1.AWSServiceEndpoint{
+				{Name: "ec2", URL: "https://ec2.custom.example"},
+			},
+			expectedFeatures: []string{"fips", "dualstack"},
+			expectedEC2URL:   "https://ec2.custom.example",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fipsFile := filepath.Join(t.TempDir(), "fips_enabled")
+			fipsValue := "0\n"
+			if tc.fips {
+				fipsValue = "1\n"
+			}
+			if err := os.WriteFile(fipsFile, []byte(fipsValue), 0600); err != nil {
+				t.Fatalf("unable to write FIPS file: %v", err)
+			}
+			defer func(original string) { fipsEnabledFile = original }(fipsEnabledFile)
+			fipsEnabledFile = fipsFile
+
+			scheme := runtime.NewScheme()
+			if err := configv1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+
+			infra := &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: GlobalInfrastuctureName},
+				Status: configv1.InfrastructureStatus{
+					PlatformStatus: &configv1.PlatformStatus{
+						AWS: &configv1.AWSPlatformStatus{
+							IPFamily:         tc.ipFamily,
+							ServiceEndpoints: tc.serviceEndpoints,
+						},
+					},
+				},
+			}
+			cloudConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: KubeCloudConfigNamespace,
+					Name:      kubeCloudConfigName,
+				},
+				Data: map[string]string{},
+			}
+			if tc.endpointsModel != "" {
+				cloudConfig.Data[cloudEndpointsModelKey] = tc.endpointsModel
+			}
+			if tc.dualStackOptIn {
+				cloudConfig.Data[cloudDualStackEndpointsKey] = "true"
+			}
+			resources := []runtime.Object{infra, cloudConfig}
+			ctrlRuntimeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(resources...).Build()
+
+			awsConfig := &aws.Config{Region: aws.String(tc.region)}
+			features, err := resolveEndpoints(context.Background(), awsConfig, ctrlRuntimeClient, ctrlRuntimeClient, tc.region)
+			if err != nil {
+				t.Fatalf("unexpected error from resolveEndpoints: %v", err)
+			}
+
+			if !reflect.DeepEqual(features, tc.expectedFeatures) {
+				t.Errorf("unexpected features: expected=%v; got %v", tc.expectedFeatures, features)
+			}
+
+			resolver := awsConfig.EndpointResolver
+			if resolver == nil {
+				resolver = endpoints.DefaultResolver()
+			}
+			resolved, err := resolver.EndpointFor("ec2", tc.region)
+			if err != nil {
+				t.Fatalf("unexpected error resolving the EC2 endpoint: %v", err)
+			}
+			if resolved.URL != tc.expectedEC2URL {
+				t.Errorf("unexpected EC2 endpoint: expected=%s; got %s", tc.expectedEC2URL, resolved.URL)
+			}
+		})
+	}
+}
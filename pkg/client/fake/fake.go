@@ -1,6 +1,7 @@
 package fake
 
 import (
+	"context"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -15,7 +16,7 @@ import (
 type awsClient struct {
 }
 
-func (c *awsClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+func (c *awsClient) DescribeImages(_ context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
 	return &ec2.DescribeImagesOutput{
 		Images: []*ec2.Image{
 			{
@@ -25,11 +26,11 @@ func (c *awsClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.Describ
 	}, nil
 }
 
-func (c *awsClient) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+func (c *awsClient) DescribeVpcs(_ context.Context, input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
 	return machine.StubDescribeVPCs()
 }
 
-func (c *awsClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+func (c *awsClient) DescribeSubnets(_ context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
 	return &ec2.DescribeSubnetsOutput{
 		Subnets: []*ec2.Subnet{
 			{
@@ -39,11 +40,11 @@ func (c *awsClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.Descr
 	}, nil
 }
 
-func (c *awsClient) DescribeAvailabilityZones(*ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+func (c *awsClient) DescribeAvailabilityZones(_ context.Context, _ *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
 	return &ec2.DescribeAvailabilityZonesOutput{}, nil
 }
 
-func (c *awsClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+func (c *awsClient) DescribeSecurityGroups(_ context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
 	return &ec2.DescribeSecurityGroupsOutput{
 		SecurityGroups: []*ec2.SecurityGroup{
 			{
@@ -53,15 +54,15 @@ func (c *awsClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInpu
 	}, nil
 }
 
-func (c *awsClient) DescribePlacementGroups(*ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
+func (c *awsClient) DescribePlacementGroups(_ context.Context, _ *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
 	return &ec2.DescribePlacementGroupsOutput{}, nil
 }
 
-func (c *awsClient) DescribeDHCPOptions(input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
+func (c *awsClient) DescribeDHCPOptions(_ context.Context, input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
 	return machine.StubDescribeDHCPOptions()
 }
 
-func (c *awsClient) RunInstances(input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+func (c *awsClient) RunInstances(_ context.Context, input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
 	return &ec2.Reservation{
 		Instances: []*ec2.Instance{
 			{
@@ -75,7 +76,7 @@ func (c *awsClient) RunInstances(input *ec2.RunInstancesInput) (*ec2.Reservation
 	}, nil
 }
 
-func (c *awsClient) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+func (c *awsClient) DescribeInstances(_ context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
 	return &ec2.DescribeInstancesOutput{
 		Reservations: []*ec2.Reservation{
 			{
@@ -95,7 +96,7 @@ func (c *awsClient) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.D
 	}, nil
 }
 
-func (c *awsClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+func (c *awsClient) DescribeInstanceTypes(_ context.Context, input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
 	return &ec2.DescribeInstanceTypesOutput{
 		InstanceTypes: []*ec2.InstanceTypeInfo{
 			{
@@ -147,6 +148,10 @@ func (c *awsClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput)
 					},
 					TotalGpuMemoryInMiB: aws.Int64(196608),
 				},
+				NetworkInfo: &ec2.NetworkInfo{
+					MaximumNetworkInterfaces:  aws.Int64(8),
+					Ipv4AddressesPerInterface: aws.Int64(30),
+				},
 				ProcessorInfo: &ec2.ProcessorInfo{
 					SupportedArchitectures: []*string{
 						aws.String("amd64"),
@@ -222,74 +227,112 @@ func (c *awsClient) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput)
 	}, nil
 }
 
-func (c *awsClient) DescribeHosts(input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error) {
+func (c *awsClient) DescribeInstanceTypeOfferings(_ context.Context, input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	// Feel free to extend the returned values
+	offerings := []*ec2.InstanceTypeOffering{}
+	for _, instanceType := range []string{"m4.large", "a1.2xlarge", "p2.16xlarge", "m6g.4xlarge"} {
+		offerings = append(offerings, &ec2.InstanceTypeOffering{
+			InstanceType: aws.String(instanceType),
+			Location:     aws.String("us-east-1a"),
+			LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		})
+	}
+	return &ec2.DescribeInstanceTypeOfferingsOutput{InstanceTypeOfferings: offerings}, nil
+}
+
+func (c *awsClient) DescribeSpotPriceHistory(_ context.Context, input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	// Feel free to extend the returned values
+	return &ec2.DescribeSpotPriceHistoryOutput{}, nil
+}
+
+func (c *awsClient) DescribeCapacityReservations(_ context.Context, input *ec2.DescribeCapacityReservationsInput) (*ec2.DescribeCapacityReservationsOutput, error) {
+	// Feel free to extend the returned values
+	return &ec2.DescribeCapacityReservationsOutput{}, nil
+}
+
+func (c *awsClient) DescribeHosts(_ context.Context, input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error) {
 	return &ec2.DescribeHostsOutput{}, nil
 }
 
-func (c *awsClient) AllocateHosts(input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
+func (c *awsClient) AllocateHosts(_ context.Context, input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
 	return &ec2.AllocateHostsOutput{
 		HostIds: []*string{aws.String("h-0123456789abcdef0")},
 	}, nil
 }
 
-func (c *awsClient) ReleaseHosts(input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
+func (c *awsClient) ReleaseHosts(_ context.Context, input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
 	return &ec2.ReleaseHostsOutput{
 		Successful:   input.HostIds,
 		Unsuccessful: []*ec2.UnsuccessfulItem{},
 	}, nil
 }
 
-func (c *awsClient) TerminateInstances(input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+func (c *awsClient) TerminateInstances(_ context.Context, input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
 	// Feel free to extend the returned values
 	return &ec2.TerminateInstancesOutput{}, nil
 }
 
-func (c *awsClient) DescribeVolumes(input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+func (c *awsClient) DescribeSpotInstanceRequests(_ context.Context, input *ec2.DescribeSpotInstanceRequestsInput) (*ec2.DescribeSpotInstanceRequestsOutput, error) {
+	// Feel free to extend the returned values
+	return &ec2.DescribeSpotInstanceRequestsOutput{}, nil
+}
+
+func (c *awsClient) CancelSpotInstanceRequests(_ context.Context, input *ec2.CancelSpotInstanceRequestsInput) (*ec2.CancelSpotInstanceRequestsOutput, error) {
+	// Feel free to extend the returned values
+	return &ec2.CancelSpotInstanceRequestsOutput{}, nil
+}
+
+func (c *awsClient) DescribeVolumes(_ context.Context, input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
 	// Feel free to extend the returned values
 	return &ec2.DescribeVolumesOutput{}, nil
 }
 
-func (c *awsClient) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+func (c *awsClient) CreateTags(_ context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
 	return &ec2.CreateTagsOutput{}, nil
 }
 
-func (c *awsClient) CreatePlacementGroup(input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
+func (c *awsClient) CreatePlacementGroup(_ context.Context, input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
 	return &ec2.CreatePlacementGroupOutput{}, nil
 }
 
-func (c *awsClient) DeletePlacementGroup(input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
+func (c *awsClient) DeletePlacementGroup(_ context.Context, input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
 	return &ec2.DeletePlacementGroupOutput{}, nil
 }
 
-func (c *awsClient) RegisterInstancesWithLoadBalancer(input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
+func (c *awsClient) RegisterInstancesWithLoadBalancer(_ context.Context, input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
 	// Feel free to extend the returned values
 	return &elb.RegisterInstancesWithLoadBalancerOutput{}, nil
 }
 
-func (c *awsClient) ELBv2DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+func (c *awsClient) ELBv2DescribeLoadBalancers(_ context.Context, _ *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
 	// Feel free to extend the returned values
 	return &elbv2.DescribeLoadBalancersOutput{}, nil
 }
 
-func (c *awsClient) ELBv2DescribeTargetGroups(*elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+func (c *awsClient) ELBv2DescribeTargetGroups(_ context.Context, _ *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
 	// Feel free to extend the returned values
 	return &elbv2.DescribeTargetGroupsOutput{}, nil
 }
 
-func (c *awsClient) ELBv2DescribeTargetHealth(*elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+func (c *awsClient) ELBv2DescribeTargetHealth(_ context.Context, _ *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
 	return &elbv2.DescribeTargetHealthOutput{}, nil
 }
 
-func (c *awsClient) ELBv2RegisterTargets(*elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+func (c *awsClient) ELBv2RegisterTargets(_ context.Context, _ *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
 	// Feel free to extend the returned values
 	return &elbv2.RegisterTargetsOutput{}, nil
 }
 
-func (c *awsClient) ELBv2DeregisterTargets(*elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
+func (c *awsClient) ELBv2DeregisterTargets(_ context.Context, _ *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
 	// Feel free to extend the returned values
 	return &elbv2.DeregisterTargetsOutput{}, nil
 }
 
+func (c *awsClient) GetServiceQuota(_ context.Context, input *client.GetServiceQuotaInput) (*client.GetServiceQuotaOutput, error) {
+	// Feel free to extend the returned values
+	return &client.GetServiceQuotaOutput{}, nil
+}
+
 // NewClient creates our client wrapper object for the actual AWS clients we use.
 // For authentication the underlying clients will use either the cluster AWS credentials
 // secret if defined (i.e. in the root cluster),
@@ -0,0 +1,158 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// redactedValue replaces the values of redacted fields.
+	redactedValue = "REDACTED"
+	// redactedAccountID replaces AWS account IDs.
+	redactedAccountID = "000000000000"
+)
+
+var (
+	// accountIDRegexp matches AWS account IDs, standalone or within ARNs.
+	accountIDRegexp = regexp.MustCompile(`\b\d{12}\b`)
+	// encodedMessageRegexp matches the encoded authorization failure messages of UnauthorizedOperation errors,
+	// which can be decoded by anyone with sts:DecodeAuthorizationMessage into the full request context.
+	encodedMessageRegexp = regexp.MustCompile(`(?i)(encoded authorization failure message:\s*)\S+`)
+)
+
+// Cassette is an ordered list of recorded AWS interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded AWS request and its outcome.
+// Request and Response hold the JSON encoding of the SDK input and output types.
+type Interaction struct {
+	// Operation is the name of the awsclient.Client method, e.g. DescribeInstances.
+	Operation string          `json:"operation"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+}
+
+// Error is a recorded AWS error.
+type Error struct {
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"statusCode,omitempty"`
+}
+
+// LoadCassette reads a cassette from a JSON or YAML file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+
+	cassette := &Cassette{}
+	// YAML is a superset of JSON, so this handles both formats.
+	if err := yaml.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to a file. Files with a .json extension are written as JSON, all others as YAML.
+func (c *Cassette) Save(path string) error {
+	var data []byte
+	var err error
+	if filepath.Ext(path) == ".json" {
+		data, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		data, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette %s: %w", path, err)
+	}
+	return nil
+}
+
+// Redactor removes sensitive data from recorded requests, responses and error messages.
+// AWS account IDs are always redacted.
+type Redactor struct {
+	// Fields lists the names of the fields whose values are replaced, at any depth. Names are case insensitive.
+	Fields []string
+}
+
+// DefaultRedactor redacts the instance user data, which holds the ignition config and its secrets, and credentials.
+// Only the SDK inputs and outputs are recorded, not the HTTP requests, so the signature, session token and instance
+// metadata token headers are never part of a cassette. They are redacted as well in case they end up in a field.
+func DefaultRedactor() Redactor {
+	return Redactor{
+		Fields: []string{"UserData", "Authorization", "X-Amz-Security-Token", "X-Aws-Ec2-Metadata-Token",
+			"SecretAccessKey", "SessionToken"},
+	}
+}
+
+// Redact returns the redacted JSON encoding of v.
+func (r Redactor) Redact(v interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool, len(r.Fields))
+	for _, field := range r.Fields {
+		fields[strings.ToLower(field)] = true
+	}
+
+	return json.Marshal(redactValue(decoded, fields))
+}
+
+// RedactMessage returns the message with AWS account IDs and encoded authorization failure messages redacted.
+func (r Redactor) RedactMessage(message string) string {
+	return redactString(message)
+}
+
+func redactString(s string) string {
+	s = encodedMessageRegexp.ReplaceAllString(s, "${1}"+redactedValue)
+	return accountIDRegexp.ReplaceAllString(s, redactedAccountID)
+}
+
+func redactValue(v interface{}, fields map[string]bool) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			// The SDK types have no omitempty tags, drop unset fields to keep cassettes readable.
+			if nested == nil {
+				delete(value, key)
+				continue
+			}
+			if fields[strings.ToLower(key)] {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = redactValue(nested, fields)
+		}
+		return value
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redactValue(nested, fields)
+		}
+		return value
+	case string:
+		return redactString(value)
+	default:
+		return value
+	}
+}
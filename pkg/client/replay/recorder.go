@@ -0,0 +1,293 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultMaxInteractions is the default number of interactions kept by a Recorder.
+const DefaultMaxInteractions = 10000
+
+// Recorder records the AWS requests made through the clients it wraps, and their outcomes, into a cassette.
+// A single Recorder may wrap many clients, interactions are recorded in the order they complete.
+// Only the latest interactions are kept, so that the memory used by long recordings is bounded.
+type Recorder struct {
+	redactor        Redactor
+	maxInteractions int
+	cassette        Cassette
+	mutex           sync.Mutex
+}
+
+// NewRecorder creates a Recorder with an empty cassette, keeping up to maxInteractions interactions.
+// All interactions are kept if maxInteractions is 0.
+func NewRecorder(redactor Redactor, maxInteractions int) *Recorder {
+	return &Recorder{
+		redactor:        redactor,
+		maxInteractions: maxInteractions,
+	}
+}
+
+// WrapClient returns a Client that records all requests to the given client.
+func (r *Recorder) WrapClient(client awsclient.Client) awsclient.Client {
+	return &recordingClient{
+		client:   client,
+		recorder: r,
+	}
+}
+
+// WrapClientBuilder returns a client builder whose clients record all requests.
+func (r *Recorder) WrapClientBuilder(builder awsclient.AwsClientBuilderFuncType) awsclient.AwsClientBuilderFuncType {
+	return func(ctx context.Context, client runtimeclient.Client, secretName, namespace, region string, configManagedClient runtimeclient.Client, regionCache awsclient.RegionCache) (awsclient.Client, error) {
+		awsClient, err := builder(ctx, client, secretName, namespace, region, configManagedClient, regionCache)
+		if err != nil {
+			return nil, err
+		}
+		return r.WrapClient(awsClient), nil
+	}
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return &Cassette{
+		Interactions: append([]Interaction{}, r.cassette.Interactions...),
+	}
+}
+
+// Save writes the interactions recorded so far to a file.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func (r *Recorder) add(interaction Interaction) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if r.maxInteractions > 0 && len(r.cassette.Interactions) > r.maxInteractions {
+		// append reallocates once the capacity is reached, copying only the interactions which are kept.
+		r.cassette.Interactions = r.cassette.Interactions[len(r.cassette.Interactions)-r.maxInteractions:]
+	}
+}
+
+// CassetteSaver is a manager Runnable saving the interactions of a Recorder to a file at an interval, and once more
+// when it is stopped, e.g. on SIGTERM. An abrupt exit of the process only loses the interactions of the last interval.
+type CassetteSaver struct {
+	Recorder *Recorder
+	Path     string
+	Interval time.Duration
+}
+
+// Start saves the cassette until the context is done.
+func (s *CassetteSaver) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Recorder.Save(s.Path); err != nil {
+				klog.Errorf("Error saving AWS cassette: %v", err)
+			}
+		case <-ctx.Done():
+			return s.Recorder.Save(s.Path)
+		}
+	}
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable, requests made by all replicas are recorded.
+func (s *CassetteSaver) NeedLeaderElection() bool {
+	return false
+}
+
+// recordError converts an error returned by the AWS SDK into its recorded form, with its message redacted.
+func recordError(err error, redactor Redactor) *Error {
+	recorded := &Error{Message: err.Error()}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		recorded.Code = awsErr.Code()
+		recorded.Message = awsErr.Message()
+	}
+
+	var requestFailure awserr.RequestFailure
+	if errors.As(err, &requestFailure) {
+		recorded.StatusCode = requestFailure.StatusCode()
+	}
+	recorded.Message = redactor.RedactMessage(recorded.Message)
+	return recorded
+}
+
+// record calls AWS and records the redacted request and outcome.
+// Recording failures are logged but never change the outcome of the call.
+func record[I any, O any](ctx context.Context, c *recordingClient, operation string, input I, call func(context.Context, I) (O, error)) (O, error) {
+	output, err := call(ctx, input)
+
+	interaction := Interaction{Operation: operation}
+	request, redactErr := c.recorder.redactor.Redact(input)
+	if redactErr != nil {
+		klog.Errorf("Unable to record %s request: %v", operation, redactErr)
+		return output, err
+	}
+	interaction.Request = request
+
+	if err != nil {
+		interaction.Error = recordError(err, c.recorder.redactor)
+	} else {
+		response, redactErr := c.recorder.redactor.Redact(output)
+		if redactErr != nil {
+			klog.Errorf("Unable to record %s response: %v", operation, redactErr)
+			return output, err
+		}
+		interaction.Response = response
+	}
+
+	c.recorder.add(interaction)
+	return output, err
+}
+
+// recordingClient is a Client which records all requests to the wrapped Client.
+// All methods are implemented explicitly, so that no request can bypass the recorder.
+type recordingClient struct {
+	client   awsclient.Client
+	recorder *Recorder
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped client, if it supports it.
+func (c *recordingClient) CloseIdleConnections() {
+	if closer, ok := c.client.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (c *recordingClient) DescribeImages(ctx context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	return record(ctx, c, "DescribeImages", input, c.client.DescribeImages)
+}
+
+func (c *recordingClient) DescribeDHCPOptions(ctx context.Context, input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
+	return record(ctx, c, "DescribeDHCPOptions", input, c.client.DescribeDHCPOptions)
+}
+
+func (c *recordingClient) DescribeVpcs(ctx context.Context, input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return record(ctx, c, "DescribeVpcs", input, c.client.DescribeVpcs)
+}
+
+func (c *recordingClient) DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return record(ctx, c, "DescribeSubnets", input, c.client.DescribeSubnets)
+}
+
+func (c *recordingClient) DescribeAvailabilityZones(ctx context.Context, input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return record(ctx, c, "DescribeAvailabilityZones", input, c.client.DescribeAvailabilityZones)
+}
+
+func (c *recordingClient) DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return record(ctx, c, "DescribeSecurityGroups", input, c.client.DescribeSecurityGroups)
+}
+
+func (c *recordingClient) DescribePlacementGroups(ctx context.Context, input *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
+	return record(ctx, c, "DescribePlacementGroups", input, c.client.DescribePlacementGroups)
+}
+
+func (c *recordingClient) DescribeInstanceTypes(ctx context.Context, input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	return record(ctx, c, "DescribeInstanceTypes", input, c.client.DescribeInstanceTypes)
+}
+
+func (c *recordingClient) DescribeInstanceTypeOfferings(ctx context.Context, input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	return record(ctx, c, "DescribeInstanceTypeOfferings", input, c.client.DescribeInstanceTypeOfferings)
+}
+
+func (c *recordingClient) DescribeSpotPriceHistory(ctx context.Context, input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	return record(ctx, c, "DescribeSpotPriceHistory", input, c.client.DescribeSpotPriceHistory)
+}
+
+func (c *recordingClient) DescribeCapacityReservations(ctx context.Context, input *ec2.DescribeCapacityReservationsInput) (*ec2.DescribeCapacityReservationsOutput, error) {
+	return record(ctx, c, "DescribeCapacityReservations", input, c.client.DescribeCapacityReservations)
+}
+
+func (c *recordingClient) DescribeHosts(ctx context.Context, input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error) {
+	return record(ctx, c, "DescribeHosts", input, c.client.DescribeHosts)
+}
+
+func (c *recordingClient) AllocateHosts(ctx context.Context, input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
+	return record(ctx, c, "AllocateHosts", input, c.client.AllocateHosts)
+}
+
+func (c *recordingClient) ReleaseHosts(ctx context.Context, input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
+	return record(ctx, c, "ReleaseHosts", input, c.client.ReleaseHosts)
+}
+
+func (c *recordingClient) RunInstances(ctx context.Context, input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+	return record(ctx, c, "RunInstances", input, c.client.RunInstances)
+}
+
+func (c *recordingClient) DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return record(ctx, c, "DescribeInstances", input, c.client.DescribeInstances)
+}
+
+func (c *recordingClient) TerminateInstances(ctx context.Context, input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	return record(ctx, c, "TerminateInstances", input, c.client.TerminateInstances)
+}
+
+func (c *recordingClient) DescribeSpotInstanceRequests(ctx context.Context, input *ec2.DescribeSpotInstanceRequestsInput) (*ec2.DescribeSpotInstanceRequestsOutput, error) {
+	return record(ctx, c, "DescribeSpotInstanceRequests", input, c.client.DescribeSpotInstanceRequests)
+}
+
+func (c *recordingClient) CancelSpotInstanceRequests(ctx context.Context, input *ec2.CancelSpotInstanceRequestsInput) (*ec2.CancelSpotInstanceRequestsOutput, error) {
+	return record(ctx, c, "CancelSpotInstanceRequests", input, c.client.CancelSpotInstanceRequests)
+}
+
+func (c *recordingClient) DescribeVolumes(ctx context.Context, input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+	return record(ctx, c, "DescribeVolumes", input, c.client.DescribeVolumes)
+}
+
+func (c *recordingClient) CreateTags(ctx context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	return record(ctx, c, "CreateTags", input, c.client.CreateTags)
+}
+
+func (c *recordingClient) CreatePlacementGroup(ctx context.Context, input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
+	return record(ctx, c, "CreatePlacementGroup", input, c.client.CreatePlacementGroup)
+}
+
+func (c *recordingClient) DeletePlacementGroup(ctx context.Context, input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
+	return record(ctx, c, "DeletePlacementGroup", input, c.client.DeletePlacementGroup)
+}
+
+func (c *recordingClient) RegisterInstancesWithLoadBalancer(ctx context.Context, input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
+	return record(ctx, c, "RegisterInstancesWithLoadBalancer", input, c.client.RegisterInstancesWithLoadBalancer)
+}
+
+func (c *recordingClient) ELBv2DescribeLoadBalancers(ctx context.Context, input *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+	return record(ctx, c, "ELBv2DescribeLoadBalancers", input, c.client.ELBv2DescribeLoadBalancers)
+}
+
+func (c *recordingClient) ELBv2DescribeTargetGroups(ctx context.Context, input *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+	return record(ctx, c, "ELBv2DescribeTargetGroups", input, c.client.ELBv2DescribeTargetGroups)
+}
+
+func (c *recordingClient) ELBv2DescribeTargetHealth(ctx context.Context, input *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	return record(ctx, c, "ELBv2DescribeTargetHealth", input, c.client.ELBv2DescribeTargetHealth)
+}
+
+func (c *recordingClient) ELBv2RegisterTargets(ctx context.Context, input *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+	return record(ctx, c, "ELBv2RegisterTargets", input, c.client.ELBv2RegisterTargets)
+}
+
+func (c *recordingClient) ELBv2DeregisterTargets(ctx context.Context, input *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
+	return record(ctx, c, "ELBv2DeregisterTargets", input, c.client.ELBv2DeregisterTargets)
+}
+
+func (c *recordingClient) GetServiceQuota(ctx context.Context, input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+	return record(ctx, c, "GetServiceQuota", input, c.client.GetServiceQuota)
+}
@@ -0,0 +1,201 @@
+package replay
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	mockaws "github.com/openshift/machine-api-provider-aws/pkg/client/mock"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	describeInput := &ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice([]string{"i-1"})}
+	describeOutput := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{
+			OwnerId: aws.String("123456789012"),
+			Instances: []*ec2.Instance{{
+				InstanceId:         aws.String("i-1"),
+				IamInstanceProfile: &ec2.IamInstanceProfile{Arn: aws.String("arn:aws:iam::123456789012:instance-profile/worker")},
+				State:              &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+			}},
+		}},
+	}
+	runInput := &ec2.RunInstancesInput{
+		ImageId:  aws.String("ami-1"),
+		UserData: aws.String("c2VjcmV0"),
+	}
+	throttled := awserr.NewRequestFailure(awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil), 503, "request-id")
+	terminateInput := &ec2.TerminateInstancesInput{InstanceIds: aws.StringSlice([]string{"i-1"})}
+	unauthorized := awserr.NewRequestFailure(awserr.New("UnauthorizedOperation",
+		"You are not authorized to perform this operation. User: arn:aws:sts::123456789012:assumed-role/worker/i-1 "+
+			"is not authorized to perform: ec2:TerminateInstances. Encoded authorization failure message: c2VjcmV0", nil), 403, "request-id")
+
+	for _, file := range []string{"cassette.yaml", "cassette.json"} {
+		t.Run(file, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockAWSClient := mockaws.NewMockClient(ctrl)
+			mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), describeInput).Return(describeOutput, nil).Times(1)
+			mockAWSClient.EXPECT().RunInstances(gomock.Any(), runInput).Return(nil, throttled).Times(1)
+			mockAWSClient.EXPECT().TerminateInstances(gomock.Any(), terminateInput).Return(nil, unauthorized).Times(1)
+
+			recorder := NewRecorder(DefaultRedactor(), 0)
+			client := recorder.WrapClient(mockAWSClient)
+			if _, err := client.DescribeInstances(context.Background(), describeInput); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := client.RunInstances(context.Background(), runInput); err != throttled {
+				t.Fatalf("Expected the original error to be returned, got %v", err)
+			}
+			if _, err := client.TerminateInstances(context.Background(), terminateInput); err != unauthorized {
+				t.Fatalf("Expected the original error to be returned, got %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), file)
+			if err := recorder.Save(path); err != nil {
+				t.Fatalf("Unable to save cassette: %v", err)
+			}
+
+			cassette, err := LoadCassette(path)
+			if err != nil {
+				t.Fatalf("Unable to load cassette: %v", err)
+			}
+			if len(cassette.Interactions) != 3 {
+				t.Fatalf("Expected 3 interactions, got %d", len(cassette.Interactions))
+			}
+			for _, interaction := range cassette.Interactions {
+				recorded := string(interaction.Request) + string(interaction.Response)
+				if interaction.Error != nil {
+					recorded += interaction.Error.Message
+				}
+				for _, secret := range []string{"123456789012", "c2VjcmV0"} {
+					if strings.Contains(recorded, secret) {
+						t.Errorf("Expected %q to be redacted from the %s interaction", secret, interaction.Operation)
+					}
+				}
+			}
+
+			replayer := NewReplayer(cassette, DefaultRedactor())
+
+			output, err := replayer.DescribeInstances(context.Background(), describeInput)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			instance := output.Reservations[0].Instances[0]
+			if aws.StringValue(instance.InstanceId) != "i-1" || aws.StringValue(instance.State.Name) != ec2.InstanceStateNameRunning {
+				t.Errorf("Unexpected replayed instance: %v", instance)
+			}
+			if arn := aws.StringValue(instance.IamInstanceProfile.Arn); arn != "arn:aws:iam::000000000000:instance-profile/worker" {
+				t.Errorf("Unexpected replayed ARN: %s", arn)
+			}
+
+			_, err = replayer.RunInstances(context.Background(), runInput)
+			requestFailure, ok := err.(awserr.RequestFailure)
+			if !ok || requestFailure.Code() != "RequestLimitExceeded" || requestFailure.StatusCode() != 503 {
+				t.Errorf("Expected a replayed RequestLimitExceeded failure, got %v", err)
+			}
+
+			_, err = replayer.TerminateInstances(context.Background(), terminateInput)
+			requestFailure, ok = err.(awserr.RequestFailure)
+			if !ok || requestFailure.Code() != "UnauthorizedOperation" || !strings.Contains(requestFailure.Message(), "arn:aws:sts::000000000000:assumed-role/worker") {
+				t.Errorf("Expected a replayed UnauthorizedOperation failure with a redacted ARN, got %v", err)
+			}
+
+			if _, err := replayer.DescribeInstances(context.Background(), describeInput); err == nil {
+				t.Error("Expected an error once the recorded interaction has been replayed")
+			}
+			if unused := replayer.Unused(); len(unused) != 0 {
+				t.Errorf("Expected all interactions to be replayed, got %v", unused)
+			}
+		})
+	}
+}
+
+func TestReplayRequestMismatch(t *testing.T) {
+	replayer := NewReplayer(&Cassette{
+		Interactions: []Interaction{{
+			Operation: "DescribeInstances",
+			Request:   []byte(`{"InstanceIds":["i-1"]}`),
+			Response:  []byte(`{}`),
+		}},
+	}, DefaultRedactor())
+
+	if _, err := replayer.DescribeInstances(context.Background(), &ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice([]string{"i-2"})}); err == nil {
+		t.Error("Expected an error for a request without a recorded interaction")
+	}
+	if _, err := replayer.TerminateInstances(context.Background(), &ec2.TerminateInstancesInput{InstanceIds: aws.StringSlice([]string{"i-1"})}); err == nil {
+		t.Error("Expected an error for an operation without a recorded interaction")
+	}
+	if unused := replayer.Unused(); len(unused) != 1 {
+		t.Errorf("Expected the interaction to remain unused, got %v", unused)
+	}
+}
+
+func TestRecorderKeepsLatestInteractions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(ctrl)
+	mockAWSClient.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).Return(&ec2.DescribeInstancesOutput{}, nil).Times(3)
+
+	recorder := NewRecorder(DefaultRedactor(), 2)
+	client := recorder.WrapClient(mockAWSClient)
+	for _, id := range []string{"i-1", "i-2", "i-3"} {
+		if _, err := client.DescribeInstances(context.Background(), &ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice([]string{id})}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	interactions := recorder.Cassette().Interactions
+	if len(interactions) != 2 {
+		t.Fatalf("Expected 2 interactions, got %d", len(interactions))
+	}
+	if !strings.Contains(string(interactions[0].Request), "i-2") || !strings.Contains(string(interactions[1].Request), "i-3") {
+		t.Errorf("Expected the latest interactions to be kept, got %s and %s", interactions[0].Request, interactions[1].Request)
+	}
+}
+
+func TestCassetteSaverSavesOnStop(t *testing.T) {
+	recorder := NewRecorder(DefaultRedactor(), 0)
+	recorder.add(Interaction{Operation: "DescribeInstances", Request: []byte(`{}`)})
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- (&CassetteSaver{Recorder: recorder, Path: path, Interval: time.Hour}).Start(ctx)
+	}()
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("Unable to load cassette: %v", err)
+	}
+	if len(cassette.Interactions) != 1 {
+		t.Errorf("Expected 1 interaction, got %d", len(cassette.Interactions))
+	}
+}
+
+func TestDefaultRedactorRedactsCredentials(t *testing.T) {
+	redacted, err := DefaultRedactor().Redact(map[string]string{
+		"authorization":        "AWS4-HMAC-SHA256 Credential=secret",
+		"X-Amz-Security-Token": "token",
+		"SessionToken":         "token",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, secret := range []string{"secret", "token"} {
+		if strings.Contains(string(redacted), secret) {
+			t.Errorf("Expected %q to be redacted, got %s", secret, redacted)
+		}
+	}
+}
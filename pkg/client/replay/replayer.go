@@ -0,0 +1,234 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	awsclient "github.com/openshift/machine-api-provider-aws/pkg/client"
+)
+
+var _ awsclient.Client = &Replayer{}
+
+// Replayer is a Client which serves the responses recorded in a cassette.
+// A request is answered by the first unused interaction with the same operation and the same redacted request,
+// so repeated identical requests are answered in the order they were recorded.
+// Requests without a matching interaction fail.
+type Replayer struct {
+	redactor     Redactor
+	interactions []Interaction
+	used         []bool
+	mutex        sync.Mutex
+}
+
+// NewReplayer creates a Replayer for the cassette. The redactor must match the one used to record the cassette,
+// so that requests containing redacted fields still match.
+func NewReplayer(cassette *Cassette, redactor Redactor) *Replayer {
+	return &Replayer{
+		redactor:     redactor,
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// Unused returns the interactions which have not been replayed yet.
+func (r *Replayer) Unused() []Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	unused := []Interaction{}
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// next finds and consumes the first unused interaction matching the operation and request.
+func (r *Replayer) next(operation string, request json.RawMessage) (*Interaction, error) {
+	var expected interface{}
+	if err := json.Unmarshal(request, &expected); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.interactions {
+		interaction := &r.interactions[i]
+		if r.used[i] || interaction.Operation != operation {
+			continue
+		}
+
+		var recorded interface{}
+		if err := json.Unmarshal(interaction.Request, &recorded); err != nil {
+			return nil, fmt.Errorf("invalid recorded %s request: %w", operation, err)
+		}
+		// Hand-written cassettes may list unset fields, which recorded requests omit.
+		if reflect.DeepEqual(expected, redactValue(recorded, nil)) {
+			r.used[i] = true
+			return interaction, nil
+		}
+	}
+	return nil, fmt.Errorf("no recorded %s interaction matches request %s", operation, request)
+}
+
+// replayError converts a recorded error back into an AWS SDK error.
+func replayError(recorded *Error) error {
+	err := awserr.New(recorded.Code, recorded.Message, nil)
+	if recorded.StatusCode != 0 {
+		return awserr.NewRequestFailure(err, recorded.StatusCode, "")
+	}
+	return err
+}
+
+// replay serves a request from the cassette.
+func replay[I any, O any](r *Replayer, operation string, input I) (O, error) {
+	var output O
+
+	request, err := r.redactor.Redact(input)
+	if err != nil {
+		return output, fmt.Errorf("unable to encode %s request: %w", operation, err)
+	}
+
+	interaction, err := r.next(operation, request)
+	if err != nil {
+		return output, err
+	}
+
+	if interaction.Error != nil {
+		return output, replayError(interaction.Error)
+	}
+
+	if err := json.Unmarshal(interaction.Response, &output); err != nil {
+		return output, fmt.Errorf("invalid recorded %s response: %w", operation, err)
+	}
+	return output, nil
+}
+
+func (r *Replayer) DescribeImages(_ context.Context, input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	return replay[*ec2.DescribeImagesInput, *ec2.DescribeImagesOutput](r, "DescribeImages", input)
+}
+
+func (r *Replayer) DescribeDHCPOptions(_ context.Context, input *ec2.DescribeDhcpOptionsInput) (*ec2.DescribeDhcpOptionsOutput, error) {
+	return replay[*ec2.DescribeDhcpOptionsInput, *ec2.DescribeDhcpOptionsOutput](r, "DescribeDHCPOptions", input)
+}
+
+func (r *Replayer) DescribeVpcs(_ context.Context, input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return replay[*ec2.DescribeVpcsInput, *ec2.DescribeVpcsOutput](r, "DescribeVpcs", input)
+}
+
+func (r *Replayer) DescribeSubnets(_ context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return replay[*ec2.DescribeSubnetsInput, *ec2.DescribeSubnetsOutput](r, "DescribeSubnets", input)
+}
+
+func (r *Replayer) DescribeAvailabilityZones(_ context.Context, input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return replay[*ec2.DescribeAvailabilityZonesInput, *ec2.DescribeAvailabilityZonesOutput](r, "DescribeAvailabilityZones", input)
+}
+
+func (r *Replayer) DescribeSecurityGroups(_ context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return replay[*ec2.DescribeSecurityGroupsInput, *ec2.DescribeSecurityGroupsOutput](r, "DescribeSecurityGroups", input)
+}
+
+func (r *Replayer) DescribePlacementGroups(_ context.Context, input *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
+	return replay[*ec2.DescribePlacementGroupsInput, *ec2.DescribePlacementGroupsOutput](r, "DescribePlacementGroups", input)
+}
+
+func (r *Replayer) DescribeInstanceTypes(_ context.Context, input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	return replay[*ec2.DescribeInstanceTypesInput, *ec2.DescribeInstanceTypesOutput](r, "DescribeInstanceTypes", input)
+}
+
+func (r *Replayer) DescribeInstanceTypeOfferings(_ context.Context, input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	return replay[*ec2.DescribeInstanceTypeOfferingsInput, *ec2.DescribeInstanceTypeOfferingsOutput](r, "DescribeInstanceTypeOfferings", input)
+}
+
+func (r *Replayer) DescribeSpotPriceHistory(_ context.Context, input *ec2.DescribeSpotPriceHistoryInput) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	return replay[*ec2.DescribeSpotPriceHistoryInput, *ec2.DescribeSpotPriceHistoryOutput](r, "DescribeSpotPriceHistory", input)
+}
+
+func (r *Replayer) DescribeCapacityReservations(_ context.Context, input *ec2.DescribeCapacityReservationsInput) (*ec2.DescribeCapacityReservationsOutput, error) {
+	return replay[*ec2.DescribeCapacityReservationsInput, *ec2.DescribeCapacityReservationsOutput](r, "DescribeCapacityReservations", input)
+}
+
+func (r *Replayer) DescribeHosts(_ context.Context, input *ec2.DescribeHostsInput) (*ec2.DescribeHostsOutput, error) {
+	return replay[*ec2.DescribeHostsInput, *ec2.DescribeHostsOutput](r, "DescribeHosts", input)
+}
+
+func (r *Replayer) AllocateHosts(_ context.Context, input *ec2.AllocateHostsInput) (*ec2.AllocateHostsOutput, error) {
+	return replay[*ec2.AllocateHostsInput, *ec2.AllocateHostsOutput](r, "AllocateHosts", input)
+}
+
+func (r *Replayer) ReleaseHosts(_ context.Context, input *ec2.ReleaseHostsInput) (*ec2.ReleaseHostsOutput, error) {
+	return replay[*ec2.ReleaseHostsInput, *ec2.ReleaseHostsOutput](r, "ReleaseHosts", input)
+}
+
+func (r *Replayer) RunInstances(_ context.Context, input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+	return replay[*ec2.RunInstancesInput, *ec2.Reservation](r, "RunInstances", input)
+}
+
+func (r *Replayer) DescribeInstances(_ context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return replay[*ec2.DescribeInstancesInput, *ec2.DescribeInstancesOutput](r, "DescribeInstances", input)
+}
+
+func (r *Replayer) TerminateInstances(_ context.Context, input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	return replay[*ec2.TerminateInstancesInput, *ec2.TerminateInstancesOutput](r, "TerminateInstances", input)
+}
+
+func (r *Replayer) DescribeSpotInstanceRequests(_ context.Context, input *ec2.DescribeSpotInstanceRequestsInput) (*ec2.DescribeSpotInstanceRequestsOutput, error) {
+	return replay[*ec2.DescribeSpotInstanceRequestsInput, *ec2.DescribeSpotInstanceRequestsOutput](r, "DescribeSpotInstanceRequests", input)
+}
+
+func (r *Replayer) CancelSpotInstanceRequests(_ context.Context, input *ec2.CancelSpotInstanceRequestsInput) (*ec2.CancelSpotInstanceRequestsOutput, error) {
+	return replay[*ec2.CancelSpotInstanceRequestsInput, *ec2.CancelSpotInstanceRequestsOutput](r, "CancelSpotInstanceRequests", input)
+}
+
+func (r *Replayer) DescribeVolumes(_ context.Context, input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+	return replay[*ec2.DescribeVolumesInput, *ec2.DescribeVolumesOutput](r, "DescribeVolumes", input)
+}
+
+func (r *Replayer) CreateTags(_ context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	return replay[*ec2.CreateTagsInput, *ec2.CreateTagsOutput](r, "CreateTags", input)
+}
+
+func (r *Replayer) CreatePlacementGroup(_ context.Context, input *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
+	return replay[*ec2.CreatePlacementGroupInput, *ec2.CreatePlacementGroupOutput](r, "CreatePlacementGroup", input)
+}
+
+func (r *Replayer) DeletePlacementGroup(_ context.Context, input *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
+	return replay[*ec2.DeletePlacementGroupInput, *ec2.DeletePlacementGroupOutput](r, "DeletePlacementGroup", input)
+}
+
+func (r *Replayer) RegisterInstancesWithLoadBalancer(_ context.Context, input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
+	return replay[*elb.RegisterInstancesWithLoadBalancerInput, *elb.RegisterInstancesWithLoadBalancerOutput](r, "RegisterInstancesWithLoadBalancer", input)
+}
+
+func (r *Replayer) ELBv2DescribeLoadBalancers(_ context.Context, input *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+	return replay[*elbv2.DescribeLoadBalancersInput, *elbv2.DescribeLoadBalancersOutput](r, "ELBv2DescribeLoadBalancers", input)
+}
+
+func (r *Replayer) ELBv2DescribeTargetGroups(_ context.Context, input *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+	return replay[*elbv2.DescribeTargetGroupsInput, *elbv2.DescribeTargetGroupsOutput](r, "ELBv2DescribeTargetGroups", input)
+}
+
+func (r *Replayer) ELBv2DescribeTargetHealth(_ context.Context, input *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	return replay[*elbv2.DescribeTargetHealthInput, *elbv2.DescribeTargetHealthOutput](r, "ELBv2DescribeTargetHealth", input)
+}
+
+func (r *Replayer) ELBv2RegisterTargets(_ context.Context, input *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+	return replay[*elbv2.RegisterTargetsInput, *elbv2.RegisterTargetsOutput](r, "ELBv2RegisterTargets", input)
+}
+
+func (r *Replayer) ELBv2DeregisterTargets(_ context.Context, input *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
+	return replay[*elbv2.DeregisterTargetsInput, *elbv2.DeregisterTargetsOutput](r, "ELBv2DeregisterTargets", input)
+}
+
+func (r *Replayer) GetServiceQuota(_ context.Context, input *awsclient.GetServiceQuotaInput) (*awsclient.GetServiceQuotaOutput, error) {
+	return replay[*awsclient.GetServiceQuotaInput, *awsclient.GetServiceQuotaOutput](r, "GetServiceQuota", input)
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsclient "github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol"
+	"github.com/aws/aws-sdk-go/private/protocol/jsonrpc"
+)
+
+// The Service Quotas package of the SDK is not vendored, as the only operation needed is GetServiceQuota.
+// It is implemented here on top of the JSON RPC protocol of the SDK, as the generated service clients do.
+
+const (
+	// ServiceQuotasEndpointsID is the ID of the Service Quotas endpoints, which custom endpoints can be configured for.
+	ServiceQuotasEndpointsID = "servicequotas"
+
+	// ErrCodeNoSuchResourceException is returned by GetServiceQuota for unknown quotas.
+	ErrCodeNoSuchResourceException = "NoSuchResourceException"
+
+	serviceQuotasServiceID  = "Service Quotas"
+	serviceQuotasAPIVersion = "2019-06-24"
+	serviceQuotasTarget     = "ServiceQuotasV20190624"
+
+	getServiceQuotaOperation = "GetServiceQuota"
+)
+
+// GetServiceQuotaInput is the input of GetServiceQuota.
+type GetServiceQuotaInput struct {
+	_ struct{} `type:"structure"`
+
+	// QuotaCode is the code of the quota, e.g. L-1216C47A.
+	QuotaCode *string `min:"1" type:"string" required:"true"`
+
+	// ServiceCode is the code of the service of the quota, e.g. ec2.
+	ServiceCode *string `min:"1" type:"string" required:"true"`
+}
+
+// GetServiceQuotaOutput is the output of GetServiceQuota.
+type GetServiceQuotaOutput struct {
+	_ struct{} `type:"structure"`
+
+	Quota *ServiceQuota `type:"structure"`
+}
+
+// ServiceQuota is the value of a quota applied to the account.
+type ServiceQuota struct {
+	_ struct{} `type:"structure"`
+
+	QuotaCode   *string  `min:"1" type:"string"`
+	QuotaName   *string  `type:"string"`
+	ServiceCode *string  `min:"1" type:"string"`
+	Unit        *string  `type:"string"`
+	Value       *float64 `type:"double"`
+}
+
+// serviceQuotas is a client of the Service Quotas API.
+type serviceQuotas struct {
+	*awsclient.Client
+}
+
+// newServiceQuotas creates a Service Quotas client from the session.
+func newServiceQuotas(p awsclient.ConfigProvider) *serviceQuotas {
+	c := p.ClientConfig(ServiceQuotasEndpointsID)
+	if c.SigningNameDerived || len(c.SigningName) == 0 {
+		c.SigningName = ServiceQuotasEndpointsID
+	}
+
+	svc := &serviceQuotas{
+		Client: awsclient.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:    ServiceQuotasEndpointsID,
+				ServiceID:      serviceQuotasServiceID,
+				SigningName:    c.SigningName,
+				SigningRegion:  c.SigningRegion,
+				PartitionID:    c.PartitionID,
+				Endpoint:       c.Endpoint,
+				APIVersion:     serviceQuotasAPIVersion,
+				ResolvedRegion: c.ResolvedRegion,
+				JSONVersion:    "1.1",
+				TargetPrefix:   serviceQuotasTarget,
+			},
+			c.Handlers,
+		),
+	}
+
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Build.PushBackNamed(jsonrpc.BuildHandler)
+	svc.Handlers.Unmarshal.PushBackNamed(jsonrpc.UnmarshalHandler)
+	svc.Handlers.UnmarshalMeta.PushBackNamed(jsonrpc.UnmarshalMetaHandler)
+	svc.Handlers.UnmarshalError.PushBackNamed(
+		protocol.NewUnmarshalErrorHandler(jsonrpc.NewUnmarshalTypedError(nil)).NamedHandler(),
+	)
+	return svc
+}
+
+// GetServiceQuotaWithContext returns the value of the quota applied to the account.
+func (s *serviceQuotas) GetServiceQuotaWithContext(ctx aws.Context, input *GetServiceQuotaInput, opts ...request.Option) (*GetServiceQuotaOutput, error) {
+	if input == nil {
+		input = &GetServiceQuotaInput{}
+	}
+	output := &GetServiceQuotaOutput{}
+
+	req := s.NewRequest(&request.Operation{
+		Name:       getServiceQuotaOperation,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}, input, output)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (c *awsClient) GetServiceQuota(ctx context.Context, input *GetServiceQuotaInput) (*GetServiceQuotaOutput, error) {
+	return c.serviceQuotasClient.GetServiceQuotaWithContext(ctx, input, withCallTimeout(c.callTimeout))
+}
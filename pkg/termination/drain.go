@@ -0,0 +1,167 @@
+package termination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubectl/pkg/drain"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InterruptionTaintKey is the key of the taint added to nodes drained ahead of an interruption.
+	// Its value is the interruption action, e.g. terminate.
+	InterruptionTaintKey = "machine.openshift.io/interruption"
+
+	// defaultDrainTimeout is used when the notice time cannot be parsed. It matches the two minutes
+	// spot interruption notices are issued ahead of time.
+	defaultDrainTimeout = 2 * time.Minute
+	// minimumDrainTimeout leaves time to evict pods when the notice time has already passed.
+	minimumDrainTimeout = 10 * time.Second
+	// evictionRetryDelay is the delay between evictions refused by a PodDisruptionBudget.
+	evictionRetryDelay = time.Second
+
+	podEvictedReason        = "Evicted"
+	podEvictionFailedReason = "EvictionFailed"
+)
+
+// drainDeadline returns the time by which the node must be drained, derived from the notice time.
+func drainDeadline(action *Notice, now time.Time) time.Time {
+	deadline, err := time.Parse(time.RFC3339, action.Time)
+	if err != nil {
+		return now.Add(defaultDrainTimeout)
+	}
+	if deadline.Before(now.Add(minimumDrainTimeout)) {
+		return now.Add(minimumDrainTimeout)
+	}
+	return deadline
+}
+
+// drainNode cordons and taints the node, then evicts its pods before the deadline.
+// Evictions respect PodDisruptionBudgets and are retried until the deadline.
+// The result of each eviction is reported as an event on the pod.
+func (h *handler) drainNode(ctx context.Context, action *Notice, deadline time.Time) error {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	node := &corev1.Node{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: h.nodeName}, node); err != nil {
+		return fmt.Errorf("error fetching node: %v", err)
+	}
+
+	drainer := &drain.Helper{
+		Ctx:                 ctx,
+		Client:              h.clientset,
+		Force:               true,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  true,
+		Out:                 logWriter{log: h.log, v: 1},
+		ErrOut:              logWriter{log: h.log},
+	}
+
+	if err := drain.RunCordonOrUncordon(drainer, node, true); err != nil {
+		return fmt.Errorf("error cordoning node: %v", err)
+	}
+
+	if err := h.taintNode(ctx, action); err != nil {
+		return fmt.Errorf("error tainting node: %v", err)
+	}
+
+	pods, errs := drainer.GetPodsForDeletion(h.nodeName)
+	if errs != nil {
+		return fmt.Errorf("error listing pods: %v", utilerrors.NewAggregate(errs))
+	}
+	if warnings := pods.Warnings(); warnings != "" {
+		h.log.Info("Draining node with warnings", "warnings", warnings)
+	}
+
+	errCh := make(chan error, len(pods.Pods()))
+	wg := &sync.WaitGroup{}
+	for _, pod := range pods.Pods() {
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			errCh <- h.evictPod(ctx, drainer, pod, action)
+		}(pod)
+	}
+	wg.Wait()
+	close(errCh)
+
+	evictionErrs := []error{}
+	for err := range errCh {
+		if err != nil {
+			evictionErrs = append(evictionErrs, err)
+		}
+	}
+	return utilerrors.NewAggregate(evictionErrs)
+}
+
+// evictPod evicts the pod, retrying while its PodDisruptionBudget does not allow the disruption.
+func (h *handler) evictPod(ctx context.Context, drainer *drain.Helper, pod corev1.Pod, action *Notice) error {
+	for {
+		err := drainer.EvictPod(pod, policyv1.SchemeGroupVersion)
+		switch {
+		case err == nil:
+			h.eventRecorder.Eventf(&pod, corev1.EventTypeNormal, podEvictedReason, "Evicted from node %s ahead of instance %s at %s", h.nodeName, action.Action, action.Time)
+			return nil
+		case apierrors.IsNotFound(err):
+			return nil
+		case ctx.Err() == nil && !apierrors.IsTooManyRequests(err):
+			h.eventRecorder.Eventf(&pod, corev1.EventTypeWarning, podEvictionFailedReason, "Failed to evict from node %s: %v", h.nodeName, err)
+			return fmt.Errorf("error evicting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			h.eventRecorder.Eventf(&pod, corev1.EventTypeWarning, podEvictionFailedReason, "Failed to evict from node %s before instance %s at %s: %v", h.nodeName, action.Action, action.Time, err)
+			return fmt.Errorf("error evicting pod %s/%s before the deadline: %v", pod.Namespace, pod.Name, err)
+		case <-time.After(evictionRetryDelay):
+		}
+	}
+}
+
+// taintNode adds the InterruptionTaintKey taint to the node so that no new pods are scheduled onto it.
+func (h *handler) taintNode(ctx context.Context, action *Notice) error {
+	node := &corev1.Node{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: h.nodeName}, node); err != nil {
+		return fmt.Errorf("error fetching node: %v", err)
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == InterruptionTaintKey {
+			return nil
+		}
+	}
+
+	patchBase := client.MergeFrom(node.DeepCopy())
+	node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+		Key:    InterruptionTaintKey,
+		Value:  action.Action,
+		Effect: corev1.TaintEffectNoSchedule,
+	})
+	if err := h.client.Patch(ctx, node, patchBase); err != nil {
+		return fmt.Errorf("error patching node: %v", err)
+	}
+	return nil
+}
+
+// logWriter adapts the output of the drain helper to the handler logger.
+type logWriter struct {
+	log logr.Logger
+	v   int
+}
+
+// Write implements io.Writer
+func (w logWriter) Write(p []byte) (int, error) {
+	w.log.V(w.v).Info(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
@@ -2,52 +2,136 @@ package termination
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/ec2metadata"
-	awsrequest "github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/aws/session"
-
 	"github.com/go-logr/logr"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/kubernetes"
+	kubernetesscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	awsTerminationEndpointURL                           = "/latest/meta-data/spot/termination-time"
+	// awsInstanceActionPath is the metadata path of the spot interruption notice, relative to /latest/meta-data.
+	awsInstanceActionPath                               = "spot/instance-action"
 	terminatingConditionType   corev1.NodeConditionType = "Terminating"
 	terminationRequestedReason                          = "TerminationRequested"
+
+	// awsRebalanceRecommendationPath is the metadata path of EC2 instance rebalance recommendations,
+	// relative to /latest/meta-data.
+	awsRebalanceRecommendationPath                                 = "events/recommendations/rebalance"
+	rebalanceRecommendedConditionType     corev1.NodeConditionType = "RebalanceRecommended"
+	rebalanceRecommendationReceivedReason                          = "RebalanceRecommendationReceived"
+
+	// RebalanceRecommendedAnnotation is set on the Machine of a node that received a rebalance recommendation.
+	// Its value is the notice time reported by EC2.
+	RebalanceRecommendedAnnotation = "machine.openshift.io/rebalance-recommended"
+
+	interruptionNoticeReceivedReason = "InterruptionNoticeReceived"
+
+	// conditionHeartbeatInterval is how often the heartbeat of the terminating condition is refreshed
+	// while the interruption notice is in effect.
+	conditionHeartbeatInterval = 30 * time.Second
+
+	// readinessStalenessFactor is the number of poll intervals after which the handler
+	// stops reporting ready if the metadata service could not be polled.
+	readinessStalenessFactor = 3
+
+	// eventSourceComponent is the component reported in the events emitted by the handler.
+	eventSourceComponent = "aws-termination-handler"
 )
 
+// apiRetryBackoff is used to retry requests to the apiserver, for about 30 seconds in total.
+var apiRetryBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    6,
+}
+
+// HandlerOptions configures the optional behaviour of the Handler.
+type HandlerOptions struct {
+	// WatchRebalanceRecommendations enables polling the rebalance recommendation endpoint,
+	// marking the node with the RebalanceRecommended condition when a recommendation is received.
+	WatchRebalanceRecommendations bool
+	// AnnotateMachineOnRebalanceRecommendation additionally annotates the Machine of the node
+	// with RebalanceRecommendedAnnotation when a recommendation is received.
+	AnnotateMachineOnRebalanceRecommendation bool
+	// WatchScheduledMaintenance enables polling the scheduled maintenance events endpoint,
+	// reflecting upcoming events in the MaintenanceScheduled condition of the node.
+	WatchScheduledMaintenance bool
+	// MarkMachineForDeletionBeforeMaintenance, when non-zero, annotates the Machine of the node with
+	// DeleteMachineAnnotation once a scheduled event is due to start within this duration.
+	MarkMachineForDeletionBeforeMaintenance time.Duration
+	// DrainOnTermination makes the handler cordon, taint and drain the node itself when the instance
+	// is marked for interruption, instead of leaving it to the machine controller.
+	DrainOnTermination bool
+	// Sources are polled for notices in addition to the instance metadata service,
+	// e.g. a simulator created with NewFileNoticeSource.
+	Sources []NoticeSource
+}
+
 // Handler represents a handler that will run to check the termination notice
 // endpoint and mark node for deletion if the instance termination notice is fulfilled.
 type Handler interface {
 	Run(stop <-chan struct{}) error
+	// Readyz reports whether the notice sources were successfully polled recently.
+	// It implements healthz.Checker.
+	Readyz(req *http.Request) error
 }
 
 // NewHandler constructs a new Handler
-func NewHandler(logger logr.Logger, cfg *rest.Config, pollInterval time.Duration, namespace, nodeName string) (Handler, error) {
-	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+func NewHandler(logger logr.Logger, cfg *rest.Config, pollInterval time.Duration, namespace, nodeName string, options HandlerOptions) (Handler, error) {
+	scheme := runtime.NewScheme()
+	if err := kubernetesscheme.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("error setting up scheme: %v", err)
+	}
+	if err := machinev1beta1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("error setting up scheme: %v", err)
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, fmt.Errorf("error creating client: %v", err)
 	}
 
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating clientset: %v", err)
+	}
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	eventRecorder := eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: eventSourceComponent, Host: nodeName})
+
 	logger = logger.WithValues("node", nodeName, "namespace", namespace)
 
 	return &handler{
-		client:       c,
-		pollInterval: pollInterval,
-		nodeName:     nodeName,
-		namespace:    namespace,
-		log:          logger,
+		client:        c,
+		clientset:     clientset,
+		eventRecorder: eventRecorder,
+		pollInterval:  pollInterval,
+		nodeName:      nodeName,
+		namespace:     namespace,
+		sources:       append(newIMDSNoticeSources(newIMDSClient(""), options), options.Sources...),
+		options:       options,
+		log:           logger,
 	}, nil
 }
 
@@ -55,29 +139,30 @@ func NewHandler(logger logr.Logger, cfg *rest.Config, pollInterval time.Duration
 // marks the node for termination
 type handler struct {
 	client client.Client
-	// endpoint - custom imds service url. For testing purposes.
-	endpoint     *string
+	// clientset is used for draining, which is implemented on top of client-go.
+	clientset     kubernetes.Interface
+	eventRecorder record.EventRecorder
+	// sources provide the notices issued for the instance.
+	sources      []NoticeSource
 	pollInterval time.Duration
 	nodeName     string
 	namespace    string
+	options      HandlerOptions
 	log          logr.Logger
+
+	// lastSuccessfulPoll is the time, in Unix nanoseconds, all the notice sources were last polled successfully.
+	lastSuccessfulPoll atomic.Int64
 }
 
 // Run starts the handler and runs the termination logic
 func (h *handler) Run(stop <-chan struct{}) error {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	imdsSession := session.Must(session.NewSession(&aws.Config{
-		MaxRetries: aws.Int(3),
-		Endpoint:   h.endpoint,
-	}))
-	imdsClient := ec2metadata.New(imdsSession)
-
 	errs := make(chan error, 1)
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
-		errs <- h.run(ctx, imdsClient, wg)
+		errs <- h.run(ctx, wg)
 	}()
 
 	select {
@@ -92,68 +177,286 @@ func (h *handler) Run(stop <-chan struct{}) error {
 	}
 }
 
-func (h *handler) run(ctx context.Context, imdsClient *ec2metadata.EC2Metadata, wg *sync.WaitGroup) error {
+func (h *handler) run(ctx context.Context, wg *sync.WaitGroup) error {
 	defer wg.Done()
 
 	logger := h.log.WithValues("node", h.nodeName)
 	logger.V(1).Info("Monitoring node termination")
 
-	if err := wait.PollImmediateUntil(h.pollInterval, func() (bool, error) {
-		// code below mostly replicates GetMetadataWithContext method of the imdsClient.
-		// https://github.com/aws/aws-sdk-go/blob/v1.43.20/aws/ec2metadata/api.go#L61
-		// Since it's not possible to reliably extract information from result of such function, manual request prep
-		// and handling happens here.
-		op := &awsrequest.Operation{
-			Name:       "GetMetadata",
-			HTTPMethod: "GET",
-			HTTPPath:   awsTerminationEndpointURL,
-		}
-		req := imdsClient.NewRequest(op, nil, nil)
-		req.SetContext(ctx)
-		// we do not care about response data, all what we are interesting about is the status code.
-		// successful request means that instance was marked for termination.
-		// If instance not yet marked, response with 404 code will be returned from imds
-		err := req.Send()
+	rebalanceRecommendationHandled := false
+	machineMarkedForMaintenance := false
+	var interruption *Notice
+	interruptionEventsRecorded := false
+	cancelMachineMark := context.CancelFunc(func() {})
+	defer func() { cancelMachineMark() }()
+	drained := false
+	// drainResult receives the result of the drain in progress, it is nil while no drain is running.
+	var drainResult chan error
+	cancelDrain := context.CancelFunc(func() {})
+	defer func() { cancelDrain() }()
+	var drainDeadlineTime time.Time
+	// The handler keeps polling after an interruption notice, so that the node condition is kept up to date
+	// and any failure to handle the notice, e.g. during an apiserver outage, is retried on the next poll.
+	_ = wait.PollImmediateUntil(h.pollInterval, func() (bool, error) {
+		notices, err := h.pollNotices(ctx)
 		if err != nil {
-			if req.HTTPResponse.StatusCode == http.StatusNotFound {
-				logger.V(2).Info("Instance not marked for termination")
-				return false, nil
+			// The sources may be temporarily unavailable, this is reported through the readiness probe.
+			logger.Error(err, "Failed to poll notice sources")
+		} else {
+			h.lastSuccessfulPoll.Store(time.Now().UnixNano())
+		}
+
+		if h.options.WatchRebalanceRecommendations && !rebalanceRecommendationHandled {
+			if recommendation := findNotice(notices, RebalanceRecommendation); recommendation != nil {
+				// Rebalance recommendations are advisory, failing to handle one must not stop termination monitoring.
+				if err := h.handleRebalanceRecommendation(ctx, recommendation); err != nil {
+					logger.Error(err, "Failed to handle rebalance recommendation")
+				} else {
+					rebalanceRecommendationHandled = true
+				}
 			}
-			return false, fmt.Errorf("%w", err)
 		}
-		// successful request, instance marked for termination. Done here.
-		return true, nil
-	}, ctx.Done()); err != nil {
-		return fmt.Errorf("error polling termination endpoint: %v", err)
-	}
 
-	// Will only get here if the termination endpoint returned 200
-	logger.V(1).Info("Instance marked for termination, marking Node for deletion")
-	if err := h.markNodeForDeletion(ctx); err != nil {
-		return fmt.Errorf("error marking node: %v", err)
+		events := filterNotices(notices, ScheduledMaintenance)
+		// A failed poll may hide scheduled events, so the node is only cleared after a successful poll.
+		if h.options.WatchScheduledMaintenance && (err == nil || len(events) > 0) {
+			// Scheduled events can be added, rescheduled or completed at any time, so keep checking them.
+			marked, err := h.handleScheduledMaintenance(ctx, events, !machineMarkedForMaintenance)
+			if err != nil {
+				logger.Error(err, "Failed to handle scheduled maintenance events")
+			}
+			machineMarkedForMaintenance = machineMarkedForMaintenance || marked
+		}
+
+		action := findNotice(notices, SpotInterruption)
+		if action == nil {
+			logger.V(2).Info("Instance not marked for termination")
+			return false, nil
+		}
+
+		if interruption == nil || *interruption != *action {
+			logger.V(1).Info("Instance marked for interruption", "action", action.Action, "time", action.Time)
+			noticesReceived.WithLabelValues(spotInterruptionNotice).Inc()
+			interruption = action
+			interruptionEventsRecorded = false
+			drained = false
+			// A drain in progress for the previous notice is stopped, its result is ignored.
+			cancelDrain()
+			drainResult = nil
+			drainDeadlineTime = drainDeadline(action, time.Now())
+
+			// Recording the interruption on the Machine is best-effort: the Machine may be outside the namespace of the
+			// handler, missing, or not patchable by it. It is retried in the background so that it holds back neither
+			// the events nor the drain.
+			cancelMachineMark()
+			var markCtx context.Context
+			markCtx, cancelMachineMark = context.WithCancel(ctx)
+			go h.markMachineInterruptedWithRetries(markCtx, interruption)
+		}
+
+		if !interruptionEventsRecorded {
+			h.recordInterruptionEvents(ctx, interruption)
+			interruptionEventsRecorded = true
+		}
+
+		// The Terminating condition is matched by the machine-api-termination-handler MachineHealthCheck, which deletes
+		// the Machine. Stopped and hibernated instances are started again, so neither their Machine is replaced nor
+		// their Node drained.
+		if !interruption.isTermination() {
+			return false, nil
+		}
+
+		if err := h.markNodeForDeletion(ctx, interruption); err != nil {
+			logger.Error(err, "Failed to mark node for deletion")
+			return false, nil
+		}
+
+		if drainResult != nil {
+			select {
+			case err := <-drainResult:
+				drainResult = nil
+				if err != nil {
+					logger.Error(err, "Failed to drain node")
+				} else {
+					drained = true
+				}
+			default:
+			}
+		}
+
+		// The drain can take until the notice time, it runs in the background so that notices keep being polled
+		// meanwhile. A failed drain is retried on the next poll until the deadline.
+		if h.options.DrainOnTermination && !drained && drainResult == nil && time.Now().Before(drainDeadlineTime) {
+			logger.V(1).Info("Draining Node ahead of the interruption")
+			drainResult = make(chan error, 1)
+			var drainCtx context.Context
+			drainCtx, cancelDrain = context.WithCancel(ctx)
+			go func(action *Notice, deadline time.Time, result chan<- error) {
+				result <- h.drainNode(drainCtx, action, deadline)
+			}(interruption, drainDeadlineTime, drainResult)
+		}
+		return false, nil
+	}, ctx.Done())
+
+	return nil
+}
+
+// Readyz implements healthz.Checker
+func (h *handler) Readyz(_ *http.Request) error {
+	lastSuccessfulPoll := h.lastSuccessfulPoll.Load()
+	if lastSuccessfulPoll == 0 {
+		return errors.New("notice sources not polled successfully yet")
 	}
 
+	since := time.Since(time.Unix(0, lastSuccessfulPoll))
+	if since > readinessStalenessFactor*h.pollInterval {
+		return fmt.Errorf("notice sources last polled successfully %s ago", since.Round(time.Second))
+	}
 	return nil
 }
 
-func (h *handler) markNodeForDeletion(ctx context.Context) error {
+// recordInterruptionEvents records the interruption notice as events on the node and its Machine.
+// Failures are only logged as the events are informational.
+func (h *handler) recordInterruptionEvents(ctx context.Context, action *Notice) {
 	node := &corev1.Node{}
 	if err := h.client.Get(ctx, client.ObjectKey{Name: h.nodeName}, node); err != nil {
-		return fmt.Errorf("error fetching node: %v", err)
+		h.log.Error(err, "Failed to fetch node to record interruption event")
+		return
+	}
+	h.eventRecorder.Eventf(node, corev1.EventTypeWarning, interruptionNoticeReceivedReason, "The cloud provider will %s the instance at %s", action.Action, action.Time)
+
+	machineKey, err := h.machineKeyForNode(node)
+	if err != nil {
+		h.log.Error(err, "Failed to find machine to record interruption event")
+		return
 	}
+	machine := &machinev1beta1.Machine{}
+	if err := h.client.Get(ctx, machineKey, machine); err != nil {
+		h.log.Error(err, "Failed to fetch machine to record interruption event", "machine", machineKey)
+		return
+	}
+	h.eventRecorder.Eventf(machine, corev1.EventTypeWarning, interruptionNoticeReceivedReason, "The cloud provider will %s the instance of node %s at %s", action.Action, h.nodeName, action.Time)
+}
 
-	addNodeTerminationCondition(node)
-	if err := h.client.Status().Update(ctx, node); err != nil {
-		return fmt.Errorf("error updating node status")
+// pollNotices returns the notices of all the sources. Notices of the sources that could be polled
+// are returned along with the errors of the others.
+func (h *handler) pollNotices(ctx context.Context) ([]Notice, error) {
+	notices := []Notice{}
+	errs := []error{}
+	for _, source := range h.sources {
+		sourceNotices, err := source.Notices(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error polling %s: %w", source.Name(), err))
+			continue
+		}
+		notices = append(notices, sourceNotices...)
 	}
+	return notices, utilerrors.NewAggregate(errs)
+}
+
+// handleRebalanceRecommendation marks the node as rebalance recommended and optionally annotates its Machine.
+func (h *handler) handleRebalanceRecommendation(ctx context.Context, recommendation *Notice) error {
+	h.log.V(1).Info("Instance received a rebalance recommendation", "noticeTime", recommendation.Time)
+	if err := h.markNodeRebalanceRecommended(ctx, recommendation); err != nil {
+		return fmt.Errorf("error marking node: %v", err)
+	}
+
+	if h.options.AnnotateMachineOnRebalanceRecommendation {
+		if err := h.annotateMachine(ctx, RebalanceRecommendedAnnotation, recommendation.Time); err != nil {
+			return fmt.Errorf("error annotating machine: %v", err)
+		}
+	}
+	noticesReceived.WithLabelValues(rebalanceRecommendationNotice).Inc()
 	return nil
 }
 
-// nodeHasTerminationCondition checks whether the node already
-// has a condition with the terminatingConditionType type
-func nodeHasTerminationCondition(node *corev1.Node) bool {
+func (h *handler) markNodeRebalanceRecommended(ctx context.Context, recommendation *Notice) error {
+	return h.updateNodeStatus(ctx, func(node *corev1.Node) bool {
+		if nodeHasCondition(node, rebalanceRecommendedConditionType) {
+			return false
+		}
+
+		now := metav1.Now()
+		setNodeCondition(node, corev1.NodeCondition{
+			Type:               rebalanceRecommendedConditionType,
+			Status:             corev1.ConditionTrue,
+			LastHeartbeatTime:  now,
+			LastTransitionTime: now,
+			Reason:             rebalanceRecommendationReceivedReason,
+			Message:            fmt.Sprintf("The cloud provider recommends rebalancing this instance, notice time %s", recommendation.Time),
+		})
+		return true
+	})
+}
+
+// markNodeForDeletion adds the terminating condition to the node, or refreshes its heartbeat
+// if the node is already marked.
+func (h *handler) markNodeForDeletion(ctx context.Context, action *Notice) error {
+	return h.updateNodeStatus(ctx, func(node *corev1.Node) bool {
+		return refreshNodeTerminationCondition(node, action, time.Now())
+	})
+}
+
+// updateNodeStatus applies mutate to the latest version of the node and patches its status.
+// mutate returns false if the status is already up to date. Conflicts with concurrent updates
+// and transient apiserver errors are retried with a backoff.
+func (h *handler) updateNodeStatus(ctx context.Context, mutate func(node *corev1.Node) bool) error {
+	return retry.OnError(apiRetryBackoff, isRetriable, func() error {
+		node := &corev1.Node{}
+		if err := h.client.Get(ctx, client.ObjectKey{Name: h.nodeName}, node); err != nil {
+			return fmt.Errorf("error fetching node: %w", err)
+		}
+
+		// The optimistic lock prevents conditions updated concurrently, e.g. by the kubelet, from being overwritten
+		patchBase := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if !mutate(node) {
+			return nil
+		}
+		if err := h.client.Status().Patch(ctx, node, patchBase); err != nil {
+			return fmt.Errorf("error patching node status: %w", err)
+		}
+		return nil
+	})
+}
+
+// isRetriable returns true if the apiserver error is likely to be resolved by retrying the request.
+func isRetriable(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+// refreshNodeTerminationCondition adds the terminating condition to the node, or updates the existing
+// condition with the latest notice, refreshing its heartbeat. It returns false if no update is needed.
+func refreshNodeTerminationCondition(node *corev1.Node, action *Notice, now time.Time) bool {
+	message := terminationConditionMessage(action)
+	for i, condition := range node.Status.Conditions {
+		if condition.Type != terminatingConditionType || condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		if condition.Message == message && now.Sub(condition.LastHeartbeatTime.Time) < conditionHeartbeatInterval {
+			return false
+		}
+		node.Status.Conditions[i].LastHeartbeatTime = metav1.NewTime(now)
+		node.Status.Conditions[i].Reason = terminationRequestedReason
+		node.Status.Conditions[i].Message = message
+		return true
+	}
+
+	addNodeTerminationCondition(node, action)
+	return true
+}
+
+func terminationConditionMessage(action *Notice) string {
+	return fmt.Sprintf("The cloud provider has marked this instance for interruption, action %s at %s", action.Action, action.Time)
+}
+
+// nodeHasCondition checks whether the node already
+// has a condition with the given type
+func nodeHasCondition(node *corev1.Node, conditionType corev1.NodeConditionType) bool {
 	for _, condition := range node.Status.Conditions {
-		if condition.Type == terminatingConditionType {
+		if condition.Type == conditionType {
 			return true
 		}
 	}
@@ -162,41 +465,45 @@ func nodeHasTerminationCondition(node *corev1.Node) bool {
 
 // addNodeTerminationCondition will add a condition with a
 // terminatingConditionType type to the node
-func addNodeTerminationCondition(node *corev1.Node) {
+func addNodeTerminationCondition(node *corev1.Node, action *Notice) {
 	now := metav1.Now()
-	terminatingCondition := corev1.NodeCondition{
+	setNodeCondition(node, corev1.NodeCondition{
 		Type:               terminatingConditionType,
 		Status:             corev1.ConditionTrue,
 		LastHeartbeatTime:  now,
 		LastTransitionTime: now,
 		Reason:             terminationRequestedReason,
-		Message:            "The cloud provider has marked this instance for termination",
-	}
+		Message:            terminationConditionMessage(action),
+	})
+}
 
-	if !nodeHasTerminationCondition(node) {
+// setNodeCondition will add the condition to the node,
+// or replace an existing condition of the same type if its status differs
+func setNodeCondition(node *corev1.Node, newCondition corev1.NodeCondition) {
+	if !nodeHasCondition(node, newCondition.Type) {
 		// No need to merge, just add the new condition to the end
-		node.Status.Conditions = append(node.Status.Conditions, terminatingCondition)
+		node.Status.Conditions = append(node.Status.Conditions, newCondition)
 		return
 	}
 
-	// The node already has a terminating condition,
+	// The node already has a condition of this type,
 	// so make sure it has the correct status
 	conditions := []corev1.NodeCondition{}
 	for _, condition := range node.Status.Conditions {
-		if condition.Type != terminatingConditionType {
+		if condition.Type != newCondition.Type {
 			conditions = append(conditions, condition)
 			continue
 		}
 
-		// Condition type is terminating
-		if condition.Status == corev1.ConditionTrue {
-			// Condition already marked true, do not update
+		// Condition type matches
+		if condition.Status == newCondition.Status {
+			// Condition already has the right status, do not update
 			conditions = append(conditions, condition)
 			continue
 		}
 
-		// The existing terminating condition had the wrong status
-		conditions = append(conditions, terminatingCondition)
+		// The existing condition had the wrong status
+		conditions = append(conditions, newCondition)
 	}
 
 	node.Status.Conditions = conditions
@@ -14,27 +14,26 @@ limitations under the License.
 package termination
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
-	"net/http/httptest"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2/klogr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-var notFoundFunc = func(rw http.ResponseWriter, req *http.Request) {
-	rw.WriteHeader(404)
-}
-
 var _ = Describe("Handler Suite", func() {
-	var terminationServer *httptest.Server
-	var httpHandler http.Handler
+	var source *fakeNoticeSource
 	var nodeName string
 	var testNode *corev1.Node
 	var stop chan struct{}
@@ -64,10 +63,8 @@ var _ = Describe("Handler Suite", func() {
 
 	BeforeEach(func() {
 		// Reset test vars
-		terminationServer = nil
-		httpHandler = nil
 		nodeName = "test-node"
-		httpHandler = newMockHTTPHandler(notFoundFunc)
+		source = &fakeNoticeSource{}
 		stop = nil
 		errs = nil
 
@@ -76,26 +73,17 @@ var _ = Describe("Handler Suite", func() {
 
 		// use NewHandler() instead of manual construction in order to test NewHandler() logic
 		// like checking that machine api is added to scheme
-		handlerInterface, err := NewHandler(klogr.New(), cfg, 100*time.Millisecond, "", nodeName)
+		handlerInterface, err := NewHandler(klogr.New(), cfg, 100*time.Millisecond, "", nodeName, HandlerOptions{})
 		Expect(err).ToNot(HaveOccurred())
 
 		h = handlerInterface.(*handler)
-	})
-
-	JustBeforeEach(func() {
-		Expect(httpHandler).ToNot(BeNil())
-		terminationServer = httptest.NewServer(httpHandler)
-
-		if h.endpoint == nil {
-			h.endpoint = &terminationServer.URL
-		}
+		h.sources = []NoticeSource{source}
 	})
 
 	AfterEach(func() {
 		if stop != nil && !isClosed(stop) {
 			close(stop)
 		}
-		terminationServer.Close()
 
 		Expect(deleteAllNodes(k8sClient)).To(Succeed())
 	})
@@ -115,25 +103,15 @@ var _ = Describe("Handler Suite", func() {
 			})
 		})
 
-		Context("when polling the termination endpoint", func() {
-			var counter int32
-
+		Context("when polling the notice sources", func() {
 			BeforeEach(func() {
-				counter = 0
 				// Ensure the polling logic is excercised in tests
-				httpHandler = newMockHTTPHandler(func(rw http.ResponseWriter, req *http.Request) {
-					if atomic.LoadInt32(&counter) == 4 {
-						rw.WriteHeader(200)
-					} else {
-						atomic.AddInt32(&counter, 1)
-						rw.WriteHeader(404)
-					}
-				})
+				source.setNoticesAfter(4, []Notice{{Type: SpotInterruption, Action: "terminate", Time: "2017-09-18T08:22:00Z"}}, nil)
 			})
 
 			JustBeforeEach(func() {
 				// Ensure the polling logic is excercised in tests
-				for atomic.LoadInt32(&counter) < 4 {
+				for source.polls() < 4 {
 					continue
 				}
 			})
@@ -156,15 +134,102 @@ var _ = Describe("Handler Suite", func() {
 				It("should mark the node for deletion", func() {
 					Eventually(nodeMarkedForDeletion(testNode.Name)).Should(BeTrue())
 				})
+
+				It("should report ready", func() {
+					Expect(h.Readyz(nil)).To(Succeed())
+				})
+
+				It("should keep monitoring the node", func() {
+					Eventually(nodeMarkedForDeletion(testNode.Name)).Should(BeTrue())
+					Consistently(errs).ShouldNot(Receive())
+				})
+
+				It("should restore the condition if it is removed", func() {
+					Eventually(nodeMarkedForDeletion(testNode.Name)).Should(BeTrue())
+
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(testNode), testNode)).To(Succeed())
+					testNode.Status.Conditions = nil
+					Expect(k8sClient.Status().Update(ctx, testNode)).To(Succeed())
+
+					Eventually(nodeMarkedForDeletion(testNode.Name)).Should(BeTrue())
+				})
+
+				Context("and the node has a machine", func() {
+					var machine *machinev1beta1.Machine
+
+					eventReasons := func(object client.Object) func() ([]string, error) {
+						return func() ([]string, error) {
+							events := &corev1.EventList{}
+							if err := k8sClient.List(ctx, events); err != nil {
+								return nil, err
+							}
+							reasons := []string{}
+							for _, event := range events.Items {
+								if event.InvolvedObject.UID == object.GetUID() {
+									reasons = append(reasons, event.Reason)
+								}
+							}
+							return reasons, nil
+						}
+					}
+
+					BeforeEach(func() {
+						machine = &machinev1beta1.Machine{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "test-machine",
+								Namespace: "default",
+							},
+						}
+						Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+						testNode.Annotations = map[string]string{machineAnnotation: "default/test-machine"}
+						Expect(k8sClient.Update(ctx, testNode)).To(Succeed())
+					})
+
+					AfterEach(func() {
+						Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+					})
+
+					It("should record an event on the node and the machine", func() {
+						Eventually(eventReasons(testNode)).Should(ContainElement(interruptionNoticeReceivedReason))
+						Eventually(eventReasons(machine)).Should(ContainElement(interruptionNoticeReceivedReason))
+					})
+
+					It("should record the interruption on the machine", func() {
+						Eventually(func() (*machinev1beta1.Machine, error) {
+							m := &machinev1beta1.Machine{}
+							return m, k8sClient.Get(ctx, client.ObjectKeyFromObject(machine), m)
+						}).Should(And(
+							HaveField("Annotations", HaveKeyWithValue(InterruptionTimeAnnotation, "2017-09-18T08:22:00Z")),
+							HaveField("Annotations", HaveKeyWithValue(InterruptionReasonAnnotation, spotInterruptionReason)),
+							HaveField("Status.Conditions", ContainElement(And(
+								HaveField("Type", InstanceInterruptedCondition),
+								HaveField("Status", corev1.ConditionTrue),
+								HaveField("Reason", spotInterruptionReason),
+								HaveField("Message", "The cloud provider will terminate the instance at 2017-09-18T08:22:00Z"),
+							))),
+						))
+					})
+
+					Context("in another namespace than the handler's", func() {
+						BeforeEach(func() {
+							h.namespace = "openshift-machine-api"
+						})
+
+						It("should not record the interruption on the machine", func() {
+							Eventually(nodeMarkedForDeletion(testNode.Name)).Should(BeTrue())
+							Consistently(func() (map[string]string, error) {
+								m := &machinev1beta1.Machine{}
+								return m.Annotations, k8sClient.Get(ctx, client.ObjectKeyFromObject(machine), m)
+							}).ShouldNot(HaveKey(InterruptionTimeAnnotation))
+						})
+					})
+				})
 			})
 
 			Context("and the instance termination notice is not fulfilled", func() {
 				BeforeEach(func() {
-					// we need to wrap the notFoundFunc so that we obey the counting mechanism of the polling tests
-					httpHandler = newMockHTTPHandler(func(rw http.ResponseWriter, req *http.Request) {
-						atomic.AddInt32(&counter, 1)
-						notFoundFunc(rw, req)
-					})
+					source.setNoticesAfter(0, nil, nil)
 				})
 
 				It("should not mark the node for deletion", func() {
@@ -172,20 +237,14 @@ var _ = Describe("Handler Suite", func() {
 				})
 			})
 
-			Context("and the instance termination endpoint returns an unknown status", func() {
+			Context("and the notice source starts failing", func() {
 				BeforeEach(func() {
-					httpHandler = newMockHTTPHandler(func(rw http.ResponseWriter, req *http.Request) {
-						if atomic.LoadInt32(&counter) == 4 {
-							rw.WriteHeader(500)
-						} else {
-							atomic.AddInt32(&counter, 1)
-							rw.WriteHeader(404)
-						}
-					})
+					source.setNoticesAfter(4, nil, errors.New("unexpected status code 500"))
 				})
 
-				It("should return an error", func() {
-					Eventually(errs).Should(Receive(MatchError("error polling termination endpoint: EC2MetadataError: failed to make EC2Metadata request\n\n\tstatus code: 500, request id: ")))
+				It("should keep polling and report not ready", func() {
+					Consistently(errs).ShouldNot(Receive())
+					Eventually(func() error { return h.Readyz(nil) }).Should(MatchError(ContainSubstring("notice sources last polled successfully")))
 				})
 
 				It("should not delete the machine", func() {
@@ -194,15 +253,15 @@ var _ = Describe("Handler Suite", func() {
 			})
 		})
 
-		Context("when the termination endpoint is invalid", func() {
-			Context("and the poll URL cannot be reached", func() {
+		Context("when the notice source cannot be polled", func() {
+			Context("and it always fails", func() {
 				BeforeEach(func() {
-					nonReachable := "abc#1://localhost"
-					h.endpoint = &nonReachable
+					source.setNoticesAfter(0, nil, errors.New("error sending metadata request"))
 				})
 
-				It("should return an error", func() {
-					Eventually(errs).Should(Receive(MatchError(ContainSubstring("RequestError: send request failed\ncaused by: Get \"/latest/meta-data/spot/termination-time#1://localhost\": unsupported protocol scheme \"\""))))
+				It("should keep polling and report not ready", func() {
+					Consistently(errs).ShouldNot(Receive())
+					Expect(h.Readyz(nil)).To(MatchError("notice sources not polled successfully yet"))
 				})
 
 				It("should not delete the machine", func() {
@@ -211,9 +270,467 @@ var _ = Describe("Handler Suite", func() {
 			})
 		})
 
+		Context("when watching rebalance recommendations", func() {
+			var machine *machinev1beta1.Machine
+
+			nodeRebalanceRecommended := func(nodeName string) func() (bool, error) {
+				key := client.ObjectKey{Name: nodeName}
+				return func() (bool, error) {
+					n := &corev1.Node{}
+					if err := k8sClient.Get(ctx, key, n); err != nil {
+						return false, err
+					}
+					for _, condition := range n.Status.Conditions {
+						if condition.Type == rebalanceRecommendedConditionType {
+							return condition.Status == corev1.ConditionTrue && condition.Reason == rebalanceRecommendationReceivedReason, nil
+						}
+					}
+					return false, nil
+				}
+			}
+
+			machineRebalanceAnnotation := func() (string, error) {
+				m := &machinev1beta1.Machine{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(machine), m); err != nil {
+					return "", err
+				}
+				return m.Annotations[RebalanceRecommendedAnnotation], nil
+			}
+
+			BeforeEach(func() {
+				h.options.WatchRebalanceRecommendations = true
+
+				machine = &machinev1beta1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-machine",
+						Namespace: "default",
+					},
+				}
+				Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+				testNode.Annotations = map[string]string{machineAnnotation: "default/test-machine"}
+				Expect(k8sClient.Update(ctx, testNode)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			Context("and a rebalance recommendation is issued", func() {
+				BeforeEach(func() {
+					source.setNoticesAfter(0, []Notice{{Type: RebalanceRecommendation, Time: "2020-10-27T08:22:00Z"}}, nil)
+				})
+
+				It("should mark the node as rebalance recommended", func() {
+					Eventually(nodeRebalanceRecommended(testNode.Name)).Should(BeTrue())
+				})
+
+				It("should not mark the node for deletion", func() {
+					Consistently(nodeMarkedForDeletion(testNode.Name)).Should(BeFalse())
+				})
+
+				It("should not annotate the machine", func() {
+					Eventually(nodeRebalanceRecommended(testNode.Name)).Should(BeTrue())
+					Consistently(machineRebalanceAnnotation).Should(BeEmpty())
+				})
+
+				Context("and machine annotation is enabled", func() {
+					BeforeEach(func() {
+						h.options.AnnotateMachineOnRebalanceRecommendation = true
+					})
+
+					It("should annotate the machine with the notice time", func() {
+						Eventually(machineRebalanceAnnotation).Should(Equal("2020-10-27T08:22:00Z"))
+					})
+				})
+			})
+
+			Context("and no rebalance recommendation is issued", func() {
+				It("should not mark the node as rebalance recommended", func() {
+					Consistently(nodeRebalanceRecommended(testNode.Name)).Should(BeFalse())
+				})
+			})
+
+			Context("and watching rebalance recommendations is disabled", func() {
+				BeforeEach(func() {
+					h.options.WatchRebalanceRecommendations = false
+					source.setNoticesAfter(0, []Notice{{Type: RebalanceRecommendation, Time: "2020-10-27T08:22:00Z"}}, nil)
+				})
+
+				It("should not mark the node as rebalance recommended", func() {
+					Consistently(nodeRebalanceRecommended(testNode.Name)).Should(BeFalse())
+				})
+			})
+		})
+
+		Context("when watching scheduled maintenance", func() {
+			var machine *machinev1beta1.Machine
+
+			nodeMaintenanceCondition := func(nodeName string) func() (*corev1.NodeCondition, error) {
+				key := client.ObjectKey{Name: nodeName}
+				return func() (*corev1.NodeCondition, error) {
+					n := &corev1.Node{}
+					if err := k8sClient.Get(ctx, key, n); err != nil {
+						return nil, err
+					}
+					for _, condition := range n.Status.Conditions {
+						if condition.Type == maintenanceScheduledConditionType {
+							return &condition, nil
+						}
+					}
+					return nil, nil
+				}
+			}
+
+			machineDeleteAnnotation := func() (string, error) {
+				m := &machinev1beta1.Machine{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(machine), m); err != nil {
+					return "", err
+				}
+				return m.Annotations[DeleteMachineAnnotation], nil
+			}
+
+			BeforeEach(func() {
+				h.options.WatchScheduledMaintenance = true
+				source.setNoticesAfter(0, []Notice{{
+					Type:    ScheduledMaintenance,
+					Action:  "system-reboot",
+					Time:    time.Now().Add(time.Hour).UTC().Format(maintenanceEventTimeLayout),
+					EventID: "instance-event-0d59937288b749b32",
+				}}, nil)
+
+				machine = &machinev1beta1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-machine",
+						Namespace: "default",
+					},
+				}
+				Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+				testNode.Annotations = map[string]string{machineAnnotation: "default/test-machine"}
+				Expect(k8sClient.Update(ctx, testNode)).To(Succeed())
+
+			})
+
+			AfterEach(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			Context("and a maintenance event is scheduled", func() {
+				It("should mark the node with the pending event", func() {
+					Eventually(nodeMaintenanceCondition(testNode.Name)).Should(And(
+						HaveField("Status", corev1.ConditionTrue),
+						HaveField("Reason", maintenanceEventScheduledReason),
+						HaveField("Message", ContainSubstring("system-reboot (instance-event-0d59937288b749b32) not before")),
+					))
+				})
+
+				It("should not mark the machine for deletion", func() {
+					Eventually(nodeMaintenanceCondition(testNode.Name)).ShouldNot(BeNil())
+					Consistently(machineDeleteAnnotation).Should(BeEmpty())
+				})
+
+				Context("and the event is due within the deletion lead time", func() {
+					BeforeEach(func() {
+						h.options.MarkMachineForDeletionBeforeMaintenance = 2 * time.Hour
+					})
+
+					It("should mark the machine for deletion", func() {
+						Eventually(machineDeleteAnnotation).Should(Equal("true"))
+					})
+				})
+
+				Context("and the event is not due within the deletion lead time", func() {
+					BeforeEach(func() {
+						h.options.MarkMachineForDeletionBeforeMaintenance = 30 * time.Minute
+					})
+
+					It("should not mark the machine for deletion", func() {
+						Eventually(nodeMaintenanceCondition(testNode.Name)).ShouldNot(BeNil())
+						Consistently(machineDeleteAnnotation).Should(BeEmpty())
+					})
+				})
+			})
+
+			Context("and no maintenance event is scheduled", func() {
+				BeforeEach(func() {
+					source.setNoticesAfter(0, nil, nil)
+				})
+
+				It("should not add the condition to the node", func() {
+					Consistently(nodeMaintenanceCondition(testNode.Name)).Should(BeNil())
+				})
+
+				Context("and the node was previously marked", func() {
+					BeforeEach(func() {
+						now := metav1.Now()
+						testNode.Status.Conditions = []corev1.NodeCondition{{
+							Type:               maintenanceScheduledConditionType,
+							Status:             corev1.ConditionTrue,
+							Reason:             maintenanceEventScheduledReason,
+							LastTransitionTime: now,
+							LastHeartbeatTime:  now,
+						}}
+						Expect(k8sClient.Status().Update(ctx, testNode)).To(Succeed())
+					})
+
+					It("should clear the condition", func() {
+						Eventually(nodeMaintenanceCondition(testNode.Name)).Should(And(
+							HaveField("Status", corev1.ConditionFalse),
+							HaveField("Reason", noMaintenanceScheduledReason),
+						))
+					})
+
+					Context("and the notice source fails", func() {
+						BeforeEach(func() {
+							source.setNoticesAfter(0, nil, errors.New("unexpected status code 500"))
+						})
+
+						It("should not clear the condition", func() {
+							Consistently(nodeMaintenanceCondition(testNode.Name)).Should(HaveField("Status", corev1.ConditionTrue))
+						})
+					})
+				})
+			})
+		})
+
+		Context("when draining on termination", func() {
+			var pods []*corev1.Pod
+			var pdb *policyv1.PodDisruptionBudget
+
+			newTestPod := func(name string, labels map[string]string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "default",
+						Labels:    labels,
+					},
+					Spec: corev1.PodSpec{
+						NodeName:   nodeName,
+						Containers: []corev1.Container{{Name: "test", Image: "test"}},
+					},
+				}
+			}
+
+			podEvicted := func(pod *corev1.Pod) func() (bool, error) {
+				return func() (bool, error) {
+					p := &corev1.Pod{}
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), p)
+					if apierrors.IsNotFound(err) {
+						return true, nil
+					}
+					return p.DeletionTimestamp != nil, err
+				}
+			}
+
+			podEventReasons := func(pod *corev1.Pod) func() ([]string, error) {
+				return func() ([]string, error) {
+					events := &corev1.EventList{}
+					if err := k8sClient.List(ctx, events, client.InNamespace(pod.Namespace)); err != nil {
+						return nil, err
+					}
+					reasons := []string{}
+					for _, event := range events.Items {
+						if event.InvolvedObject.Name == pod.Name {
+							reasons = append(reasons, event.Reason)
+						}
+					}
+					return reasons, nil
+				}
+			}
+
+			BeforeEach(func() {
+				h.options.DrainOnTermination = true
+				pdb = nil
+				pods = []*corev1.Pod{
+					newTestPod("evictable", nil),
+					newTestPod("protected", map[string]string{"app": "protected"}),
+				}
+
+				for _, pod := range pods {
+					Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+					// Pending pods bypass PodDisruptionBudgets, so report them as running and ready
+					pod.Status = corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					}
+					Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+				}
+
+				noticeTime := time.Now().Add(3 * time.Second).UTC().Format(time.RFC3339)
+				source.setNoticesAfter(0, []Notice{{Type: SpotInterruption, Action: "terminate", Time: noticeTime}}, nil)
+			})
+
+			AfterEach(func() {
+				for _, pod := range pods {
+					Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, pod, client.GracePeriodSeconds(0)))).To(Succeed())
+				}
+				if pdb != nil {
+					Expect(k8sClient.Delete(ctx, pdb)).To(Succeed())
+				}
+			})
+
+			It("should cordon and taint the node", func() {
+				Eventually(func() (*corev1.Node, error) {
+					n := &corev1.Node{}
+					return n, k8sClient.Get(ctx, client.ObjectKey{Name: nodeName}, n)
+				}).Should(And(
+					HaveField("Spec.Unschedulable", BeTrue()),
+					HaveField("Spec.Taints", ContainElement(And(
+						HaveField("Key", InterruptionTaintKey),
+						HaveField("Value", "terminate"),
+						HaveField("Effect", corev1.TaintEffectNoSchedule),
+					))),
+				))
+			})
+
+			It("should evict the pods and report it", func() {
+				for _, pod := range pods {
+					Eventually(podEvicted(pod)).Should(BeTrue())
+					Eventually(podEventReasons(pod)).Should(ContainElement(podEvictedReason))
+				}
+			})
+
+			Context("and the notice hibernates the instance", func() {
+				BeforeEach(func() {
+					noticeTime := time.Now().Add(3 * time.Second).UTC().Format(time.RFC3339)
+					source.setNoticesAfter(0, []Notice{{Type: SpotInterruption, Action: "hibernate", Time: noticeTime}}, nil)
+				})
+
+				It("should neither mark the node for deletion nor drain it", func() {
+					Eventually(source.polls).Should(BeNumerically(">", 2))
+					Consistently(nodeMarkedForDeletion(testNode.Name), time.Second).Should(BeFalse())
+					Expect(k8sClient.Get(ctx, client.ObjectKey{Name: nodeName}, testNode)).To(Succeed())
+					Expect(testNode.Spec.Unschedulable).To(BeFalse())
+					Expect(podEvicted(pods[0])()).To(BeFalse())
+				})
+			})
+
+			Context("and the machine of the node cannot be patched", func() {
+				var machine *machinev1beta1.Machine
+
+				BeforeEach(func() {
+					machine = &machinev1beta1.Machine{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "test-machine",
+							Namespace: "default",
+						},
+					}
+					Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+					testNode.Annotations = map[string]string{machineAnnotation: "default/test-machine"}
+					Expect(k8sClient.Update(ctx, testNode)).To(Succeed())
+
+					h.client = machinePatchFailingClient{Client: h.client}
+				})
+
+				AfterEach(func() {
+					Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+				})
+
+				It("should still drain the node", func() {
+					Eventually(func() (*corev1.Node, error) {
+						n := &corev1.Node{}
+						return n, k8sClient.Get(ctx, client.ObjectKey{Name: nodeName}, n)
+					}).Should(HaveField("Spec.Unschedulable", BeTrue()))
+					Eventually(podEvicted(pods[0])).Should(BeTrue())
+				})
+			})
+
+			Context("and a PodDisruptionBudget does not allow the eviction", func() {
+				BeforeEach(func() {
+					minAvailable := intstr.FromInt32(1)
+					pdb = &policyv1.PodDisruptionBudget{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "protected",
+							Namespace: "default",
+						},
+						Spec: policyv1.PodDisruptionBudgetSpec{
+							MinAvailable: &minAvailable,
+							Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "protected"}},
+						},
+					}
+					Expect(k8sClient.Create(ctx, pdb)).To(Succeed())
+				})
+
+				It("should evict the other pods", func() {
+					Eventually(podEvicted(pods[0])).Should(BeTrue())
+				})
+
+				It("should keep polling and report ready while the drain is blocked", func() {
+					Eventually(podEvicted(pods[0])).Should(BeTrue())
+					Consistently(func() error { return h.Readyz(nil) }, time.Second).Should(Succeed())
+				})
+
+				It("should give up on the protected pod at the notice time and report it", func() {
+					Eventually(podEventReasons(pods[1]), 15*time.Second).Should(ContainElement(podEvictionFailedReason))
+					Expect(podEvicted(pods[1])()).To(BeFalse())
+					Expect(errs).ToNot(Receive())
+				})
+
+				Context("and the notice changes", func() {
+					BeforeEach(func() {
+						noticeTime := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+						source.setNoticesAfter(0, []Notice{{Type: SpotInterruption, Action: "terminate", Time: noticeTime}}, nil)
+					})
+
+					It("should stop the drain of the previous notice", func() {
+						Eventually(podEvicted(pods[0])).Should(BeTrue())
+
+						noticeTime := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+						source.setNoticesAfter(0, []Notice{{Type: SpotInterruption, Action: "hibernate", Time: noticeTime}}, nil)
+
+						// The protected pod is given up on well before the deadline of the previous notice.
+						Eventually(podEventReasons(pods[1]), 5*time.Second).Should(ContainElement(podEvictionFailedReason))
+						Expect(podEvicted(pods[1])()).To(BeFalse())
+					})
+				})
+			})
+		})
+
+		Context("refreshNodeTerminationCondition", func() {
+			var action *Notice
+			var now time.Time
+
+			BeforeEach(func() {
+				action = &Notice{Type: SpotInterruption, Action: "terminate", Time: "2017-09-18T08:22:00Z"}
+				now = time.Now()
+			})
+
+			It("should add the condition to a node without it", func() {
+				Expect(refreshNodeTerminationCondition(testNode, action, now)).To(BeTrue())
+				Expect(testNode.Status.Conditions).To(ConsistOf(HaveField("Type", terminatingConditionType)))
+			})
+
+			Context("with a recent condition for the same notice", func() {
+				BeforeEach(func() {
+					addNodeTerminationCondition(testNode, action)
+				})
+
+				It("should not update the condition", func() {
+					Expect(refreshNodeTerminationCondition(testNode, action, now.Add(time.Second))).To(BeFalse())
+				})
+
+				It("should refresh the heartbeat once it is stale", func() {
+					transition := testNode.Status.Conditions[0].LastTransitionTime
+					later := now.Add(2 * conditionHeartbeatInterval)
+
+					Expect(refreshNodeTerminationCondition(testNode, action, later)).To(BeTrue())
+					Expect(testNode.Status.Conditions).To(HaveLen(1))
+					Expect(testNode.Status.Conditions[0].LastHeartbeatTime.Time).To(BeTemporally("==", later))
+					Expect(testNode.Status.Conditions[0].LastTransitionTime).To(Equal(transition))
+				})
+
+				It("should update the message when the notice changes", func() {
+					Expect(refreshNodeTerminationCondition(testNode, &Notice{Type: SpotInterruption, Action: "stop", Time: action.Time}, now)).To(BeTrue())
+					Expect(testNode.Status.Conditions[0].Message).To(ContainSubstring("action stop"))
+				})
+			})
+		})
+
 		Context("addNodeTerminationCondition", func() {
 			JustBeforeEach(func() {
-				addNodeTerminationCondition(testNode)
+				addNodeTerminationCondition(testNode, &Notice{Type: SpotInterruption, Action: "terminate", Time: "2017-09-18T08:22:00Z"})
 			})
 
 			Context("with no existing conditions", func() {
@@ -311,22 +828,64 @@ var _ = Describe("Handler Suite", func() {
 	})
 })
 
-// mockHTTPHandler is used to mock the endpoint responses during tests
-type mockHTTPHandler struct {
-	handleFunc func(rw http.ResponseWriter, req *http.Request)
+// fakeNoticeSource is used to issue notices during tests
+type fakeNoticeSource struct {
+	lock        sync.Mutex
+	after       int
+	notices     []Notice
+	err         error
+	pollCounter int
+}
+
+// Name implements NoticeSource
+func (s *fakeNoticeSource) Name() string {
+	return "fake"
+}
+
+// Notices implements NoticeSource. It returns no notices until it has been polled
+// the configured number of times, then the configured notices and error.
+func (s *fakeNoticeSource) Notices(_ context.Context) ([]Notice, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pollCounter++
+	if s.pollCounter <= s.after {
+		return nil, nil
+	}
+	return s.notices, s.err
 }
 
-// ServeHTTP implements the http.Handler interface
-func (m *mockHTTPHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	m.handleFunc(rw, req)
+// setNoticesAfter makes the source return the notices and error once it has been polled after times
+func (s *fakeNoticeSource) setNoticesAfter(after int, notices []Notice, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.after = after
+	s.notices = notices
+	s.err = err
 }
 
-// newMockHTTPHandler constructs a mockHTTPHandler with the given handleFunc
-func newMockHTTPHandler(handleFunc func(http.ResponseWriter, *http.Request)) http.Handler {
-	return &mockHTTPHandler{handleFunc: handleFunc}
+// polls returns the number of times the source has been polled
+func (s *fakeNoticeSource) polls() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.pollCounter
 }
 
 // isClosed checks if a channel is closed already
+// machinePatchFailingClient refuses to patch Machines, as when the handler is not allowed to.
+type machinePatchFailingClient struct {
+	client.Client
+}
+
+func (c machinePatchFailingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*machinev1beta1.Machine); ok {
+		return apierrors.NewForbidden(machinev1beta1.Resource("machines"), obj.GetName(), errors.New("not allowed"))
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
 func isClosed(ch <-chan struct{}) bool {
 	select {
 	case <-ch:
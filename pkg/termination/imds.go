@@ -0,0 +1,159 @@
+package termination
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultIMDSEndpoint is the address of the instance metadata service.
+	defaultIMDSEndpoint = "http://169.254.169.254"
+
+	imdsTokenPath           = "/latest/api/token"
+	imdsMetadataPathPrefix  = "/latest/meta-data/"
+	imdsTokenHeader         = "X-aws-ec2-metadata-token"
+	imdsTokenTTLHeader      = "X-aws-ec2-metadata-token-ttl-seconds"
+	imdsTokenTTL            = 6 * time.Hour
+	imdsTokenRefreshWindow  = time.Minute
+	imdsRequestTimeout      = 5 * time.Second
+	imdsMaxResponseBodySize = 1 << 20
+)
+
+// imdsClient reads instance metadata using IMDSv2 session tokens.
+// Tokens are fetched on first use and refreshed before they expire or when rejected.
+// If the metadata service does not support tokens, the client falls back to IMDSv1.
+type imdsClient struct {
+	endpoint   string
+	httpClient *http.Client
+
+	lock          sync.Mutex
+	token         string
+	tokenExpiry   time.Time
+	tokenDisabled bool
+}
+
+// newIMDSClient returns an imdsClient for the given endpoint, or the default endpoint if empty.
+func newIMDSClient(endpoint string) *imdsClient {
+	if endpoint == "" {
+		endpoint = defaultIMDSEndpoint
+	}
+	return &imdsClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{Timeout: imdsRequestTimeout},
+	}
+}
+
+// getMetadata returns the content of the metadata path, relative to /latest/meta-data.
+// found is false if the metadata service has no content at the path.
+func (c *imdsClient) getMetadata(ctx context.Context, path string) (content string, found bool, err error) {
+	start := time.Now()
+	defer func() {
+		imdsPollDuration.WithLabelValues(path).Observe(time.Since(start).Seconds())
+		if err != nil {
+			imdsPollFailures.WithLabelValues(path).Inc()
+		}
+	}()
+
+	token, err := c.getToken(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	statusCode, body, err := c.do(ctx, http.MethodGet, imdsMetadataPathPrefix+path, token, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	if statusCode == http.StatusUnauthorized && token != "" {
+		// The token expired or was revoked, fetch a new one and try again
+		c.resetToken()
+		if token, err = c.getToken(ctx); err != nil {
+			return "", false, err
+		}
+		if statusCode, body, err = c.do(ctx, http.MethodGet, imdsMetadataPathPrefix+path, token, nil); err != nil {
+			return "", false, err
+		}
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+		return body, true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("unexpected status code %d from metadata service at %s", statusCode, path)
+	}
+}
+
+// getToken returns a valid session token, or an empty token if the metadata service does not support them.
+func (c *imdsClient) getToken(ctx context.Context) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.tokenDisabled {
+		return "", nil
+	}
+	if c.token != "" && time.Now().Before(c.tokenExpiry.Add(-imdsTokenRefreshWindow)) {
+		return c.token, nil
+	}
+
+	headers := map[string]string{imdsTokenTTLHeader: strconv.Itoa(int(imdsTokenTTL.Seconds()))}
+	statusCode, body, err := c.do(ctx, http.MethodPut, imdsTokenPath, "", headers)
+	if err != nil {
+		return "", fmt.Errorf("error fetching metadata session token: %w", err)
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+		c.token = strings.TrimSpace(body)
+		c.tokenExpiry = time.Now().Add(imdsTokenTTL)
+		return c.token, nil
+	case http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed:
+		// Tokens are not supported, fall back to IMDSv1
+		c.tokenDisabled = true
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected status code %d fetching metadata session token", statusCode)
+	}
+}
+
+// resetToken discards the current session token.
+func (c *imdsClient) resetToken() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.token = ""
+}
+
+// do sends a request to the metadata service and returns the status code and body of the response.
+// Errors are only returned if no response was received.
+func (c *imdsClient) do(ctx context.Context, method, path, token string, headers map[string]string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("error creating metadata request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set(imdsTokenHeader, token)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("error sending metadata request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, imdsMaxResponseBodySize))
+	if err != nil {
+		return 0, "", fmt.Errorf("error reading metadata response: %w", err)
+	}
+	return resp.StatusCode, string(body), nil
+}
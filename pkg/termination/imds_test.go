@@ -0,0 +1,101 @@
+package termination
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IMDS client", func() {
+	var imdsServer *httptest.Server
+	var tokensIssued int32
+	var validToken string
+	var tokenStatus int
+
+	BeforeEach(func() {
+		tokensIssued = 0
+		validToken = ""
+		tokenStatus = http.StatusOK
+
+		imdsServer = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.URL.Path == imdsTokenPath:
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.Header.Get(imdsTokenTTLHeader)).To(Equal("21600"))
+				if tokenStatus != http.StatusOK {
+					rw.WriteHeader(tokenStatus)
+					return
+				}
+				validToken = fmt.Sprintf("token-%d", atomic.AddInt32(&tokensIssued, 1))
+				fmt.Fprint(rw, validToken)
+			case tokenStatus == http.StatusOK && req.Header.Get(imdsTokenHeader) != validToken:
+				rw.WriteHeader(http.StatusUnauthorized)
+			case req.URL.Path == imdsMetadataPathPrefix+awsInstanceActionPath:
+				fmt.Fprint(rw, `{"action": "stop", "time": "2017-09-18T08:22:00Z"}`)
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		imdsServer.Close()
+	})
+
+	It("should read metadata with a session token", func() {
+		c := newIMDSClient(imdsServer.URL)
+
+		notices, err := (&imdsSpotInterruptionSource{client: c}).Notices(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notices).To(ConsistOf(Notice{Type: SpotInterruption, Action: "stop", Time: "2017-09-18T08:22:00Z"}))
+
+		_, found, err := c.getMetadata(ctx, awsRebalanceRecommendationPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+		Expect(atomic.LoadInt32(&tokensIssued)).To(BeEquivalentTo(1))
+	})
+
+	It("should fetch a new session token when the token is rejected", func() {
+		c := newIMDSClient(imdsServer.URL)
+
+		_, _, err := c.getMetadata(ctx, awsInstanceActionPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		validToken = "revoked"
+		content, found, err := c.getMetadata(ctx, awsInstanceActionPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(content).To(ContainSubstring("stop"))
+		Expect(atomic.LoadInt32(&tokensIssued)).To(BeEquivalentTo(2))
+	})
+
+	It("should fall back to IMDSv1 when session tokens are not supported", func() {
+		tokenStatus = http.StatusNotFound
+		c := newIMDSClient(imdsServer.URL)
+
+		_, found, err := c.getMetadata(ctx, awsInstanceActionPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(atomic.LoadInt32(&tokensIssued)).To(BeEquivalentTo(0))
+	})
+
+	It("should return an error when the session token cannot be fetched", func() {
+		tokenStatus = http.StatusInternalServerError
+		c := newIMDSClient(imdsServer.URL)
+
+		_, _, err := c.getMetadata(ctx, awsInstanceActionPath)
+		Expect(err).To(MatchError("unexpected status code 500 fetching metadata session token"))
+	})
+
+	It("should return an error when the metadata service cannot be reached", func() {
+		imdsServer.Close()
+		c := newIMDSClient(imdsServer.URL)
+
+		_, _, err := c.getMetadata(ctx, awsInstanceActionPath)
+		Expect(err).To(MatchError(ContainSubstring("error sending metadata request")))
+	})
+})
@@ -0,0 +1,162 @@
+package termination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/machine-api-operator/pkg/util/conditions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// machineAnnotation is set by the machine controller on nodes, with the namespace/name of the node's Machine.
+	machineAnnotation = "machine.openshift.io/machine"
+
+	// InterruptionTimeAnnotation is set on the Machine of an interrupted instance, with the time of the
+	// interruption reported by EC2.
+	InterruptionTimeAnnotation = "machine.openshift.io/interruption-time"
+	// InterruptionReasonAnnotation is set on the Machine of an interrupted instance, with the reason of the
+	// interruption, e.g. SpotInterruption.
+	InterruptionReasonAnnotation = "machine.openshift.io/interruption-reason"
+
+	// InstanceInterruptedCondition is set on the Machine of an interrupted instance, so that spot reclaims
+	// can be told apart from other failures.
+	InstanceInterruptedCondition machinev1beta1.ConditionType = "InstanceInterrupted"
+	spotInterruptionReason                                    = "SpotInterruption"
+
+	// machineMarkMaxRetryDelay caps the delay between attempts to record an interruption on the Machine.
+	machineMarkMaxRetryDelay = time.Minute
+)
+
+// errNoMachine is returned when the node is not backed by a Machine.
+var errNoMachine = errors.New("node has no machine")
+
+// markMachineInterrupted records the interruption on the Machine of the node, as annotations
+// and the InstanceInterrupted condition. Nodes without a Machine are ignored.
+func (h *handler) markMachineInterrupted(ctx context.Context, action *Notice) error {
+	return retry.OnError(apiRetryBackoff, isRetriable, func() error {
+		node := &corev1.Node{}
+		if err := h.client.Get(ctx, client.ObjectKey{Name: h.nodeName}, node); err != nil {
+			return fmt.Errorf("error fetching node: %w", err)
+		}
+
+		machineKey, err := h.machineKeyForNode(node)
+		if errors.Is(err, errNoMachine) {
+			h.log.V(2).Info("Node has no machine, not marking it as interrupted")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		machine := &machinev1beta1.Machine{}
+		if err := h.client.Get(ctx, machineKey, machine); err != nil {
+			return fmt.Errorf("error fetching machine %s: %w", machineKey, err)
+		}
+
+		if machine.Annotations[InterruptionTimeAnnotation] != action.Time || machine.Annotations[InterruptionReasonAnnotation] != spotInterruptionReason {
+			patchBase := client.MergeFrom(machine.DeepCopy())
+			if machine.Annotations == nil {
+				machine.Annotations = map[string]string{}
+			}
+			machine.Annotations[InterruptionTimeAnnotation] = action.Time
+			machine.Annotations[InterruptionReasonAnnotation] = spotInterruptionReason
+			if err := h.client.Patch(ctx, machine, patchBase); err != nil {
+				return fmt.Errorf("error patching machine %s: %w", machineKey, err)
+			}
+		}
+
+		original := machine.DeepCopy()
+		conditions.Set(machine, conditions.TrueConditionWithReason(
+			InstanceInterruptedCondition,
+			spotInterruptionReason,
+			"The cloud provider will %s the instance at %s", action.Action, action.Time,
+		))
+		if reflect.DeepEqual(original.Status.Conditions, machine.Status.Conditions) {
+			return nil
+		}
+
+		patchBase := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		if err := h.client.Status().Patch(ctx, machine, patchBase); err != nil {
+			return fmt.Errorf("error patching machine %s status: %w", machineKey, err)
+		}
+		return nil
+	})
+}
+
+// markMachineInterruptedWithRetries records the interruption on the Machine of the node, retrying failures with an
+// increasing delay until it succeeds or ctx is cancelled.
+func (h *handler) markMachineInterruptedWithRetries(ctx context.Context, action *Notice) {
+	delay := h.pollInterval
+	for {
+		err := h.markMachineInterrupted(ctx, action)
+		if err == nil {
+			return
+		}
+		h.log.Error(err, "Failed to mark machine as interrupted, retrying", "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, machineMarkMaxRetryDelay)
+	}
+}
+
+// annotateMachine sets the annotation on the Machine of the node, unless it already has the given value.
+func (h *handler) annotateMachine(ctx context.Context, key, value string) error {
+	return retry.OnError(apiRetryBackoff, isRetriable, func() error {
+		node := &corev1.Node{}
+		if err := h.client.Get(ctx, client.ObjectKey{Name: h.nodeName}, node); err != nil {
+			return fmt.Errorf("error fetching node: %w", err)
+		}
+
+		machineKey, err := h.machineKeyForNode(node)
+		if err != nil {
+			return err
+		}
+
+		machine := &machinev1beta1.Machine{}
+		if err := h.client.Get(ctx, machineKey, machine); err != nil {
+			return fmt.Errorf("error fetching machine %s: %w", machineKey, err)
+		}
+		if current, ok := machine.Annotations[key]; ok && current == value {
+			return nil
+		}
+
+		patchBase := client.MergeFrom(machine.DeepCopy())
+		if machine.Annotations == nil {
+			machine.Annotations = map[string]string{}
+		}
+		machine.Annotations[key] = value
+		if err := h.client.Patch(ctx, machine, patchBase); err != nil {
+			return fmt.Errorf("error patching machine %s: %w", machineKey, err)
+		}
+		return nil
+	})
+}
+
+// machineKeyForNode returns the key of the Machine backing the node, as recorded by the machine controller.
+func (h *handler) machineKeyForNode(node *corev1.Node) (client.ObjectKey, error) {
+	value, ok := node.Annotations[machineAnnotation]
+	if !ok {
+		return client.ObjectKey{}, fmt.Errorf("node %s has no %s annotation: %w", node.Name, machineAnnotation, errNoMachine)
+	}
+
+	namespace, name, found := strings.Cut(value, "/")
+	if !found || namespace == "" || name == "" {
+		return client.ObjectKey{}, fmt.Errorf("node %s has an invalid %s annotation %q", node.Name, machineAnnotation, value)
+	}
+	if h.namespace != "" && namespace != h.namespace {
+		return client.ObjectKey{}, fmt.Errorf("machine %s of node %s is not in namespace %s", value, node.Name, h.namespace)
+	}
+	return client.ObjectKey{Namespace: namespace, Name: name}, nil
+}
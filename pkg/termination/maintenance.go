@@ -0,0 +1,127 @@
+package termination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// awsScheduledMaintenancePath is the metadata path of the scheduled events of the instance,
+	// relative to /latest/meta-data.
+	awsScheduledMaintenancePath                                = "events/maintenance/scheduled"
+	maintenanceScheduledConditionType corev1.NodeConditionType = "MaintenanceScheduled"
+	maintenanceEventScheduledReason                            = "MaintenanceEventScheduled"
+	noMaintenanceScheduledReason                               = "NoMaintenanceScheduled"
+
+	// maintenanceEventTimeLayout is the layout of the times of scheduled events, e.g. "21 Jan 2019 09:00:43 GMT".
+	maintenanceEventTimeLayout = "2 Jan 2006 15:04:05 MST"
+
+	// DeleteMachineAnnotation marks a Machine as preferred for deletion when its MachineSet is scaled down.
+	DeleteMachineAnnotation = "machine.openshift.io/delete-machine"
+)
+
+// handleScheduledMaintenance reflects the scheduled events of the instance in the node conditions.
+// When markMachine is set and an event is due within MarkMachineForDeletionBeforeMaintenance, the Machine of
+// the node is annotated for deletion. It returns true once the Machine has been annotated.
+func (h *handler) handleScheduledMaintenance(ctx context.Context, events []Notice, markMachine bool) (bool, error) {
+	if err := h.updateMaintenanceCondition(ctx, events); err != nil {
+		return false, fmt.Errorf("error marking node: %v", err)
+	}
+
+	if !markMachine || h.options.MarkMachineForDeletionBeforeMaintenance == 0 {
+		return false, nil
+	}
+
+	event, due := maintenanceEventDue(events, time.Now(), h.options.MarkMachineForDeletionBeforeMaintenance)
+	if !due {
+		return false, nil
+	}
+
+	h.log.V(1).Info("Scheduled maintenance event is due, marking Machine for deletion", "code", event.Action, "eventID", event.EventID, "notBefore", event.Time)
+	if err := h.annotateMachine(ctx, DeleteMachineAnnotation, "true"); err != nil {
+		return false, fmt.Errorf("error annotating machine: %v", err)
+	}
+	return true, nil
+}
+
+// maintenanceEventDue returns the first event starting within the lead time of now.
+// Events with a not-before time that cannot be parsed are considered due.
+func maintenanceEventDue(events []Notice, now time.Time, lead time.Duration) (Notice, bool) {
+	for _, event := range events {
+		notBefore, err := parseNoticeTime(event.Time)
+		if err != nil || !now.Add(lead).Before(notBefore) {
+			return event, true
+		}
+	}
+	return Notice{}, false
+}
+
+// updateMaintenanceCondition sets the MaintenanceScheduled condition of the node to reflect the pending events.
+// Nodes that never had scheduled events are left without the condition.
+func (h *handler) updateMaintenanceCondition(ctx context.Context, events []Notice) error {
+	condition := corev1.NodeCondition{
+		Type:    maintenanceScheduledConditionType,
+		Status:  corev1.ConditionFalse,
+		Reason:  noMaintenanceScheduledReason,
+		Message: "The cloud provider has no maintenance scheduled for this instance",
+	}
+	if len(events) > 0 {
+		descriptions := []string{}
+		for _, event := range events {
+			descriptions = append(descriptions, fmt.Sprintf("%s (%s) not before %s", event.Action, event.EventID, event.Time))
+		}
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = maintenanceEventScheduledReason
+		condition.Message = fmt.Sprintf("The cloud provider has scheduled maintenance for this instance: %s", strings.Join(descriptions, "; "))
+	}
+
+	newlyScheduled := false
+	err := h.updateNodeStatus(ctx, func(node *corev1.Node) bool {
+		var updated bool
+		updated, newlyScheduled = setMaintenanceCondition(node, condition, metav1.Now())
+		return updated
+	})
+	if err != nil {
+		return err
+	}
+
+	if newlyScheduled {
+		noticesReceived.WithLabelValues(scheduledMaintenanceNotice).Inc()
+	}
+	return nil
+}
+
+// setMaintenanceCondition sets the condition on the node, unless it is up to date. Nodes without the condition
+// only get it when maintenance is scheduled. It returns whether the node was updated, and whether maintenance
+// was newly scheduled.
+func setMaintenanceCondition(node *corev1.Node, condition corev1.NodeCondition, now metav1.Time) (bool, bool) {
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+
+	for i, existing := range node.Status.Conditions {
+		if existing.Type != maintenanceScheduledConditionType {
+			continue
+		}
+
+		if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+			// Condition is up to date, do not update
+			return false, false
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		node.Status.Conditions[i] = condition
+		return true, existing.Status != corev1.ConditionTrue && condition.Status == corev1.ConditionTrue
+	}
+
+	if condition.Status != corev1.ConditionTrue {
+		return false, false
+	}
+	node.Status.Conditions = append(node.Status.Conditions, condition)
+	return true, true
+}
@@ -0,0 +1,40 @@
+package termination
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	spotInterruptionNotice        = "spot_interruption"
+	rebalanceRecommendationNotice = "rebalance_recommendation"
+	scheduledMaintenanceNotice    = "scheduled_maintenance"
+)
+
+var (
+	imdsPollDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mapi_aws_termination_handler_imds_poll_duration_seconds",
+			Help:    "Duration of the requests made by the termination handler to the instance metadata service.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"path"},
+	)
+
+	imdsPollFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_aws_termination_handler_imds_poll_failures_total",
+			Help: "Number of requests made by the termination handler to the instance metadata service that failed.",
+		}, []string{"path"},
+	)
+
+	noticesReceived = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mapi_aws_termination_handler_notices_total",
+			Help: "Number of notices received by the termination handler, by type of notice.",
+		}, []string{"type"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(imdsPollDuration, imdsPollFailures, noticesReceived)
+}
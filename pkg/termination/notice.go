@@ -0,0 +1,202 @@
+package termination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NoticeType is the kind of notice issued for an instance.
+type NoticeType string
+
+const (
+	// SpotInterruption notices announce that the instance is about to be terminated, stopped or hibernated.
+	SpotInterruption NoticeType = "SpotInterruption"
+	// RebalanceRecommendation notices announce that the instance is at an elevated risk of interruption.
+	RebalanceRecommendation NoticeType = "RebalanceRecommendation"
+	// ScheduledMaintenance notices announce an event scheduled for the instance, such as a retirement or reboot.
+	ScheduledMaintenance NoticeType = "ScheduledMaintenance"
+)
+
+// Notice is a notice issued for the instance by the cloud provider, or by a simulator.
+type Notice struct {
+	Type NoticeType `json:"type"`
+	// Action is the action of spot interruptions, e.g. terminate, or the code of scheduled events,
+	// e.g. instance-retirement. It is unused for rebalance recommendations.
+	Action string `json:"action,omitempty"`
+	// Time is when the spot interruption takes effect, the notice time of rebalance recommendations,
+	// or the time scheduled events start at the earliest.
+	Time string `json:"time,omitempty"`
+	// EventID identifies scheduled events.
+	EventID string `json:"eventID,omitempty"`
+}
+
+// isTermination returns true unless the notice is a spot interruption stopping or hibernating the instance.
+// Stopped and hibernated instances are started again by EC2 once capacity is available, so their Node is kept.
+func (n *Notice) isTermination() bool {
+	return n.Action != "stop" && n.Action != "hibernate"
+}
+
+// NoticeSource provides the notices currently in effect for the instance.
+type NoticeSource interface {
+	// Name identifies the source in logs and errors.
+	Name() string
+	// Notices returns the notices currently in effect, or none if there are no notices.
+	Notices(ctx context.Context) ([]Notice, error)
+}
+
+// findNotice returns the first notice of the given type, or nil if there is none.
+func findNotice(notices []Notice, noticeType NoticeType) *Notice {
+	for i := range notices {
+		if notices[i].Type == noticeType {
+			return &notices[i]
+		}
+	}
+	return nil
+}
+
+// filterNotices returns the notices of the given type.
+func filterNotices(notices []Notice, noticeType NoticeType) []Notice {
+	filtered := []Notice{}
+	for _, notice := range notices {
+		if notice.Type == noticeType {
+			filtered = append(filtered, notice)
+		}
+	}
+	return filtered
+}
+
+// parseNoticeTime parses the time of a notice, either in RFC 3339 format as used by spot interruptions
+// and rebalance recommendations, or in the format of scheduled events.
+func parseNoticeTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(maintenanceEventTimeLayout, value)
+}
+
+// newIMDSNoticeSources returns the instance metadata service sources enabled by the options.
+func newIMDSNoticeSources(imdsClient *imdsClient, options HandlerOptions) []NoticeSource {
+	sources := []NoticeSource{&imdsSpotInterruptionSource{client: imdsClient}}
+	if options.WatchRebalanceRecommendations {
+		sources = append(sources, &imdsRebalanceRecommendationSource{client: imdsClient})
+	}
+	if options.WatchScheduledMaintenance {
+		sources = append(sources, &imdsScheduledMaintenanceSource{client: imdsClient})
+	}
+	return sources
+}
+
+// imdsSpotInterruptionSource reads spot interruption notices from the instance metadata service.
+type imdsSpotInterruptionSource struct {
+	client *imdsClient
+}
+
+// instanceAction is the spot interruption notice returned by the metadata service.
+type instanceAction struct {
+	// Action is one of terminate, stop or hibernate.
+	Action string `json:"action"`
+	Time   string `json:"time"`
+}
+
+// Name implements NoticeSource
+func (s *imdsSpotInterruptionSource) Name() string {
+	return "imds-spot-interruption"
+}
+
+// Notices implements NoticeSource
+func (s *imdsSpotInterruptionSource) Notices(ctx context.Context) ([]Notice, error) {
+	content, found, err := s.client.getMetadata(ctx, awsInstanceActionPath)
+	if err != nil {
+		return nil, fmt.Errorf("error polling instance action endpoint: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	action := instanceAction{}
+	if err := json.Unmarshal([]byte(content), &action); err != nil {
+		return nil, fmt.Errorf("error decoding instance action %q: %v", content, err)
+	}
+	return []Notice{{Type: SpotInterruption, Action: action.Action, Time: action.Time}}, nil
+}
+
+// imdsRebalanceRecommendationSource reads rebalance recommendations from the instance metadata service.
+type imdsRebalanceRecommendationSource struct {
+	client *imdsClient
+}
+
+// rebalanceRecommendation is the rebalance recommendation notice returned by the metadata service.
+type rebalanceRecommendation struct {
+	NoticeTime string `json:"noticeTime"`
+}
+
+// Name implements NoticeSource
+func (s *imdsRebalanceRecommendationSource) Name() string {
+	return "imds-rebalance-recommendation"
+}
+
+// Notices implements NoticeSource
+func (s *imdsRebalanceRecommendationSource) Notices(ctx context.Context) ([]Notice, error) {
+	content, found, err := s.client.getMetadata(ctx, awsRebalanceRecommendationPath)
+	if err != nil {
+		return nil, fmt.Errorf("error polling rebalance recommendation endpoint: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	recommendation := rebalanceRecommendation{}
+	if err := json.Unmarshal([]byte(content), &recommendation); err != nil {
+		return nil, fmt.Errorf("error decoding rebalance recommendation %q: %v", content, err)
+	}
+	return []Notice{{Type: RebalanceRecommendation, Time: recommendation.NoticeTime}}, nil
+}
+
+// imdsScheduledMaintenanceSource reads the scheduled events of the instance from the instance metadata service.
+type imdsScheduledMaintenanceSource struct {
+	client *imdsClient
+}
+
+// maintenanceEvent is a scheduled event returned by the metadata service, such as
+// instance-retirement, system-reboot or instance-stop.
+type maintenanceEvent struct {
+	Code        string `json:"Code"`
+	Description string `json:"Description"`
+	EventID     string `json:"EventId"`
+	NotBefore   string `json:"NotBefore"`
+	NotAfter    string `json:"NotAfter"`
+	State       string `json:"State"`
+}
+
+// Name implements NoticeSource
+func (s *imdsScheduledMaintenanceSource) Name() string {
+	return "imds-scheduled-maintenance"
+}
+
+// Notices implements NoticeSource. Events which have completed or been canceled are left out.
+func (s *imdsScheduledMaintenanceSource) Notices(ctx context.Context) ([]Notice, error) {
+	content, found, err := s.client.getMetadata(ctx, awsScheduledMaintenancePath)
+	if err != nil {
+		return nil, fmt.Errorf("error polling scheduled maintenance endpoint: %w", err)
+	}
+	if !found || strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+
+	events := []maintenanceEvent{}
+	if err := json.Unmarshal([]byte(content), &events); err != nil {
+		return nil, fmt.Errorf("error decoding scheduled maintenance events %q: %v", content, err)
+	}
+
+	notices := []Notice{}
+	for _, event := range events {
+		if event.State == "completed" || event.State == "canceled" {
+			continue
+		}
+		notices = append(notices, Notice{Type: ScheduledMaintenance, Action: event.Code, Time: event.NotBefore, EventID: event.EventID})
+	}
+	return notices, nil
+}
@@ -0,0 +1,77 @@
+package termination
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IMDS notice sources", func() {
+	var imdsServer *httptest.Server
+	var responses map[string]string
+	var sources []NoticeSource
+
+	BeforeEach(func() {
+		responses = map[string]string{}
+
+		imdsServer = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			content, ok := responses[req.URL.Path]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(rw, content)
+		}))
+
+		sources = newIMDSNoticeSources(newIMDSClient(imdsServer.URL), HandlerOptions{
+			WatchRebalanceRecommendations: true,
+			WatchScheduledMaintenance:     true,
+		})
+	})
+
+	AfterEach(func() {
+		imdsServer.Close()
+	})
+
+	pollAll := func() []Notice {
+		notices := []Notice{}
+		for _, source := range sources {
+			sourceNotices, err := source.Notices(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			notices = append(notices, sourceNotices...)
+		}
+		return notices
+	}
+
+	It("should return no notices when none were issued", func() {
+		Expect(pollAll()).To(BeEmpty())
+	})
+
+	It("should return the notices issued for the instance", func() {
+		responses[imdsMetadataPathPrefix+awsInstanceActionPath] = `{"action": "stop", "time": "2017-09-18T08:22:00Z"}`
+		responses[imdsMetadataPathPrefix+awsRebalanceRecommendationPath] = `{"noticeTime": "2020-10-27T08:22:00Z"}`
+		responses[imdsMetadataPathPrefix+awsScheduledMaintenancePath] = `[` +
+			`{"NotBefore": "21 Jan 2019 09:00:43 GMT", "Code": "system-reboot", "EventId": "instance-event-0", "State": "active"}, ` +
+			`{"NotBefore": "21 Jan 2019 09:00:43 GMT", "Code": "instance-stop", "EventId": "instance-event-1", "State": "completed"}]`
+
+		Expect(pollAll()).To(ConsistOf(
+			Notice{Type: SpotInterruption, Action: "stop", Time: "2017-09-18T08:22:00Z"},
+			Notice{Type: RebalanceRecommendation, Time: "2020-10-27T08:22:00Z"},
+			Notice{Type: ScheduledMaintenance, Action: "system-reboot", Time: "21 Jan 2019 09:00:43 GMT", EventID: "instance-event-0"},
+		))
+	})
+
+	It("should only poll the instance action when the other notices are not watched", func() {
+		Expect(newIMDSNoticeSources(newIMDSClient(imdsServer.URL), HandlerOptions{})).To(HaveLen(1))
+	})
+
+	It("should return an error when a notice cannot be decoded", func() {
+		responses[imdsMetadataPathPrefix+awsInstanceActionPath] = `not json`
+
+		_, err := sources[0].Notices(ctx)
+		Expect(err).To(MatchError(ContainSubstring("error decoding instance action")))
+	})
+})
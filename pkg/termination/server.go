@@ -0,0 +1,33 @@
+package termination
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	livenessEndpoint  = "/healthz"
+	readinessEndpoint = "/readyz"
+	metricsEndpoint   = "/metrics"
+
+	serverReadHeaderTimeout = 10 * time.Second
+)
+
+// NewServer returns an HTTP server exposing the liveness and readiness probes of the handler,
+// and the Prometheus metrics of the process.
+func NewServer(addr string, h Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(livenessEndpoint, healthz.CheckHandler{Checker: healthz.Ping})
+	mux.Handle(readinessEndpoint, healthz.CheckHandler{Checker: h.Readyz})
+	mux.Handle(metricsEndpoint, promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: serverReadHeaderTimeout,
+	}
+}
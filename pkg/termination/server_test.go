@@ -0,0 +1,69 @@
+package termination
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var h *handler
+	var server *httptest.Server
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	BeforeEach(func() {
+		h = &handler{pollInterval: time.Minute}
+		server = httptest.NewServer(NewServer("", h).Handler)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should report live", func() {
+		statusCode, _ := get(livenessEndpoint)
+		Expect(statusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should report not ready before the first successful poll", func() {
+		statusCode, _ := get(readinessEndpoint)
+		Expect(statusCode).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("should report ready after a recent successful poll", func() {
+		h.lastSuccessfulPoll.Store(time.Now().UnixNano())
+		statusCode, _ := get(readinessEndpoint)
+		Expect(statusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should report not ready when the last successful poll is stale", func() {
+		h.lastSuccessfulPoll.Store(time.Now().Add(-time.Hour).UnixNano())
+		statusCode, _ := get(readinessEndpoint)
+		Expect(statusCode).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("should serve the handler metrics", func() {
+		noticesReceived.WithLabelValues(spotInterruptionNotice).Add(0)
+		imdsPollFailures.WithLabelValues(awsInstanceActionPath).Add(0)
+		imdsPollDuration.WithLabelValues(awsInstanceActionPath).Observe(0)
+
+		statusCode, body := get(metricsEndpoint)
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("mapi_aws_termination_handler_notices_total"))
+		Expect(body).To(ContainSubstring("mapi_aws_termination_handler_imds_poll_failures_total"))
+		Expect(body).To(ContainSubstring("mapi_aws_termination_handler_imds_poll_duration_seconds"))
+	})
+})
@@ -0,0 +1,62 @@
+package termination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// fileNoticeSource simulates notices from a file, which is read on every poll.
+type fileNoticeSource struct {
+	path string
+}
+
+// NewFileNoticeSource returns a NoticeSource simulating the notices listed in the file at path,
+// in YAML or JSON. The file is read on every poll, so that notices can be issued and withdrawn
+// on a running node by editing it, e.g. during chaos testing. A missing file means no notices.
+//
+// For example, the following file simulates a spot interruption:
+//
+//   - type: SpotInterruption
+//     action: terminate
+//     time: "2024-01-01T00:00:00Z"
+func NewFileNoticeSource(path string) NoticeSource {
+	return &fileNoticeSource{path: path}
+}
+
+// Name implements NoticeSource
+func (s *fileNoticeSource) Name() string {
+	return fmt.Sprintf("simulator %s", s.path)
+}
+
+// Notices implements NoticeSource
+func (s *fileNoticeSource) Notices(_ context.Context) ([]Notice, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading simulated notices: %v", err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return nil, nil
+	}
+
+	notices := []Notice{}
+	if err := yaml.UnmarshalStrict(content, &notices); err != nil {
+		return nil, fmt.Errorf("error decoding simulated notices: %v", err)
+	}
+	for _, notice := range notices {
+		switch notice.Type {
+		case SpotInterruption, RebalanceRecommendation, ScheduledMaintenance:
+		default:
+			return nil, fmt.Errorf("unknown simulated notice type %q", notice.Type)
+		}
+	}
+	return notices, nil
+}
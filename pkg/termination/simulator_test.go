@@ -0,0 +1,59 @@
+package termination
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File notice source", func() {
+	var path string
+	var source NoticeSource
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "notices.yaml")
+		source = NewFileNoticeSource(path)
+	})
+
+	It("should return no notices when the file does not exist", func() {
+		notices, err := source.Notices(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notices).To(BeEmpty())
+	})
+
+	It("should return the notices listed in the file", func() {
+		Expect(os.WriteFile(path, []byte(`
+- type: SpotInterruption
+  action: terminate
+  time: "2017-09-18T08:22:00Z"
+- type: ScheduledMaintenance
+  action: system-reboot
+  time: "21 Jan 2019 09:00:43 GMT"
+  eventID: instance-event-0
+`), 0o600)).To(Succeed())
+
+		notices, err := source.Notices(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notices).To(Equal([]Notice{
+			{Type: SpotInterruption, Action: "terminate", Time: "2017-09-18T08:22:00Z"},
+			{Type: ScheduledMaintenance, Action: "system-reboot", Time: "21 Jan 2019 09:00:43 GMT", EventID: "instance-event-0"},
+		}))
+	})
+
+	It("should withdraw the notices when the file is emptied", func() {
+		Expect(os.WriteFile(path, []byte(`[{"type": "RebalanceRecommendation", "time": "2020-10-27T08:22:00Z"}]`), 0o600)).To(Succeed())
+		Expect(source.Notices(ctx)).To(HaveLen(1))
+
+		Expect(os.WriteFile(path, nil, 0o600)).To(Succeed())
+		Expect(source.Notices(ctx)).To(BeEmpty())
+	})
+
+	It("should return an error for unknown notice types", func() {
+		Expect(os.WriteFile(path, []byte(`[{"type": "Meteorite"}]`), 0o600)).To(Succeed())
+
+		_, err := source.Notices(ctx)
+		Expect(err).To(MatchError(`unknown simulated notice type "Meteorite"`))
+	})
+})
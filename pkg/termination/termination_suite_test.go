@@ -56,7 +56,7 @@ var _ = BeforeSuite(func() {
 	Expect(gateErr).ToNot(HaveOccurred())
 
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "..", "vendor", "github.com", "openshift", "api", "machine", "v1beta1")},
+		CRDDirectoryPaths: []string{filepath.Join("..", "..", "vendor", "github.com", "openshift", "api", "machine", "v1beta1", "zz_generated.crd-manifests", "0000_10_machine-api_01_machines-CustomNoUpgrade.crd.yaml")},
 	}
 
 	// Use our own scheme so we don't interfere with any test cases